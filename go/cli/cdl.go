@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libcdl"
+	"lib-post-interchange/libcdl/cdl"
+	cdltypes "lib-post-interchange/libcdl/types"
+
+	"github.com/urfave/cli/v2"
+)
+
+var toCDLCommand = &cli.Command{
+	Name:      "to-cdl",
+	Usage:     "Extract the grades of ALE, CSV or EDL files as a .ccc, a .cdl, or .cc files per clip",
+	ArgsUsage: "<.ale, .csv or .edl file, or folder of ALEs>...",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Usage:    "Write to this .ccc or .cdl file, or folder with --per-clip or --per-reel",
+			Aliases:  []string{"o"},
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "id-column",
+			Usage: "Name color corrections by this column",
+			Value: convert.DefaultCDLOptions().IDColumn,
+		},
+		&cli.BoolFlag{
+			Name:  "per-clip",
+			Usage: "Write one .cc file per clip",
+		},
+		&cli.BoolFlag{
+			Name:  "per-reel",
+			Usage: "Write one .ccc collection per reel",
+		},
+		&cli.StringSliceFlag{
+			Name:  "reel-column",
+			Usage: "Take reel names for --per-reel from these columns, in order of preference",
+			Value: cli.NewStringSlice(convert.DefaultCDLReelColumns...),
+		},
+	}, gradeCSVFlags...),
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			return formatError("to-cdl", fmt.Errorf("missing file or folder argument"))
+		}
+		if c.Bool("per-clip") && c.Bool("per-reel") {
+			return formatError("to-cdl", fmt.Errorf("--per-clip and --per-reel cannot be combined"))
+		}
+		opts := convert.DefaultCDLOptions()
+		opts.IDColumn = c.String("id-column")
+		opts.ReelColumns = c.StringSlice("reel-column")
+		csvOpts, err := gradeCSVOptionsFromFlags(c)
+		if err != nil {
+			return formatError("to-cdl", err)
+		}
+
+		paths, err := aleFilePaths(c.Args().Slice())
+		if err != nil {
+			return formatError("to-cdl", err)
+		}
+		if len(paths) == 0 {
+			return formatError("to-cdl", fmt.Errorf("no files found"))
+		}
+		collection := &cdltypes.Object{Format: cdltypes.FormatCCC}
+		seen := make(map[string]cdltypes.ColorCorrection)
+		for _, path := range paths {
+			corrections, err := readGrades(c, path, opts, csvOpts)
+			if err != nil {
+				return formatError("read file", fmt.Errorf("%s: %w", path, err))
+			}
+			// The same clip may be logged in more than one ALE, or cut in more than once
+			for _, cc := range corrections.Corrections {
+				if earlier, ok := seen[cc.ID]; ok {
+					if earlier.Slope != cc.Slope || earlier.Offset != cc.Offset || earlier.Power != cc.Power || earlier.Saturation != cc.Saturation {
+						fmt.Fprintf(c.App.ErrWriter, "cli: Skipped %s %s: different grade earlier\n", path, cc.ID)
+					}
+					continue
+				}
+				seen[cc.ID] = cc
+				collection.Corrections = append(collection.Corrections, cc)
+			}
+		}
+
+		output := c.String("output")
+		var written []string
+		switch {
+		case c.Bool("per-clip"), c.Bool("per-reel"):
+			if err := os.MkdirAll(output, 0755); err != nil {
+				return formatError("write file", err)
+			}
+			if c.Bool("per-clip") {
+				written, err = cdl.WriteFilesPerClip(output, collection, cdl.DefaultWriteOptions())
+			} else {
+				written, err = cdl.WriteFilesPerReel(output, collection, cdl.DefaultWriteOptions())
+			}
+		default:
+			if strings.EqualFold(filepath.Ext(output), ".cdl") {
+				collection.Format = cdltypes.FormatCDL
+			}
+			err = cdl.WriteFile(output, collection)
+			written = []string{output}
+		}
+		if err != nil {
+			return formatError("write file", err)
+		}
+		for _, path := range written {
+			fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", path)
+		}
+		return nil
+	},
+}
+
+var mergeCDLCommand = &cli.Command{
+	Name:      "merge-cdl",
+	Usage:     "Write the grades of CDL files into the ASC_SOP and ASC_SAT columns of an ALE",
+	ArgsUsage: "<input.ale> <output.ale> <.ccc, .cdl, .cc, .csv or .edl file>...",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "id-column",
+			Usage: "Match this column against color correction IDs",
+			Value: convert.DefaultMergeCDLOptions().IDColumn,
+		},
+		&cli.StringFlag{
+			Name:  "pattern",
+			Usage: "Also match clips and grades that share the first submatch of this regular expression",
+		},
+		&cli.StringSliceFlag{
+			Name:  "map",
+			Usage: "Use a grade for a clip, as Clip=ID",
+		},
+	}, gradeCSVFlags...),
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 3 {
+			return formatError("merge-cdl", fmt.Errorf("expected input and output ALE and CDL file path arguments"))
+		}
+		opts := convert.DefaultMergeCDLOptions()
+		opts.IDColumn = c.String("id-column")
+		if pattern := c.String("pattern"); pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return formatError("merge-cdl", err)
+			}
+			opts.Pattern = re
+		}
+		for _, m := range c.StringSlice("map") {
+			clip, id, ok := strings.Cut(m, "=")
+			if !ok || clip == "" || id == "" {
+				return formatError("merge-cdl", fmt.Errorf("invalid map %q, expected Clip=ID", m))
+			}
+			if opts.Mapping == nil {
+				opts.Mapping = make(map[string]string)
+			}
+			opts.Mapping[clip] = id
+		}
+		csvOpts, err := gradeCSVOptionsFromFlags(c)
+		if err != nil {
+			return formatError("merge-cdl", err)
+		}
+
+		obj, err := libale.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		collection := &cdltypes.Object{Format: cdltypes.FormatCCC}
+		for _, path := range c.Args().Slice()[2:] {
+			grades, err := readGrades(c, path, convert.DefaultCDLOptions(), csvOpts)
+			if err != nil {
+				return formatError("read file", fmt.Errorf("%s: %w", path, err))
+			}
+			collection.Corrections = append(collection.Corrections, grades.Corrections...)
+		}
+
+		merged, report, err := convert.MergeCDL(obj, collection, opts)
+		if err != nil {
+			return formatError("merge-cdl", err)
+		}
+		for _, d := range report.UnmatchedClips {
+			fmt.Fprintf(c.App.ErrWriter, "cli: Unmatched clip %s\n", d)
+		}
+		for _, id := range report.UnmatchedGrades {
+			fmt.Fprintf(c.App.ErrWriter, "cli: Unmatched grade %s\n", id)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), merged); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	},
+}
+
+var toCubeCommand = &cli.Command{
+	Name:      "to-cube",
+	Usage:     "Bake the grades of ALE, CSV, EDL or CDL files into .cube 3D LUTs, one per grade",
+	ArgsUsage: "<.ale, .csv, .edl, .ccc, .cdl or .cc file>...",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Usage:    "Write .cube files to this folder",
+			Aliases:  []string{"o"},
+			Required: true,
+		},
+		&cli.IntFlag{
+			Name:  "size",
+			Usage: "Points along each axis of the LUT: 17, 33 or 65",
+			Value: cdl.DefaultCubeSize,
+		},
+		&cli.StringFlag{
+			Name:  "id-column",
+			Usage: "Name grades from ALE files by this column",
+			Value: convert.DefaultCDLOptions().IDColumn,
+		},
+	}, gradeCSVFlags...),
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			return formatError("to-cube", fmt.Errorf("missing file argument"))
+		}
+		cdlOpts := convert.DefaultCDLOptions()
+		cdlOpts.IDColumn = c.String("id-column")
+		csvOpts, err := gradeCSVOptionsFromFlags(c)
+		if err != nil {
+			return formatError("to-cube", err)
+		}
+
+		collection := &cdltypes.Object{Format: cdltypes.FormatCCC}
+		for _, path := range c.Args().Slice() {
+			grades, err := readGrades(c, path, cdlOpts, csvOpts)
+			if err != nil {
+				return formatError("read file", fmt.Errorf("%s: %w", path, err))
+			}
+			collection.Corrections = append(collection.Corrections, grades.Corrections...)
+		}
+
+		output := c.String("output")
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return formatError("write file", err)
+		}
+		opts := cdl.DefaultCubeOptions()
+		opts.Size = c.Int("size")
+		written, err := cdl.WriteCubeFiles(output, collection, cdl.IDOriginal, opts)
+		if err != nil {
+			return formatError("write file", err)
+		}
+		for _, path := range written {
+			fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", path)
+		}
+		return nil
+	},
+}
+
+// gradeCSVFlags are the flags shared by the commands that read grades from
+// CSV exports
+var gradeCSVFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:  "csv-column",
+		Usage: "Read a grade value from this CSV column before the usual ones, as KEY=Column where KEY is one of " + strings.Join(gradeCSVKeys, ", "),
+	},
+	&cli.StringFlag{
+		Name:  "csv-delimiter",
+		Usage: "CSV field delimiter",
+		Value: ",",
+	},
+}
+
+// Keys of the csv-column flag, one per field of cdl.CSVColumns
+var gradeCSVKeys = []string{"name", "sop", "sat", "start", "end", "duration", "fps", "scene", "take"}
+
+// gradeCSVOptionsFromFlags builds options for reading grades from CSV from
+// the grade CSV flags. Columns given with csv-column are tried before the
+// default columns for the same value.
+func gradeCSVOptionsFromFlags(c *cli.Context) (cdl.CSVOptions, error) {
+	opts := cdl.DefaultCSVOptions()
+	delimiter := c.String("csv-delimiter")
+	if delimiter == `\t` {
+		delimiter = "\t"
+	}
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return opts, fmt.Errorf("delimiter must be a single character: %q", delimiter)
+	}
+	opts.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+
+	columns := &opts.Columns
+	fields := map[string]*[]string{
+		"name": &columns.Name, "sop": &columns.SOP, "sat": &columns.SAT,
+		"start": &columns.Start, "end": &columns.End, "duration": &columns.Duration,
+		"fps": &columns.FPS, "scene": &columns.Scene, "take": &columns.Take,
+	}
+	added := make(map[string][]string)
+	for _, m := range c.StringSlice("csv-column") {
+		key, column, ok := strings.Cut(m, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, known := fields[key]; !ok || !known || strings.TrimSpace(column) == "" {
+			return opts, fmt.Errorf("invalid CSV column %q, expected KEY=Column with KEY one of %s", m, strings.Join(gradeCSVKeys, ", "))
+		}
+		added[key] = append(added[key], column)
+	}
+	for key, names := range added {
+		*fields[key] = append(names, *fields[key]...)
+	}
+	return opts, nil
+}
+
+// readGrades reads the color corrections of a file by its extension: the
+// ASC_SOP and ASC_SAT columns of an .ale, the grades of a .csv export read
+// with csvOpts, the ASC comments of an .edl, or a .ccc, .cdl or .cc file.
+// ALE rows without a grade are reported as skipped.
+func readGrades(c *cli.Context, path string, opts convert.CDLOptions, csvOpts cdl.CSVOptions) (*cdltypes.Object, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ale":
+		obj, err := libale.New().ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		grades, diagnostics, err := convert.ToCDL(obj, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range diagnostics {
+			fmt.Fprintf(c.App.ErrWriter, "cli: Skipped %s %s\n", path, d)
+		}
+		return grades, nil
+	case ".csv":
+		return cdl.ReadCSVFile(path, csvOpts)
+	case ".edl":
+		return cdl.ReadEDLFile(path)
+	}
+	return libcdl.New().ReadFile(path)
+}
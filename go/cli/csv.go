@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/csv"
+	"lib-post-interchange/libale/mapping"
+	"lib-post-interchange/libale/types"
+
+	"github.com/urfave/cli/v2"
+)
+
+// csvFlags are the flags shared by the CSV commands
+var csvFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "delimiter",
+		Usage: "CSV field delimiter",
+		Value: ",",
+	},
+	&cli.StringFlag{
+		Name:  "header-fields",
+		Usage: "Carry ALE header fields as: omit, comment or sidecar",
+		Value: "omit",
+	},
+	&cli.StringFlag{
+		Name:  "profile",
+		Usage: "Map columns with a mapping profile file or built-in profile (" + strings.Join(mapping.BuiltinNames(), ", ") + ")",
+	},
+}
+
+// profileFromFlag loads the mapping profile named by the profile flag: a file
+// if one exists at that path, or else a built-in profile
+func profileFromFlag(c *cli.Context) (*mapping.Profile, error) {
+	name := c.String("profile")
+	if name == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(name); err == nil {
+		return mapping.LoadFile(name)
+	}
+	return mapping.Builtin(name)
+}
+
+// csvOptionsFromFlags builds CSV options from the CSV flags
+func csvOptionsFromFlags(c *cli.Context) (csv.Options, error) {
+	opts := csv.DefaultOptions()
+	delimiter := c.String("delimiter")
+	if delimiter == `\t` {
+		delimiter = "\t"
+	}
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return opts, fmt.Errorf("delimiter must be a single character: %q", delimiter)
+	}
+	opts.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	switch c.String("header-fields") {
+	case "omit":
+		opts.HeaderFields = csv.HeaderFieldsOmit
+	case "comment":
+		opts.HeaderFields = csv.HeaderFieldsComment
+	case "sidecar":
+		opts.HeaderFields = csv.HeaderFieldsSidecar
+	default:
+		return opts, fmt.Errorf("unknown header fields mode: %q", c.String("header-fields"))
+	}
+	profile, err := profileFromFlag(c)
+	if err != nil {
+		return opts, err
+	}
+	opts.Profile = profile
+	return opts, nil
+}
+
+var toCSVCommand = &cli.Command{
+	Name:      "to-csv",
+	Usage:     "Convert ALE files to a single CSV",
+	ArgsUsage: "<file or folder>...",
+	Flags: append([]cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "map",
+			Usage: "Output only mapped columns, as ALEColumn:CSVColumn",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Usage:   "Write CSV to this file instead of stdout",
+			Aliases: []string{"o"},
+		},
+	}, csvFlags...),
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			return formatError("to-csv", fmt.Errorf("missing file or folder argument"))
+		}
+		opts, err := csvOptionsFromFlags(c)
+		if err != nil {
+			return formatError("to-csv", err)
+		}
+
+		// Column mapping, in the same syntax as the Python ale_to_csv.py example
+		for _, m := range c.StringSlice("map") {
+			aleCol, csvCol, ok := strings.Cut(m, ":")
+			if !ok || aleCol == "" || csvCol == "" {
+				return formatError("to-csv", fmt.Errorf("invalid map %q, expected ALEColumn:CSVColumn", m))
+			}
+			opts.Projection.Include = append(opts.Projection.Include, aleCol)
+			if opts.Projection.Rename == nil {
+				opts.Projection.Rename = make(map[string]string)
+			}
+			opts.Projection.Rename[aleCol] = csvCol
+		}
+
+		// Gather and read all ALE files
+		paths, err := aleFilePaths(c.Args().Slice())
+		if err != nil {
+			return formatError("to-csv", err)
+		}
+		if len(paths) == 0 {
+			return formatError("to-csv", fmt.Errorf("no ALE files found"))
+		}
+		handler := libale.New()
+		objs := make([]*types.Object, 0, len(paths))
+		for _, path := range paths {
+			obj, err := handler.ReadFile(path)
+			if err != nil {
+				return formatError("read file", fmt.Errorf("%s: %w", path, err))
+			}
+			objs = append(objs, obj)
+		}
+		combined := concatObjects(objs)
+
+		// Output
+		if output := c.String("output"); output != "" {
+			if err := csv.WriteFile(output, combined, opts); err != nil {
+				return formatError("write file", err)
+			}
+			return nil
+		}
+		data, err := csv.Write(combined, opts)
+		if err != nil {
+			return formatError("write CSV", err)
+		}
+		fmt.Fprint(c.App.Writer, data)
+		return nil
+	},
+}
+
+var fromCSVCommand = &cli.Command{
+	Name:      "from-csv",
+	Usage:     "Convert a CSV file to an ALE file",
+	ArgsUsage: "<input.csv> <output.ale>",
+	Flags:     append([]cli.Flag{}, csvFlags...),
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("from-csv", fmt.Errorf("expected input and output file path arguments"))
+		}
+		opts, err := csvOptionsFromFlags(c)
+		if err != nil {
+			return formatError("from-csv", err)
+		}
+		obj, err := csv.ReadFile(c.Args().Get(0), opts)
+		if err != nil {
+			return formatError("read file", err)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), obj); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	},
+}
+
+// aleFilePaths expands files and folders into a sorted list of .ale files, recursing into folders
+func aleFilePaths(items []string) ([]string, error) {
+	var paths []string
+	for _, item := range items {
+		info, err := os.Stat(item)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, item)
+			continue
+		}
+		err = filepath.WalkDir(item, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".ale") {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// concatObjects combines the rows of several objects under the union of their
+// columns, in the order first seen. Header fields come from the first object.
+func concatObjects(objs []*types.Object) *types.Object {
+	if len(objs) == 1 {
+		return objs[0]
+	}
+	var names []string
+	index := make(map[string]int)
+	var rows [][]string
+	for _, obj := range objs {
+		for _, name := range obj.ColumnNames() {
+			if _, ok := index[name]; !ok {
+				index[name] = len(names)
+				names = append(names, name)
+			}
+		}
+	}
+	for _, obj := range objs {
+		columnNames := obj.ColumnNames()
+		for _, row := range obj.Rows {
+			values := make([]string, len(names))
+			for i, value := range obj.Values(row) {
+				values[index[columnNames[i]]] = value
+			}
+			rows = append(rows, values)
+		}
+	}
+	return types.NewObject(objs[0].HeaderFields, names, rows)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/csv"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var cutListCommand = &cli.Command{
+	Name:      "cut-list",
+	Usage:     "List the key numbers of the film frames each event of a CMX3600 EDL plays, from the KN Start of film ALEs",
+	ArgsUsage: "<input.edl> <film ALE file or folder>...",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Usage:    "Write the cut list to this .txt or .csv file",
+			Aliases:  []string{"o"},
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: txt or csv (default: from the output file extension)",
+		},
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the EDL and clips (default: the first ALE's FPS header field)",
+		},
+		&cli.StringFlag{
+			Name:  "film-format",
+			Usage: "Film format of the key numbers, such as \"35 mm, 3 perf\" (default: the ALE's FILM_FORMAT header field, or 35 mm)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "reel-column",
+			Usage: "Match EDL reel names against these clip columns",
+			Value: cli.NewStringSlice(convert.DefaultClipReelColumns...),
+		},
+		&cli.IntFlag{
+			Name:  "reel-length",
+			Usage: "Length EDL reel names were shortened to",
+			Value: convert.DefaultCutListOptions().ReelLength,
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("cut-list", fmt.Errorf("expected EDL and film ALE arguments"))
+		}
+		output := c.String("output")
+		outputFormat := strings.ToLower(c.String("format"))
+		if outputFormat == "" {
+			outputFormat = strings.ToLower(strings.TrimPrefix(filepath.Ext(output), "."))
+		}
+		if outputFormat != "txt" && outputFormat != "csv" {
+			return formatError("cut-list", fmt.Errorf("unknown output format: %q", outputFormat))
+		}
+
+		opts := convert.DefaultCutListOptions()
+		opts.ReelColumns = c.StringSlice("reel-column")
+		opts.ReelLength = c.Int("reel-length")
+		if fps := c.String("fps"); fps != "" {
+			var err error
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("cut-list", err)
+			}
+		}
+		if film := c.String("film-format"); film != "" {
+			var err error
+			if opts.Format, err = keycode.ParseFormat(film); err != nil {
+				return formatError("cut-list", err)
+			}
+		}
+
+		list, err := libedl.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		paths, err := aleFilePaths(c.Args().Tail())
+		if err != nil {
+			return formatError("cut-list", err)
+		}
+		if len(paths) == 0 {
+			return formatError("cut-list", fmt.Errorf("no ALE files found"))
+		}
+		handler := libale.New()
+		clips := make([]*types.Object, 0, len(paths))
+		for _, path := range paths {
+			obj, err := handler.ReadFile(path)
+			if err != nil {
+				return formatError("read file", fmt.Errorf("%s: %w", path, err))
+			}
+			clips = append(clips, obj)
+		}
+
+		cutList, diagnostics, err := convert.FilmCutList(list, clips, opts)
+		if err != nil {
+			return formatError("cut-list", err)
+		}
+		for _, d := range diagnostics {
+			fmt.Fprintf(c.App.ErrWriter, "cli: Unmatched %s\n", d)
+		}
+
+		switch outputFormat {
+		case "txt":
+			err = os.WriteFile(output, []byte(cutList.String()), 0644)
+		case "csv":
+			err = csv.WriteFile(output, cutList.Object(), csv.DefaultOptions())
+		}
+		if err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", output)
+		return nil
+	},
+}
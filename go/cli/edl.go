@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libedl"
+	"lib-post-interchange/libedl/edl"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+// edlWriteFlags are the flags shared by commands that write an EDL
+var edlWriteFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:  "lf",
+		Usage: "End lines with LF instead of CRLF",
+	},
+	&cli.IntFlag{
+		Name:  "reel-length",
+		Usage: "Longest reel name written",
+		Value: edl.DefaultReelLength,
+	},
+	&cli.StringFlag{
+		Name:  "reels",
+		Usage: "Shorten long reel names by: truncate-end, truncate-start or fail",
+		Value: "truncate-end",
+	},
+}
+
+// edlWriteOptionsFromFlags builds EDL write options from the EDL write flags
+func edlWriteOptionsFromFlags(c *cli.Context) (edl.WriteOptions, error) {
+	opts := edl.DefaultWriteOptions()
+	opts.CRLF = !c.Bool("lf")
+	opts.ReelLength = c.Int("reel-length")
+	switch c.String("reels") {
+	case "truncate-end":
+		opts.Reels = edl.ReelTruncateEnd
+	case "truncate-start":
+		opts.Reels = edl.ReelTruncateStart
+	case "fail":
+		opts.Reels = edl.ReelFail
+	default:
+		return opts, fmt.Errorf("unknown reel policy: %q", c.String("reels"))
+	}
+	return opts, nil
+}
+
+var toEDLCommand = &cli.Command{
+	Name:      "to-edl",
+	Usage:     "Lay out the clips of an ALE end to end as a CMX3600 EDL",
+	ArgsUsage: "<input.ale> <output.edl>",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "title",
+			Usage: "EDL title (default: output file name)",
+		},
+		&cli.StringFlag{
+			Name:  "record-start",
+			Usage: "Record timecode of the first event",
+			Value: "01:00:00:00",
+		},
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the clips (default: the ALE's FPS header field)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "reel-column",
+			Usage: "Take reel names from these columns, in order of preference",
+			Value: cli.NewStringSlice(convert.DefaultReelColumns...),
+		},
+	}, edlWriteFlags...),
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("to-edl", fmt.Errorf("expected input and output file path arguments"))
+		}
+		output := c.Args().Get(1)
+
+		opts := convert.DefaultStringoutOptions()
+		opts.Title = c.String("title")
+		if opts.Title == "" {
+			opts.Title = strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+		}
+		start, err := timecode.Parse(c.String("record-start"))
+		if err != nil {
+			return formatError("to-edl", err)
+		}
+		opts.RecordStart = start
+		if fps := c.String("fps"); fps != "" {
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("to-edl", err)
+			}
+		}
+		opts.ReelColumns = c.StringSlice("reel-column")
+		writeOpts, err := edlWriteOptionsFromFlags(c)
+		if err != nil {
+			return formatError("to-edl", err)
+		}
+
+		obj, err := libale.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		list, err := convert.Stringout(obj, opts)
+		if err != nil {
+			return formatError("to-edl", err)
+		}
+		if err := edl.WriteFileWithOptions(output, list, writeOpts); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", output)
+		return nil
+	},
+}
+
+var fromEDLCommand = &cli.Command{
+	Name:      "from-edl",
+	Usage:     "Convert the events of a CMX3600 EDL to an ALE of the source ranges used",
+	ArgsUsage: "<input.edl> <output.ale>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the EDL",
+			Value: convert.DefaultALEOptions().Rate.String(),
+		},
+		&cli.StringFlag{
+			Name:  "video-format",
+			Usage: "VIDEO_FORMAT header field",
+			Value: convert.DefaultALEOptions().VideoFormat,
+		},
+		&cli.IntFlag{
+			Name:  "handles",
+			Usage: "Extend each clip by this many frames at both ends",
+		},
+		&cli.BoolFlag{
+			Name:  "audio",
+			Usage: "Keep events on audio-only tracks",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("from-edl", fmt.Errorf("expected input and output file path arguments"))
+		}
+		rate, err := timecode.ParseRate(c.String("fps"))
+		if err != nil {
+			return formatError("from-edl", err)
+		}
+		opts := convert.ALEOptions{
+			Rate:         rate,
+			VideoFormat:  c.String("video-format"),
+			Handles:      c.Int("handles"),
+			IncludeAudio: c.Bool("audio"),
+		}
+
+		list, err := libedl.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		obj, err := convert.ToALE(list, opts)
+		if err != nil {
+			return formatError("from-edl", err)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), obj); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	},
+}
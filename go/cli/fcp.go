@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var toFCPCommand = &cli.Command{
+	Name:      "to-fcp",
+	Usage:     "Write the clips of an ALE as a Final Cut Pro 7 XML bin or an FCPXML event",
+	ArgsUsage: "<input.ale> <output.xml or output.fcpxml>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: xmeml or fcpxml (default: fcpxml for .fcpxml files, otherwise xmeml)",
+		},
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "Bin or event name (default: output file name)",
+		},
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the clips (default: the ALE's FPS header field)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "reel-column",
+			Usage: "Take reel names from these columns, in order of preference",
+			Value: cli.NewStringSlice(convert.DefaultReelColumns...),
+		},
+		&cli.StringFlag{
+			Name:  "media-folder",
+			Usage: "Locate Source File values that are not absolute paths in this folder",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("to-fcp", fmt.Errorf("expected input and output file path arguments"))
+		}
+		output := c.Args().Get(1)
+		outputFormat := strings.ToLower(c.String("format"))
+		if outputFormat == "" {
+			outputFormat = "xmeml"
+			if strings.EqualFold(filepath.Ext(output), ".fcpxml") {
+				outputFormat = "fcpxml"
+			}
+		}
+		if outputFormat != "xmeml" && outputFormat != "fcpxml" {
+			return formatError("to-fcp", fmt.Errorf("unknown output format: %q", outputFormat))
+		}
+
+		opts := convert.DefaultFCPOptions()
+		opts.Name = c.String("name")
+		if opts.Name == "" {
+			opts.Name = strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+		}
+		if fps := c.String("fps"); fps != "" {
+			var err error
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("to-fcp", err)
+			}
+		}
+		opts.ReelColumns = c.StringSlice("reel-column")
+		opts.MediaFolder = c.String("media-folder")
+
+		obj, err := libale.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		var data []byte
+		if outputFormat == "fcpxml" {
+			data, err = convert.ToFCPXML(obj, opts)
+		} else {
+			data, err = convert.ToXMEML(obj, opts)
+		}
+		if err != nil {
+			return formatError("to-fcp", err)
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", output)
+		return nil
+	},
+}
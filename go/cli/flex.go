@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libflex"
+	"lib-post-interchange/libflex/flex"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var toFLExCommand = &cli.Command{
+	Name:      "to-flex",
+	Usage:     "Write the clips of an ALE as a FLEx telecine log",
+	ArgsUsage: "<input.ale> <output.flx>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "title",
+			Usage: "Title record text (default: output file name)",
+		},
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the clips (default: the ALE's FPS header field)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "reel-column",
+			Usage: "Take video reel names from these columns, in order of preference",
+			Value: cli.NewStringSlice(convert.DefaultReelColumns...),
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("to-flex", fmt.Errorf("expected input and output file path arguments"))
+		}
+		output := c.Args().Get(1)
+
+		opts := convert.DefaultFLExOptions()
+		opts.Title = c.String("title")
+		if opts.Title == "" {
+			opts.Title = strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+		}
+		if fps := c.String("fps"); fps != "" {
+			var err error
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("to-flex", err)
+			}
+		}
+		opts.ReelColumns = c.StringSlice("reel-column")
+
+		obj, err := libale.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		log, err := convert.ToFLEx(obj, opts)
+		if err != nil {
+			return formatError("to-flex", err)
+		}
+		if err := flex.WriteFile(output, log); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", output)
+		return nil
+	},
+}
+
+var fromFLExCommand = &cli.Command{
+	Name:      "from-flex",
+	Usage:     "Convert the events of a FLEx telecine log to an ALE",
+	ArgsUsage: "<input.flx> <output.ale>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the video timecodes (default: the FPS of the edit records)",
+		},
+		&cli.StringFlag{
+			Name:  "video-format",
+			Usage: "VIDEO_FORMAT header field",
+			Value: convert.DefaultFLExOptions().VideoFormat,
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("from-flex", fmt.Errorf("expected input and output file path arguments"))
+		}
+		opts := convert.DefaultFLExOptions()
+		opts.VideoFormat = c.String("video-format")
+		if fps := c.String("fps"); fps != "" {
+			var err error
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("from-flex", err)
+			}
+		}
+
+		log, err := libflex.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		obj, err := convert.FromFLEx(log, opts)
+		if err != nil {
+			return formatError("from-flex", err)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), obj); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	},
+}
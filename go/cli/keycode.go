@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var checkKeycodesCommand = &cli.Command{
+	Name:      "check-keycodes",
+	Usage:     "Check the KN Start and KN End columns of ALEs against their durations",
+	ArgsUsage: "<input.ale or folder>...",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "film-format",
+			Usage: "Film format of the key numbers, such as \"35 mm, 3 perf\" (default: the ALE's FILM_FORMAT header field, or 35 mm)",
+		},
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the clips (default: the ALE's FPS header field)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			return formatError("check-keycodes", fmt.Errorf("missing file path argument"))
+		}
+		opts := convert.DefaultKeycodeOptions()
+		if film := c.String("film-format"); film != "" {
+			var err error
+			if opts.Format, err = keycode.ParseFormat(film); err != nil {
+				return formatError("check-keycodes", err)
+			}
+		}
+		if fps := c.String("fps"); fps != "" {
+			var err error
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("check-keycodes", err)
+			}
+		}
+
+		paths, err := aleFilePaths(c.Args().Slice())
+		if err != nil {
+			return formatError("check-keycodes", err)
+		}
+		if len(paths) == 0 {
+			return formatError("check-keycodes", fmt.Errorf("no files found"))
+		}
+		problems := 0
+		for _, path := range paths {
+			obj, err := libale.New().ReadFile(path)
+			if err != nil {
+				return formatError("read file", err)
+			}
+			diagnostics, err := convert.CheckKeycodes(obj, opts)
+			if err != nil {
+				return formatError("check-keycodes", fmt.Errorf("%s: %w", path, err))
+			}
+			for _, d := range diagnostics {
+				fmt.Fprintf(c.App.ErrWriter, "cli: %s %s\n", path, d)
+			}
+			problems += len(diagnostics)
+		}
+		if problems > 0 {
+			return formatError("check-keycodes", fmt.Errorf("%d keycode problems in %d files", problems, len(paths)))
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Keycodes match in %d files\n", len(paths))
+		return nil
+	},
+}
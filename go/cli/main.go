@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/types"
 
 	"github.com/urfave/cli/v2"
 )
@@ -15,6 +18,41 @@ func formatError(op string, err error) error {
 	return fmt.Errorf("cli: %s: %w", op, err)
 }
 
+// projectionFlags are the flags shared by commands that output a subset of columns
+var projectionFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:  "columns",
+		Usage: "Output only these columns, in this order",
+	},
+	&cli.StringSliceFlag{
+		Name:  "exclude",
+		Usage: "Leave these columns out of the output",
+	},
+	&cli.StringSliceFlag{
+		Name:  "rename",
+		Usage: "Rename a column on output, as Source=Output",
+	},
+}
+
+// projectionFromFlags builds a column projection from the projection flags
+func projectionFromFlags(c *cli.Context) (types.Projection, error) {
+	projection := types.Projection{
+		Include: c.StringSlice("columns"),
+		Exclude: c.StringSlice("exclude"),
+	}
+	for _, rename := range c.StringSlice("rename") {
+		source, output, ok := strings.Cut(rename, "=")
+		if !ok || source == "" || output == "" {
+			return projection, fmt.Errorf("invalid rename %q, expected Source=Output", rename)
+		}
+		if projection.Rename == nil {
+			projection.Rename = make(map[string]string)
+		}
+		projection.Rename[source] = output
+	}
+	return projection, nil
+}
+
 func main() {
 	app := &cli.App{
 		Name:  "libale-go-cli",
@@ -23,13 +61,13 @@ func main() {
 			{
 				Name:  "read",
 				Usage: "Read an ALE file",
-				Flags: []cli.Flag{
+				Flags: append([]cli.Flag{
 					&cli.BoolFlag{
 						Name:    "json",
 						Usage:   "Output in JSON format",
 						Aliases: []string{"j"},
 					},
-				},
+				}, projectionFlags...),
 				Action: func(c *cli.Context) error {
 					// Validate input
 					if c.NArg() < 1 {
@@ -57,6 +95,18 @@ func main() {
 						return formatError("read file", err)
 					}
 
+					// Apply any column projection
+					projection, err := projectionFromFlags(c)
+					if err != nil {
+						return formatError("read", err)
+					}
+					if !projection.IsZero() {
+						aleObj, err = aleObj.Project(projection)
+						if err != nil {
+							return formatError("project columns", err)
+						}
+					}
+
 					// Output based on format
 					if c.Bool("json") {
 						// Marshal with indentation for readability
@@ -76,13 +126,78 @@ func main() {
 				},
 			},
 			{
-				Name:  "write",
-				Usage: "Write metadata to an ALE file",
+				Name:      "write",
+				Usage:     "Write an ALE file to a new ALE file",
+				ArgsUsage: "<input.ale> <output.ale>",
+				Flags: append([]cli.Flag{
+					&cli.BoolFlag{
+						Name:  "crlf",
+						Usage: "Use CRLF line endings",
+					},
+					&cli.BoolFlag{
+						Name:  "backup",
+						Usage: "Keep a timestamped .bak of an existing output file",
+					},
+					&cli.BoolFlag{
+						Name:  "no-overwrite",
+						Usage: "Refuse to replace an existing output file",
+					},
+				}, projectionFlags...),
 				Action: func(c *cli.Context) error {
-					fmt.Fprintf(c.App.Writer, "cli: Write command executed\n")
+					// Validate input
+					if c.NArg() < 2 {
+						return formatError("write", fmt.Errorf("expected input and output file path arguments"))
+					}
+					inputFile := c.Args().Get(0)
+					outputFile := c.Args().Get(1)
+
+					// Read the ALE file
+					aleObj, err := libale.New().ReadFile(inputFile)
+					if err != nil {
+						return formatError("read file", err)
+					}
+
+					// Build write options
+					opts := ale.DefaultWriteOptions()
+					if c.Bool("crlf") {
+						opts.LineEnding = ale.CRLF
+					}
+					opts.Backup = c.Bool("backup")
+					opts.NoOverwrite = c.Bool("no-overwrite")
+					opts.Projection, err = projectionFromFlags(c)
+					if err != nil {
+						return formatError("write", err)
+					}
+
+					// Write the ALE file
+					if err := ale.WriteFileWithOptions(outputFile, aleObj, opts); err != nil {
+						return formatError("write file", err)
+					}
+					fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", outputFile)
 					return nil
 				},
 			},
+			toCSVCommand,
+			fromCSVCommand,
+			toXLSXCommand,
+			fromXLSXCommand,
+			toEDLCommand,
+			fromEDLCommand,
+			pullListCommand,
+			toCDLCommand,
+			mergeCDLCommand,
+			toCubeCommand,
+			toOTIOCommand,
+			fromOTIOCommand,
+			toFCPCommand,
+			toFLExCommand,
+			fromFLExCommand,
+			checkKeycodesCommand,
+			cutListCommand,
+			toFilmRateCommand,
+			toVideoRateCommand,
+			checkPulldownCommand,
+			retimeCommand,
 		},
 	}
 
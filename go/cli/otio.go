@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var toOTIOCommand = &cli.Command{
+	Name:      "to-otio",
+	Usage:     "Lay out the clips of an ALE end to end as an OpenTimelineIO timeline",
+	ArgsUsage: "<input.ale> <output.otio>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "Timeline name (default: output file name)",
+		},
+		&cli.StringFlag{
+			Name:  "record-start",
+			Usage: "Start timecode of the timeline",
+			Value: "01:00:00:00",
+		},
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the clips (default: the ALE's FPS header field)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("to-otio", fmt.Errorf("expected input and output file path arguments"))
+		}
+		output := c.Args().Get(1)
+
+		opts := convert.DefaultOTIOOptions()
+		opts.Name = c.String("name")
+		if opts.Name == "" {
+			opts.Name = strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+		}
+		start, err := timecode.Parse(c.String("record-start"))
+		if err != nil {
+			return formatError("to-otio", err)
+		}
+		opts.RecordStart = start
+		if fps := c.String("fps"); fps != "" {
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("to-otio", err)
+			}
+		}
+
+		obj, err := libale.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		data, err := convert.ToOTIO(obj, opts)
+		if err != nil {
+			return formatError("to-otio", err)
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", output)
+		return nil
+	},
+}
+
+var fromOTIOCommand = &cli.Command{
+	Name:      "from-otio",
+	Usage:     "Convert the clips of an OpenTimelineIO timeline to an ALE",
+	ArgsUsage: "<input.otio> <output.ale>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "video-format",
+			Usage: "VIDEO_FORMAT header field, unless the timeline was written from an ALE",
+			Value: convert.DefaultFromOTIOOptions().VideoFormat,
+		},
+		&cli.BoolFlag{
+			Name:  "drop-frame",
+			Usage: "Write 29.97 and 59.94 timecodes in drop frame",
+		},
+		&cli.BoolFlag{
+			Name:  "audio",
+			Usage: "Keep clips on audio tracks",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("from-otio", fmt.Errorf("expected input and output file path arguments"))
+		}
+		opts := convert.FromOTIOOptions{
+			VideoFormat:  c.String("video-format"),
+			DropFrame:    c.Bool("drop-frame"),
+			IncludeAudio: c.Bool("audio"),
+		}
+
+		data, err := os.ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		obj, err := convert.FromOTIO(data, opts)
+		if err != nil {
+			return formatError("from-otio", err)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), obj); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	},
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+// pulldownFlags are the flags shared by the pulldown conversion commands.
+var pulldownFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "fps",
+		Usage: "Frame rate of the input timecodes (default: the ALE's FPS header field)",
+	},
+	&cli.StringSliceFlag{
+		Name:  "column",
+		Usage: "Convert the timecodes of these columns",
+		Value: cli.NewStringSlice(convert.DefaultPulldownOptions().Columns...),
+	},
+}
+
+// pulldownOptions returns pulldown options from the flags of a command.
+func pulldownOptions(c *cli.Context) (convert.PulldownOptions, error) {
+	opts := convert.DefaultPulldownOptions()
+	opts.Columns = c.StringSlice("column")
+	opts.DropFrame = c.Bool("drop-frame")
+	if fps := c.String("fps"); fps != "" {
+		var err error
+		if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// pulldownAction returns the action of a command converting an ALE with a
+// pulldown conversion.
+func pulldownAction(name string, conv func(*types.Object, convert.PulldownOptions) (*types.Object, error)) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError(name, fmt.Errorf("expected input and output file path arguments"))
+		}
+		opts, err := pulldownOptions(c)
+		if err != nil {
+			return formatError(name, err)
+		}
+		obj, err := libale.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		if obj, err = conv(obj, opts); err != nil {
+			return formatError(name, err)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), obj); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	}
+}
+
+var toFilmRateCommand = &cli.Command{
+	Name:      "to-film-rate",
+	Usage:     "Convert the timecodes of a 29.97 or 30 fps ALE to the 23.976 or 24 fps film it holds with 2:3 pulldown",
+	ArgsUsage: "<input.ale> <output.ale>",
+	Flags:     pulldownFlags,
+	Action:    pulldownAction("to-film-rate", convert.VideoToFilm),
+}
+
+var toVideoRateCommand = &cli.Command{
+	Name:      "to-video-rate",
+	Usage:     "Convert the timecodes of a 23.976 or 24 fps ALE to a 29.97 or 30 fps 2:3 pulldown transfer",
+	ArgsUsage: "<input.ale> <output.ale>",
+	Flags: append([]cli.Flag{
+		&cli.BoolFlag{
+			Name:  "drop-frame",
+			Usage: "Write the video timecodes in drop frame",
+		},
+	}, pulldownFlags...),
+	Action: pulldownAction("to-video-rate", convert.FilmToVideo),
+}
+
+var checkPulldownCommand = &cli.Command{
+	Name:      "check-pulldown",
+	Usage:     "Check the Pulldown column of 29.97 or 30 fps ALEs for valid phases and a steady cadence on each tape",
+	ArgsUsage: "<input.ale or folder>...",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the clips (default: the ALE's FPS header field)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			return formatError("check-pulldown", fmt.Errorf("missing file path argument"))
+		}
+		opts := convert.DefaultPulldownOptions()
+		if fps := c.String("fps"); fps != "" {
+			var err error
+			if opts.Rate, err = timecode.ParseRate(fps); err != nil {
+				return formatError("check-pulldown", err)
+			}
+		}
+
+		paths, err := aleFilePaths(c.Args().Slice())
+		if err != nil {
+			return formatError("check-pulldown", err)
+		}
+		if len(paths) == 0 {
+			return formatError("check-pulldown", fmt.Errorf("no files found"))
+		}
+		problems := 0
+		for _, path := range paths {
+			obj, err := libale.New().ReadFile(path)
+			if err != nil {
+				return formatError("read file", err)
+			}
+			diagnostics, err := convert.CheckPulldown(obj, opts)
+			if err != nil {
+				return formatError("check-pulldown", fmt.Errorf("%s: %w", path, err))
+			}
+			for _, d := range diagnostics {
+				fmt.Fprintf(c.App.ErrWriter, "cli: %s %s\n", path, d)
+			}
+			problems += len(diagnostics)
+		}
+		if problems > 0 {
+			return formatError("check-pulldown", fmt.Errorf("%d pulldown problems in %d files", problems, len(paths)))
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Pulldown cadence holds in %d files\n", len(paths))
+		return nil
+	},
+}
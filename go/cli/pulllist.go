@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lib-post-interchange/convert"
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/csv"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var pullListCommand = &cli.Command{
+	Name:      "pull-list",
+	Usage:     "Match the events of a CMX3600 EDL to the clips of camera ALEs and list the source ranges to pull",
+	ArgsUsage: "<input.edl> <camera ALE file or folder>...",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Usage:    "Write the pull list to this .ale, .csv or .json file",
+			Aliases:  []string{"o"},
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: ale, csv or json (default: from the output file extension)",
+		},
+		&cli.IntFlag{
+			Name:  "handles",
+			Usage: "Extend each pull by this many frames at both ends, within the clip",
+		},
+		&cli.StringFlag{
+			Name:  "fps",
+			Usage: "Frame rate of the EDL and clips (default: the first ALE's FPS header field)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "reel-column",
+			Usage: "Match EDL reel names against these clip columns",
+			Value: cli.NewStringSlice(convert.DefaultClipReelColumns...),
+		},
+		&cli.IntFlag{
+			Name:  "reel-length",
+			Usage: "Length EDL reel names were shortened to",
+			Value: convert.DefaultPullListOptions().ReelLength,
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("pull-list", fmt.Errorf("expected EDL and camera ALE arguments"))
+		}
+		output := c.String("output")
+		outputFormat := strings.ToLower(c.String("format"))
+		if outputFormat == "" {
+			outputFormat = strings.ToLower(strings.TrimPrefix(filepath.Ext(output), "."))
+		}
+		if outputFormat != "ale" && outputFormat != "csv" && outputFormat != "json" {
+			return formatError("pull-list", fmt.Errorf("unknown output format: %q", outputFormat))
+		}
+
+		opts := convert.DefaultPullListOptions()
+		opts.Handles = c.Int("handles")
+		opts.ReelColumns = c.StringSlice("reel-column")
+		opts.ReelLength = c.Int("reel-length")
+		if fps := c.String("fps"); fps != "" {
+			rate, err := timecode.ParseRate(fps)
+			if err != nil {
+				return formatError("pull-list", err)
+			}
+			opts.Rate = rate
+		}
+
+		list, err := libedl.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		paths, err := aleFilePaths(c.Args().Tail())
+		if err != nil {
+			return formatError("pull-list", err)
+		}
+		if len(paths) == 0 {
+			return formatError("pull-list", fmt.Errorf("no ALE files found"))
+		}
+		handler := libale.New()
+		clips := make([]*types.Object, 0, len(paths))
+		for _, path := range paths {
+			obj, err := handler.ReadFile(path)
+			if err != nil {
+				return formatError("read file", fmt.Errorf("%s: %w", path, err))
+			}
+			clips = append(clips, obj)
+		}
+
+		obj, diagnostics, err := convert.PullList(list, clips, opts)
+		if err != nil {
+			return formatError("pull-list", err)
+		}
+		for _, d := range diagnostics {
+			fmt.Fprintf(c.App.ErrWriter, "cli: Unmatched %s\n", d)
+		}
+
+		switch outputFormat {
+		case "ale":
+			err = ale.WriteFile(output, obj)
+		case "csv":
+			err = csv.WriteFile(output, obj, csv.DefaultOptions())
+		case "json":
+			var data []byte
+			if data, err = json.MarshalIndent(obj, "", "    "); err == nil {
+				err = os.WriteFile(output, data, 0644)
+			}
+		}
+		if err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", output)
+		return nil
+	},
+}
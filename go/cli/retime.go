@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var retimeCommand = &cli.Command{
+	Name:      "retime",
+	Usage:     "Change the frame rate of an ALE's timecode columns and FPS header field",
+	ArgsUsage: "<input.ale> <output.ale>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "fps",
+			Usage:    "New frame rate, such as 24 or \"29.97 DF\"",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "from-fps",
+			Usage: "Frame rate of the input timecodes (default: the ALE's FPS header field)",
+		},
+		&cli.StringFlag{
+			Name:  "mode",
+			Usage: "frames to relabel each frame at the new rate, or realtime to keep wall-clock times",
+			Value: "frames",
+		},
+		&cli.StringFlag{
+			Name:  "rounding",
+			Usage: "Rounding of realtime timecodes between frames: nearest, down or up",
+			Value: "nearest",
+		},
+		&cli.StringSliceFlag{
+			Name:  "column",
+			Usage: "Retime these columns (default: every column of timecodes)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("retime", fmt.Errorf("expected input and output file path arguments"))
+		}
+		retiming := types.Retiming{Columns: c.StringSlice("column")}
+		var err error
+		if retiming.Rate, err = timecode.ParseRate(c.String("fps")); err != nil {
+			return formatError("retime", err)
+		}
+		if fps := c.String("from-fps"); fps != "" {
+			if retiming.From, err = timecode.ParseRate(fps); err != nil {
+				return formatError("retime", err)
+			}
+		}
+		switch mode := strings.ToLower(c.String("mode")); mode {
+		case "frames":
+			retiming.Mode = types.RetimePreserveFrames
+		case "realtime":
+			retiming.Mode = types.RetimePreserveRealtime
+		default:
+			return formatError("retime", fmt.Errorf("unknown mode: %q", mode))
+		}
+		switch rounding := strings.ToLower(c.String("rounding")); rounding {
+		case "nearest":
+			retiming.Rounding = types.RoundNearest
+		case "down":
+			retiming.Rounding = types.RoundDown
+		case "up":
+			retiming.Rounding = types.RoundUp
+		default:
+			return formatError("retime", fmt.Errorf("unknown rounding: %q", rounding))
+		}
+
+		obj, err := libale.New().ReadFile(c.Args().Get(0))
+		if err != nil {
+			return formatError("read file", err)
+		}
+		obj, inexact, err := obj.Retime(retiming)
+		if err != nil {
+			return formatError("retime", err)
+		}
+		for _, d := range inexact {
+			fmt.Fprintf(c.App.ErrWriter, "cli: Inexact %s\n", d)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), obj); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	},
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"lib-post-interchange/libale"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libale/xlsx"
+
+	"github.com/urfave/cli/v2"
+)
+
+var toXLSXCommand = &cli.Command{
+	Name:      "to-xlsx",
+	Usage:     "Convert ALE files to a single XLSX workbook",
+	ArgsUsage: "<file or folder>...",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Usage:    "Write the workbook to this file",
+			Aliases:  []string{"o"},
+			Required: true,
+		},
+	}, projectionFlags...),
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			return formatError("to-xlsx", fmt.Errorf("missing file or folder argument"))
+		}
+		paths, err := aleFilePaths(c.Args().Slice())
+		if err != nil {
+			return formatError("to-xlsx", err)
+		}
+		if len(paths) == 0 {
+			return formatError("to-xlsx", fmt.Errorf("no ALE files found"))
+		}
+		handler := libale.New()
+		objs := make([]*types.Object, 0, len(paths))
+		for _, path := range paths {
+			obj, err := handler.ReadFile(path)
+			if err != nil {
+				return formatError("read file", fmt.Errorf("%s: %w", path, err))
+			}
+			objs = append(objs, obj)
+		}
+		combined := concatObjects(objs)
+
+		projection, err := projectionFromFlags(c)
+		if err != nil {
+			return formatError("to-xlsx", err)
+		}
+		if !projection.IsZero() {
+			if combined, err = combined.Project(projection); err != nil {
+				return formatError("to-xlsx", err)
+			}
+		}
+
+		if err := xlsx.WriteFile(c.String("output"), combined, xlsx.DefaultOptions()); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.String("output"))
+		return nil
+	},
+}
+
+var fromXLSXCommand = &cli.Command{
+	Name:      "from-xlsx",
+	Usage:     "Convert an XLSX workbook to an ALE file",
+	ArgsUsage: "<input.xlsx> <output.ale>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sheet",
+			Usage: "Name of the sheet holding clips",
+			Value: xlsx.DefaultOptions().SheetName,
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return formatError("from-xlsx", fmt.Errorf("expected input and output file path arguments"))
+		}
+		opts := xlsx.DefaultOptions()
+		opts.SheetName = c.String("sheet")
+		obj, err := xlsx.ReadFile(c.Args().Get(0), opts)
+		if err != nil {
+			return formatError("read file", err)
+		}
+		if err := ale.WriteFile(c.Args().Get(1), obj); err != nil {
+			return formatError("write file", err)
+		}
+		fmt.Fprintf(c.App.Writer, "cli: Output file: %s\n", c.Args().Get(1))
+		return nil
+	},
+}
@@ -0,0 +1,120 @@
+package convert
+
+import (
+	"fmt"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libcdl/cdl"
+	cdltypes "lib-post-interchange/libcdl/types"
+)
+
+// DefaultCDLReelColumns are the columns camera reels are taken from for color corrections.
+var DefaultCDLReelColumns = []string{"Reel_name", "Camroll", "Tape"}
+
+// CDLOptions controls how the grades of an ALE become color corrections.
+type CDLOptions struct {
+	// IDColumn is the column color corrections are named by.
+	IDColumn string
+	// ReelColumns are the columns the Reel of each correction is taken from,
+	// in order of preference, for writing one collection per reel. The ALE's
+	// TAPE header field is used if none has a value.
+	ReelColumns []string
+}
+
+// DefaultCDLOptions returns options naming corrections by the Name column,
+// with reels from DefaultCDLReelColumns.
+func DefaultCDLOptions() CDLOptions {
+	return CDLOptions{
+		IDColumn:    "Name",
+		ReelColumns: DefaultCDLReelColumns,
+	}
+}
+
+// RowDiagnostic describes an ALE row that was left out of a conversion.
+type RowDiagnostic struct {
+	// Row is the index of the row, from 0.
+	Row     int
+	Name    string
+	Message string
+}
+
+func (d RowDiagnostic) String() string {
+	return fmt.Sprintf("row %d (%s): %s", d.Row, d.Name, d.Message)
+}
+
+// ToCDL reads the ASC_SOP and ASC_SAT columns of an ALE as a .ccc color
+// correction collection, one correction per row. Rows without an ID, with a
+// missing or malformed grade value, or repeating an ID with a different grade
+// are left out and returned as diagnostics. A row repeating an ID with the
+// same grade is left out silently.
+func ToCDL(obj *types.Object, opts CDLOptions) (*cdltypes.Object, []RowDiagnostic, error) {
+	if obj == nil {
+		return nil, nil, errors.ErrOutputNilObject
+	}
+	idColumn := opts.IDColumn
+	if idColumn == "" {
+		idColumn = "Name"
+	}
+	reelColumns := opts.ReelColumns
+	if len(reelColumns) == 0 {
+		reelColumns = DefaultCDLReelColumns
+	}
+
+	collection := &cdltypes.Object{Format: cdltypes.FormatCCC}
+	var diagnostics []RowDiagnostic
+	index := make(map[string]int)
+	for i, row := range obj.Rows {
+		name, _ := value(row, "Name")
+		cc, message := rowCorrection(obj, row, idColumn, reelColumns)
+		if j, seen := index[cc.ID]; message == "" && seen {
+			if sameGrade(collection.Corrections[j], cc) {
+				continue
+			}
+			message = fmt.Sprintf("%s %q has a different grade in an earlier row", idColumn, cc.ID)
+		}
+		if message != "" {
+			diagnostics = append(diagnostics, RowDiagnostic{Row: i, Name: name, Message: message})
+			continue
+		}
+		index[cc.ID] = len(collection.Corrections)
+		collection.Corrections = append(collection.Corrections, cc)
+	}
+	return collection, diagnostics, nil
+}
+
+// rowCorrection reads the color correction of a row, or describes why it has none.
+func rowCorrection(obj *types.Object, row types.Row, idColumn string, reelColumns []string) (cdltypes.ColorCorrection, string) {
+	id, _ := value(row, idColumn)
+	if id == "" {
+		return cdltypes.ColorCorrection{}, fmt.Sprintf("no %s", idColumn)
+	}
+	sop, _ := value(row, "ASC_SOP")
+	sat, _ := value(row, "ASC_SAT")
+	switch {
+	case sop == "" && sat == "":
+		return cdltypes.ColorCorrection{}, "no ASC_SOP or ASC_SAT"
+	case sop == "":
+		return cdltypes.ColorCorrection{}, "no ASC_SOP"
+	case sat == "":
+		return cdltypes.ColorCorrection{}, "no ASC_SAT"
+	}
+
+	cc := cdltypes.NewColorCorrection(id)
+	var err error
+	if cc.Slope, cc.Offset, cc.Power, err = cdl.ParseSOP(sop); err != nil {
+		return cc, err.Error()
+	}
+	if cc.Saturation, err = cdl.ParseSAT(sat); err != nil {
+		return cc, err.Error()
+	}
+	if reel, reelErr := reelName(obj, row, reelColumns); reelErr == nil {
+		cc.Reel = reel
+	}
+	return cc, ""
+}
+
+// sameGrade reports whether two corrections have the same values.
+func sameGrade(a, b cdltypes.ColorCorrection) bool {
+	return a.Slope == b.Slope && a.Offset == b.Offset && a.Power == b.Power && a.Saturation == b.Saturation
+}
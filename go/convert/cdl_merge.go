@@ -0,0 +1,144 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libcdl/cdl"
+	cdltypes "lib-post-interchange/libcdl/types"
+)
+
+// MergeCDLOptions controls how clips are matched to color corrections.
+type MergeCDLOptions struct {
+	// IDColumn is the column matched against correction IDs.
+	IDColumn string
+	// Pattern, if set, matches clips and corrections whose IDs share the
+	// same match of this expression: its first submatch, or the whole match
+	// without one. For example `^[A-Z]\d{3}C\d{3}` matches the clip
+	// A001C001_240426_R1AA to the correction A001C001.
+	Pattern *regexp.Regexp
+	// Mapping gives the correction ID for ID column values, before any other matching.
+	Mapping map[string]string
+}
+
+// DefaultMergeCDLOptions returns options matching the Name column to correction IDs.
+func DefaultMergeCDLOptions() MergeCDLOptions {
+	return MergeCDLOptions{IDColumn: "Name"}
+}
+
+// MergeCDLReport lists what MergeCDL could not match.
+type MergeCDLReport struct {
+	// UnmatchedClips are the rows no correction was found for.
+	UnmatchedClips []RowDiagnostic
+	// UnmatchedGrades are the IDs of the corrections no row was matched to.
+	UnmatchedGrades []string
+}
+
+// MergeCDL returns a copy of an ALE with the grades of a collection written
+// to its ASC_SOP and ASC_SAT columns, adding the columns if needed. Each row
+// is matched to a correction by Mapping, then by ID, exactly and then
+// ignoring case, and then by Pattern. Rows without a match keep their values.
+func MergeCDL(obj *types.Object, collection *cdltypes.Object, opts MergeCDLOptions) (*types.Object, MergeCDLReport, error) {
+	var report MergeCDLReport
+	if obj == nil || collection == nil {
+		return nil, report, errors.ErrOutputNilObject
+	}
+	idColumn := opts.IDColumn
+	if idColumn == "" {
+		idColumn = "Name"
+	}
+
+	byID := make(map[string]int)
+	byFoldedID := make(map[string]int)
+	byKey := make(map[string]int)
+	for i := len(collection.Corrections) - 1; i >= 0; i-- {
+		id := collection.Corrections[i].ID
+		byID[id] = i
+		byFoldedID[strings.ToLower(id)] = i
+		if key := patternKey(opts.Pattern, id); key != "" {
+			byKey[key] = i
+		}
+	}
+	match := func(id string) (int, bool) {
+		if mapped, ok := opts.Mapping[id]; ok {
+			i, ok := byID[mapped]
+			return i, ok
+		}
+		if i, ok := byID[id]; ok {
+			return i, true
+		}
+		if i, ok := byFoldedID[strings.ToLower(id)]; ok {
+			return i, true
+		}
+		i, ok := byKey[patternKey(opts.Pattern, id)]
+		return i, ok
+	}
+
+	columns := obj.ColumnNames()
+	sopIndex, satIndex := columnIndex(columns, "ASC_SOP"), columnIndex(columns, "ASC_SAT")
+	if sopIndex < 0 {
+		sopIndex = len(columns)
+		columns = append(columns, "ASC_SOP")
+	}
+	if satIndex < 0 {
+		satIndex = len(columns)
+		columns = append(columns, "ASC_SAT")
+	}
+
+	used := make([]bool, len(collection.Corrections))
+	rows := make([][]string, len(obj.Rows))
+	for i, row := range obj.Rows {
+		rows[i] = make([]string, len(columns))
+		copy(rows[i], obj.Values(row))
+
+		id, _ := value(row, idColumn)
+		j, ok := match(id)
+		if id == "" || !ok {
+			name, _ := value(row, "Name")
+			message := fmt.Sprintf("no grade for %s %q", idColumn, id)
+			if id == "" {
+				message = fmt.Sprintf("no %s", idColumn)
+			}
+			report.UnmatchedClips = append(report.UnmatchedClips, RowDiagnostic{Row: i, Name: name, Message: message})
+			continue
+		}
+		cc := collection.Corrections[j]
+		used[j] = true
+		rows[i][sopIndex] = cdl.FormatSOP(cc.Slope, cc.Offset, cc.Power)
+		rows[i][satIndex] = cdl.FormatValue(cc.Saturation)
+	}
+	for j, cc := range collection.Corrections {
+		if !used[j] {
+			report.UnmatchedGrades = append(report.UnmatchedGrades, cc.ID)
+		}
+	}
+	return types.NewObject(obj.HeaderFields, columns, rows), report, nil
+}
+
+// patternKey returns the part of an ID that pattern matches, or "".
+func patternKey(pattern *regexp.Regexp, id string) string {
+	if pattern == nil {
+		return ""
+	}
+	match := pattern.FindStringSubmatch(id)
+	switch {
+	case match == nil:
+		return ""
+	case len(match) > 1:
+		return match[1]
+	}
+	return match[0]
+}
+
+// columnIndex returns the index of a column name, ignoring case, or -1.
+func columnIndex(columns []string, name string) int {
+	for i, column := range columns {
+		if strings.EqualFold(column, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,99 @@
+package convert
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libcdl/cdl"
+)
+
+func TestMergeCDLSample(t *testing.T) {
+	obj, err := ale.ReadFile("../../samples/ALE/A001R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample ALE: %v", err)
+	}
+	collection, err := cdl.ReadFile("../../samples/CDL/A001R1AA.ccc")
+	if err != nil {
+		t.Fatalf("Failed to read sample CDL: %v", err)
+	}
+	merged, report, err := MergeCDL(obj, collection, DefaultMergeCDLOptions())
+	if err != nil {
+		t.Fatalf("MergeCDL() error = %v", err)
+	}
+	if len(report.UnmatchedClips) != 0 || len(report.UnmatchedGrades) != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if !reflect.DeepEqual(merged.ColumnNames(), obj.ColumnNames()) {
+		t.Errorf("Columns changed: %q", merged.ColumnNames())
+	}
+	sop, _ := merged.Rows[1].Value("ASC_SOP")
+	sat, _ := merged.Rows[1].Value("ASC_SAT")
+	if sop != "(1.100000 1.000000 0.950000)(0.010000 0.000000 -0.010000)(1.000000 1.000000 1.000000)" || sat != "0.900000" {
+		t.Errorf("Second clip ASC_SOP = %q, ASC_SAT = %q", sop, sat)
+	}
+	if v, _ := merged.Rows[1].Value("Camera_model"); v != "ALEXA Mini" {
+		t.Errorf("Camera_model = %q, want other columns kept", v)
+	}
+	if merged.FPS.GetValue() != "25" {
+		t.Errorf("FPS = %q, want header fields kept", merged.FPS.GetValue())
+	}
+}
+
+func TestMergeCDL(t *testing.T) {
+	obj, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t25\n\nColumn\n" +
+		"Name\tClip\n\nData\n" +
+		"A001C001_240426_R1AA\tC001\n" +
+		"a001c002_240426_r1aa\tC002\n" +
+		"A001C003_240426_R1AA\tC003\n" +
+		"A001C004_240426_R1AA\tC004\n" +
+		"A001C005_240426_R1AA\tC005\n")
+	if err != nil {
+		t.Fatalf("Failed to read ALE: %v", err)
+	}
+	collection, err := cdl.Read(`<ColorCorrectionCollection>
+<ColorCorrection id="A001C001_240426_R1AA"><SATNode><Saturation>0.1</Saturation></SATNode></ColorCorrection>
+<ColorCorrection id="A001C002_240426_R1AA"><SATNode><Saturation>0.2</Saturation></SATNode></ColorCorrection>
+<ColorCorrection id="A001C003"><SATNode><Saturation>0.3</Saturation></SATNode></ColorCorrection>
+<ColorCorrection id="shot_4"><SATNode><Saturation>0.4</Saturation></SATNode></ColorCorrection>
+<ColorCorrection id="B001C001"><SATNode><Saturation>0.5</Saturation></SATNode></ColorCorrection>
+</ColorCorrectionCollection>`)
+	if err != nil {
+		t.Fatalf("Failed to read CDL: %v", err)
+	}
+
+	opts := DefaultMergeCDLOptions()
+	opts.Pattern = regexp.MustCompile(`^([A-Z]\d{3}C\d{3})`)
+	opts.Mapping = map[string]string{"A001C004_240426_R1AA": "shot_4"}
+	merged, report, err := MergeCDL(obj, collection, opts)
+	if err != nil {
+		t.Fatalf("MergeCDL() error = %v", err)
+	}
+
+	// By ID, by ID ignoring case, by pattern, by mapping, and unmatched
+	wantSAT := []string{"0.100000", "0.200000", "0.300000", "0.400000", ""}
+	for i, row := range merged.Rows {
+		if got, _ := row.Value("ASC_SAT"); got != wantSAT[i] {
+			t.Errorf("Row %d ASC_SAT = %q, want %q", i, got, wantSAT[i])
+		}
+	}
+	if got, _ := merged.Rows[0].Value("ASC_SOP"); got != "(1.000000 1.000000 1.000000)(0.000000 0.000000 0.000000)(1.000000 1.000000 1.000000)" {
+		t.Errorf("Row 0 ASC_SOP = %q", got)
+	}
+	if got := merged.ColumnNames(); !reflect.DeepEqual(got, []string{"Name", "Clip", "ASC_SOP", "ASC_SAT"}) {
+		t.Errorf("Columns = %q", got)
+	}
+
+	var clips []string
+	for _, d := range report.UnmatchedClips {
+		clips = append(clips, d.String())
+	}
+	if got := strings.Join(clips, "\n"); got != `row 4 (A001C005_240426_R1AA): no grade for Name "A001C005_240426_R1AA"` {
+		t.Errorf("UnmatchedClips = %s", got)
+	}
+	if !reflect.DeepEqual(report.UnmatchedGrades, []string{"B001C001"}) {
+		t.Errorf("UnmatchedGrades = %q, want [B001C001]", report.UnmatchedGrades)
+	}
+}
@@ -0,0 +1,89 @@
+package convert
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libcdl/cdl"
+	cdltypes "lib-post-interchange/libcdl/types"
+)
+
+func TestToCDLSample(t *testing.T) {
+	obj, err := ale.ReadFile("../../samples/ALE/A901R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	collection, diagnostics, err := ToCDL(obj, DefaultCDLOptions())
+	if err != nil {
+		t.Fatalf("ToCDL() error = %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("Unexpected diagnostics: %v", diagnostics)
+	}
+	if len(collection.Corrections) != len(obj.Rows) {
+		t.Fatalf("Got %d corrections, want %d", len(collection.Corrections), len(obj.Rows))
+	}
+	first := collection.Corrections[0]
+	if first.ID != "A901C001_240426_R1AA" || !first.IsIdentity() {
+		t.Errorf("First correction = %+v", first)
+	}
+
+	// The collection is written as a valid .ccc
+	data, err := cdl.Write(collection)
+	if err != nil {
+		t.Fatalf("cdl.Write() error = %v", err)
+	}
+	if _, err := cdl.Read(data); err != nil {
+		t.Errorf("Output does not parse: %v", err)
+	}
+}
+
+func TestToCDL(t *testing.T) {
+	obj, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t25\n\nColumn\n" +
+		"Name\tTape\tClip\tASC_SOP\tASC_SAT\n\nData\n" +
+		"A001C001\tA001R1AA\tC001\t(1.1 1 0.9)(0 0 0.01)(1 1 1)\t0.8\n" +
+		"A001C002\tA001R1AA\tC002\t\t\n" +
+		"A001C003\tA001R1AA\tC003\t(1 1 1)(0 0 0)\t1\n" +
+		"A001C004\tA001R1AA\tC004\t(1 1 1)(0 0 0)(1 1 1)\t\n" +
+		"A001C001\tA001R1AA\tC001\t(1.1 1 0.9)(0 0 0.01)(1 1 1)\t0.8\n" +
+		"A001C001\tA001R1AA\tC001\t(1 1 1)(0 0 0)(1 1 1)\t1\n" +
+		"\tA001R1AA\t\t(1 1 1)(0 0 0)(1 1 1)\t1\n")
+	if err != nil {
+		t.Fatalf("Failed to read ALE: %v", err)
+	}
+
+	opts := DefaultCDLOptions()
+	opts.IDColumn = "Clip"
+	collection, diagnostics, err := ToCDL(obj, opts)
+	if err != nil {
+		t.Fatalf("ToCDL() error = %v", err)
+	}
+	want := []cdltypes.ColorCorrection{{
+		ID:         "C001",
+		Reel:       "A001R1AA",
+		Slope:      [3]float64{1.1, 1, 0.9},
+		Offset:     [3]float64{0, 0, 0.01},
+		Power:      [3]float64{1, 1, 1},
+		Saturation: 0.8,
+	}}
+	if !reflect.DeepEqual(collection.Corrections, want) {
+		t.Errorf("Corrections = %+v, want %+v", collection.Corrections, want)
+	}
+
+	wantDiagnostics := []string{
+		"row 1 (A001C002): no ASC_SOP or ASC_SAT",
+		"row 2 (A001C003): cdl: [1.0] malformed ASC CDL value: ASC_SOP \"(1 1 1)(0 0 0)\": expected (slope)(offset)(power)",
+		"row 3 (A001C004): no ASC_SAT",
+		"row 5 (A001C001): Clip \"C001\" has a different grade in an earlier row",
+		"row 6 (): no Clip",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(wantDiagnostics, "\n") {
+		t.Errorf("Diagnostics =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantDiagnostics, "\n"))
+	}
+}
@@ -0,0 +1,85 @@
+// Package convert converts between ALE objects and the other interchange
+// formats: EDLs, CDLs, FLEx telecine logs, OpenTimelineIO timelines and Final
+// Cut Pro XML.
+package convert
+
+import (
+	"fmt"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+)
+
+// frameRate returns rate if it is set, or else the rate in the object's FPS
+// header field.
+func frameRate(obj *types.Object, rate timecode.Rate) (timecode.Rate, error) {
+	if !rate.IsZero() {
+		return rate, nil
+	}
+	fps := obj.FPS.GetValue()
+	if fps == "" {
+		return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext("no FPS header field or frame rate option")
+	}
+	rate, err := timecode.ParseRate(fps)
+	if err != nil {
+		return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext(err.Error())
+	}
+	return rate, nil
+}
+
+// clipRate returns the frame rate of an ALE's clips as frameRate does. A rate
+// from the FPS header field counts in drop frame if the first Start timecode
+// is written with a drop frame separator, as drop frame is marked by the
+// timecodes rather than the header.
+func clipRate(obj *types.Object, rate timecode.Rate) (timecode.Rate, error) {
+	if !rate.IsZero() {
+		return rate, nil
+	}
+	rate, err := frameRate(obj, rate)
+	if err != nil {
+		return timecode.Rate{}, err
+	}
+	if rate.CanDropFrame() && len(obj.Rows) > 0 {
+		if start, _ := value(obj.Rows[0], "Start"); strings.ContainsAny(start, ";,.") {
+			rate.DropFrame = true
+		}
+	}
+	return rate, nil
+}
+
+// value returns the first non-empty value of the named columns in a row,
+// matching names case-insensitively, and the column it came from.
+func value(row types.Row, names ...string) (string, string) {
+	for _, name := range names {
+		for _, col := range row.Columns {
+			if !strings.EqualFold(col.Name, name) {
+				continue
+			}
+			if v, ok := row.ValueMap[col]; ok && v != nil && strings.TrimSpace(v.String()) != "" {
+				return strings.TrimSpace(v.String()), col.Name
+			}
+		}
+	}
+	return "", ""
+}
+
+// parseTimecode parses a timecode value from a row and validates it at the rate.
+func parseTimecode(s string, rate timecode.Rate, row int, column string) (timecode.Timecode, error) {
+	tc, err := timecode.Parse(s)
+	if err == nil {
+		err = tc.Validate(rate)
+	}
+	if err != nil {
+		return timecode.Timecode{}, errors.ErrInputInvalidTimecode.WithContext(fmt.Sprintf("row %d, column %q: %v", row, column, err))
+	}
+	return tc, nil
+}
+
+// formatDuration formats a number of frames as a Duration value. A duration
+// is a length rather than a time of day, so it is counted without drop frame.
+func formatDuration(frames int, rate timecode.Rate) string {
+	rate.DropFrame = false
+	return timecode.FromFrames(frames, rate).String()
+}
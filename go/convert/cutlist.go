@@ -0,0 +1,293 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/format"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl/edl"
+	edltypes "lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+// CutListOptions controls how EDL events are matched to film clips.
+type CutListOptions struct {
+	// Rate is the frame rate of the EDL and clips. The zero value means the
+	// FPS header field of the first ALE.
+	Rate timecode.Rate
+	// Format is the film format of the key numbers. The zero value means the
+	// FILM_FORMAT header field of each clip's ALE, or 35 mm 4-perf without one.
+	Format keycode.Format
+	// ReelColumns are the clip columns matched against EDL reel names.
+	ReelColumns []string
+	// ReelLength is the length EDL reel names were shortened to. The zero
+	// value means edl.DefaultReelLength.
+	ReelLength int
+}
+
+// DefaultCutListOptions returns options matching reels against
+// DefaultClipReelColumns, with the film format from each ALE.
+func DefaultCutListOptions() CutListOptions {
+	return CutListOptions{
+		ReelColumns: DefaultClipReelColumns,
+		ReelLength:  edl.DefaultReelLength,
+	}
+}
+
+// CutEvent is one event of a film cut list: the film frames of a clip that
+// an EDL event plays.
+type CutEvent struct {
+	Event     int
+	Line      int
+	Clip      string
+	Camroll   string
+	SourceIn  timecode.Timecode
+	SourceOut timecode.Timecode
+	RecordIn  timecode.Timecode
+	// KeyIn is the keycode of the first film frame and KeyOut of the last.
+	KeyIn  keycode.Keycode
+	KeyOut keycode.Keycode
+	Frames int
+	Length keycode.FeetFrames
+	// Footage is the running length of the cut list before the event.
+	Footage keycode.FeetFrames
+	Format  keycode.Format
+	// Optical describes the transition or speed change that makes the event
+	// an optical, such as "dissolve 025". It is empty for a straight cut.
+	Optical string
+}
+
+// CutList is a film cut list: an EDL's events in key numbers.
+type CutList struct {
+	Title  string
+	Rate   timecode.Rate
+	Events []CutEvent
+}
+
+// Columns of the rows returned by CutList.Object
+var cutListColumns = []string{
+	"Event", "Clip", "Camroll", "Key In", "Key Out", "Frames", "Length", "Footage",
+	"Source In", "Source Out", "Record In", "Optical",
+}
+
+// FilmCutList matches the events of an EDL to the clips of ALEs as
+// PullList does, and converts each event's source range to the key numbers
+// of the film frames it plays, from the KN Start keycode at the clip's Start
+// timecode. At 29.97 and 30 fps the video is taken to be a 3:2 pulldown
+// transfer whose first frame has the phase in the clip's pulldown column,
+// one of PulldownColumns, or A if it has none, and video frames map to film
+// frames as keycode.FilmFrame does; at 23.976, 24 and 25 fps each video
+// frame is one film frame, and other rates are an error. Dissolves, wipes,
+// keys and speed changes are marked as opticals, on both sides of a
+// transition. Events that match no clip, or whose clip has no usable
+// keycode, are returned as diagnostics.
+func FilmCutList(list *edltypes.Object, clips []*types.Object, opts CutListOptions) (*CutList, []Diagnostic, error) {
+	if list == nil || len(clips) == 0 {
+		return nil, nil, errors.ErrOutputNilObject
+	}
+	rate, err := frameRate(clips[0], opts.Rate)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rate, err = edlRate(list, rate); err != nil {
+		return nil, nil, err
+	}
+	// Film runs at 24 or 25 fps, transferred one frame to one frame or with
+	// 3:2 pulldown at 30; other rates would need a cadence not handled here
+	base := rate.Base()
+	if base != 24 && base != 25 && base != 30 {
+		return nil, nil, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("no film cut list from %s fps video", rate))
+	}
+	pulldown := base == 30
+	reelColumns := opts.ReelColumns
+	if len(reelColumns) == 0 {
+		reelColumns = DefaultClipReelColumns
+	}
+	reelLength := opts.ReelLength
+	if reelLength <= 0 {
+		reelLength = edl.DefaultReelLength
+	}
+
+	candidates, err := pullClips(clips, reelColumns, rate)
+	if err != nil {
+		return nil, nil, err
+	}
+	transitions := make(map[int]edltypes.Transition)
+	for _, event := range list.Events {
+		if event.Transition.Type != edltypes.Cut {
+			transitions[event.Number] = event.Transition
+		}
+	}
+
+	cutList := &CutList{Title: list.Title, Rate: rate}
+	var diagnostics []Diagnostic
+	total := 0
+	for _, r := range eventRanges(list, rate, false) {
+		event := r.event
+		report := func(format string, args ...any) {
+			diagnostics = append(diagnostics, Diagnostic{Event: event.Number, Line: event.Line, Reel: event.Reel, Message: fmt.Sprintf(format, args...)})
+		}
+		clip, message := matchClip(r, candidates, rate, reelLength)
+		if clip == nil {
+			report("%s", message)
+			continue
+		}
+		film, err := filmFormat(clip.obj, opts.Format)
+		if err != nil {
+			return nil, nil, err
+		}
+		knStart, _ := value(clip.row, "KN Start")
+		if knStart == "" {
+			report("clip %s has no KN Start", clip.name)
+			continue
+		}
+		start, err := keycode.Parse(knStart)
+		if err == nil {
+			err = start.Validate(film)
+		}
+		if err != nil {
+			report("clip %s: %v", clip.name, err)
+			continue
+		}
+
+		filmIn, filmOut := r.start.Sub(clip.start, rate), r.end.Sub(clip.start, rate)
+		if pulldown {
+			pullin, _ := value(clip.row, PulldownColumns...)
+			if filmIn, err = keycode.FilmFrame(filmIn, pullin); err == nil {
+				filmOut, err = keycode.FilmFrame(filmOut, pullin)
+			}
+			if err != nil {
+				report("clip %s: %v", clip.name, err)
+				continue
+			}
+		}
+		frames := filmOut - filmIn
+		if frames <= 0 {
+			report("source %s-%s holds no whole film frame", r.start, r.end)
+			continue
+		}
+
+		camroll, _ := value(clip.row, "Camroll")
+		cutList.Events = append(cutList.Events, CutEvent{
+			Event:     event.Number,
+			Line:      event.Line,
+			Clip:      clip.name,
+			Camroll:   camroll,
+			SourceIn:  r.start,
+			SourceOut: r.end,
+			RecordIn:  event.RecordIn,
+			KeyIn:     start.Add(filmIn, film),
+			KeyOut:    start.Add(filmOut-1, film),
+			Frames:    frames,
+			Length:    keycode.FeetFramesFromFrames(frames, film),
+			Footage:   keycode.FeetFramesFromFrames(total, film),
+			Format:    film,
+			Optical:   opticalOf(event, transitions),
+		})
+		total += frames
+	}
+	return cutList, diagnostics, nil
+}
+
+// opticalOf describes the transition or speed change of an event, or
+// returns an empty string for a straight cut. The outgoing side of a
+// transition is a cut event with the same number as the transition.
+func opticalOf(event edltypes.Event, transitions map[int]edltypes.Transition) string {
+	var parts []string
+	transition, outgoing := event.Transition, false
+	if transition.Type == edltypes.Cut {
+		transition, outgoing = transitions[event.Number]
+	}
+	switch transition.Type {
+	case edltypes.Dissolve:
+		parts = append(parts, fmt.Sprintf("dissolve %03d", transition.Duration))
+	case edltypes.Wipe:
+		parts = append(parts, fmt.Sprintf("wipe %s %03d", transition.Code, transition.Duration))
+	case edltypes.Key:
+		parts = append(parts, "key "+transition.Code)
+	}
+	if outgoing {
+		parts[0] += " out"
+	}
+	if event.Speed != nil {
+		parts = append(parts, fmt.Sprintf("speed %.1f fps", event.Speed.FPS))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Frames returns the number of film frames in the cut list.
+func (c *CutList) Frames() int {
+	frames := 0
+	for _, event := range c.Events {
+		frames += event.Frames
+	}
+	return frames
+}
+
+// Object returns the cut list as ALE rows, one per event, for writing as a
+// CSV or ALE.
+func (c *CutList) Object() *types.Object {
+	rows := make([][]string, len(c.Events))
+	for i, event := range c.Events {
+		rows[i] = []string{
+			fmt.Sprintf("%03d", event.Event),
+			event.Clip,
+			event.Camroll,
+			event.KeyIn.String(),
+			event.KeyOut.String(),
+			strconv.Itoa(event.Frames),
+			event.Length.String(),
+			event.Footage.String(),
+			event.SourceIn.String(),
+			event.SourceOut.String(),
+			event.RecordIn.String(),
+			event.Optical,
+		}
+	}
+	fps := c.Rate
+	fps.DropFrame = false
+	headerFields := []types.Field{format.DelimiterTab}
+	if len(c.Events) > 0 {
+		headerFields = append(headerFields, types.BaseField{Key: "FILM_FORMAT", Value: c.Events[0].Format.String()})
+	}
+	headerFields = append(headerFields, types.BaseField{Key: "FPS", Value: fps.String()})
+	return types.NewObject(headerFields, cutListColumns, rows)
+}
+
+// String formats the cut list as printable text: a title, one line per
+// event and totals of the frames and opticals.
+func (c *CutList) String() string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		b.WriteString(strings.TrimRight(fmt.Sprintf(format, args...), " ") + "\n")
+	}
+	const row = "%-5s %-24s %-8s %-22s %-22s %6s %8s %8s %-11s %-11s %-11s %s"
+
+	line("FILM CUT LIST: %s", c.Title)
+	line("")
+	line(row, "EVENT", "CLIP", "CAMROLL", "KEY IN", "KEY OUT", "FRAMES", "LENGTH", "FOOTAGE",
+		"SOURCE IN", "SOURCE OUT", "RECORD IN", "OPTICAL")
+	opticals := 0
+	for _, event := range c.Events {
+		line(row, fmt.Sprintf("%03d", event.Event), event.Clip, event.Camroll, event.KeyIn, event.KeyOut,
+			strconv.Itoa(event.Frames), event.Length, event.Footage,
+			event.SourceIn, event.SourceOut, event.RecordIn, event.Optical)
+		if event.Optical != "" {
+			opticals++
+		}
+	}
+	line("")
+	frames := c.Frames()
+	if len(c.Events) > 0 {
+		film := c.Events[0].Format
+		line("TOTAL: %d events, %d frames, %s at %s", len(c.Events), frames, keycode.FeetFramesFromFrames(frames, film), film)
+	} else {
+		line("TOTAL: 0 events")
+	}
+	line("OPTICALS: %d", opticals)
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package convert
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl/edl"
+	edltypes "lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+// cutListClips is a film ALE of the two clips cut in the sample EDL
+const cutListClips = "Heading\nFIELD_DELIM\tTABS\nFPS\t25\nFILM_FORMAT\t35 mm\n\nColumn\n" +
+	"Name\tTape\tStart\tEnd\tCamroll\tKN Start\n\nData\n" +
+	"A001C001_240426_R1AA\tA001R1AA\t03:44:36:21\t03:45:00:00\tA001\tKJ 23 4512 0100+00\n" +
+	"A001C002_240426_R1AA\tA001R1AA\t03:45:40:00\t03:46:10:00\tA001\tKJ 23 4513 0200+00\n"
+
+func TestFilmCutList(t *testing.T) {
+	list, err := edl.ReadFile("../../samples/EDL/A001R1AA_CUT.edl")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	clips, err := ale.Read(cutListClips)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	cutList, diagnostics, err := FilmCutList(list, []*types.Object{clips}, DefaultCutListOptions())
+	if err != nil {
+		t.Fatalf("FilmCutList() error = %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("Diagnostics = %v", diagnostics)
+	}
+
+	want := [][]string{
+		{"001", "A001C001_240426_R1AA", "A001", "KJ 23 4512 0104+15", "KJ 23 4512 0112+11", "125", "7+13", "0+00", "03:44:40:00", "03:44:45:00", "01:00:00:00", ""},
+		{"002", "A001C001_240426_R1AA", "A001", "KJ 23 4512 0112+12", "KJ 23 4512 0114+04", "25", "1+09", "7+13", "03:44:45:00", "03:44:46:00", "01:00:05:00", "dissolve 025 out"},
+		{"002", "A001C002_240426_R1AA", "A001", "KJ 23 4513 0215+10", "KJ 23 4513 0223+06", "125", "7+13", "9+06", "03:45:50:00", "03:45:55:00", "01:00:05:00", "dissolve 025"},
+		{"003", "A001C002_240426_R1AA", "A001", "KJ 23 4513 0228+02", "KJ 23 4513 0232+01", "64", "4+00", "17+03", "03:45:58:00", "03:46:00:14", "01:00:10:00", "speed 50.0 fps"},
+	}
+	obj := cutList.Object()
+	if !reflect.DeepEqual(obj.ColumnNames(), cutListColumns) {
+		t.Errorf("Columns = %v", obj.ColumnNames())
+	}
+	if len(obj.Rows) != len(want) {
+		t.Fatalf("Got %d rows, want %d", len(obj.Rows), len(want))
+	}
+	for i := range want {
+		if got := obj.Values(obj.Rows[i]); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Row %d = %v\nwant %v", i, got, want[i])
+		}
+	}
+	if obj.FilmFormat.GetValue() != "35 mm" || obj.FPS.GetValue() != "25" {
+		t.Errorf("FILM_FORMAT = %q, FPS = %q", obj.FilmFormat.GetValue(), obj.FPS.GetValue())
+	}
+
+	text := cutList.String()
+	for _, s := range []string{
+		"FILM CUT LIST: A001R1AA_CUT\n",
+		"002   A001C002_240426_R1AA     A001     KJ 23 4513 0215+10     KJ 23 4513 0223+06        125     7+13     9+06 03:45:50:00 03:45:55:00 01:00:05:00 dissolve 025\n",
+		"TOTAL: 4 events, 339 frames, 21+03 at 35 mm\n",
+		"OPTICALS: 3\n",
+	} {
+		if !strings.Contains(text, s) {
+			t.Errorf("String() does not contain %q:\n%s", s, text)
+		}
+	}
+
+	// Rates without a one-to-one or 3:2 film transfer are rejected rather
+	// than counted as one film frame per video frame
+	for _, rate := range []timecode.Rate{timecode.Rate48, timecode.Rate50, timecode.Rate59_94, timecode.Rate60} {
+		opts := DefaultCutListOptions()
+		opts.Rate = rate
+		if _, _, err := FilmCutList(list, []*types.Object{clips}, opts); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidFrameRate.Error()) {
+			t.Errorf("FilmCutList() at %s fps error = %v, want %v", rate, err, errors.ErrInputInvalidFrameRate)
+		}
+	}
+}
+
+func TestFilmCutListPulldown(t *testing.T) {
+	list, err := edl.Read("TITLE: PULLDOWN\nFCM: NON-DROP FRAME\n\n" +
+		"001  A001     V     C        01:00:00:02 01:00:00:12 01:00:00:00 01:00:00:10\n" +
+		"002  A002     V     C        01:00:00:00 01:00:00:10 01:00:00:10 01:00:00:20\n" +
+		"003  A003     V     C        01:00:00:00 01:00:00:10 01:00:00:20 01:00:01:00\n" +
+		"004  A001     V     C        01:00:00:01 01:00:00:02 01:00:01:00 01:00:01:01\n")
+	if err != nil {
+		t.Fatalf("edl.Read() error = %v", err)
+	}
+	clips, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t29.97\n\nColumn\n" +
+		"Name\tTape\tStart\tEnd\tKN Start\tPullin\n\nData\n" +
+		"A\tA001\t01:00:00:00\t01:00:10:00\tKJ 23 4512 0100+00\tA\n" +
+		"B\tA002\t01:00:00:00\t01:00:10:00\t\t\n")
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	cutList, diagnostics, err := FilmCutList(list, []*types.Object{clips}, DefaultCutListOptions())
+	if err != nil {
+		t.Fatalf("FilmCutList() error = %v", err)
+	}
+	if len(cutList.Events) != 1 {
+		t.Fatalf("Got %d events, want 1", len(cutList.Events))
+	}
+	// Video frames 2 to 11 from an A frame are film frames 1 to 8
+	event := cutList.Events[0]
+	if event.KeyIn.String() != "KJ 23 4512 0100+01" || event.KeyOut.String() != "KJ 23 4512 0100+08" || event.Frames != 8 {
+		t.Errorf("Event = %s to %s, %d frames", event.KeyIn, event.KeyOut, event.Frames)
+	}
+
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"event 002 (line 5, reel A002): clip B has no KN Start",
+		"event 003 (line 6, reel A003): no clip with this reel",
+		"event 004 (line 7, reel A001): source 01:00:00:01-01:00:00:02 holds no whole film frame",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diagnostics = %q\nwant %q", got, want)
+	}
+
+	// The phase is read from a Pulldown column as from a Pullin column
+	var keys []string
+	for _, column := range PulldownColumns {
+		clips, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t29.97\n\nColumn\n" +
+			"Name\tTape\tStart\tEnd\tKN Start\t" + column + "\n\nData\n" +
+			"A\tA001\t01:00:00:00\t01:00:10:00\tKJ 23 4512 0100+00\tC\n")
+		if err != nil {
+			t.Fatalf("ale.Read() error = %v", err)
+		}
+		cutList, _, err := FilmCutList(list, []*types.Object{clips}, DefaultCutListOptions())
+		if err != nil || len(cutList.Events) == 0 {
+			t.Fatalf("FilmCutList() = %v, %v", cutList, err)
+		}
+		keys = append(keys, cutList.Events[0].KeyIn.String()+" "+cutList.Events[0].KeyOut.String())
+	}
+	if keys[0] != keys[1] || keys[0] == event.KeyIn.String()+" "+event.KeyOut.String() {
+		t.Errorf("Key numbers with a C frame pull-in = %q", keys)
+	}
+
+	if _, _, err := FilmCutList(nil, []*types.Object{clips}, DefaultCutListOptions()); err != errors.ErrOutputNilObject {
+		t.Errorf("FilmCutList(nil) error = %v", err)
+	}
+}
+
+func TestOpticalOf(t *testing.T) {
+	transitions := map[int]edltypes.Transition{
+		2: {Type: edltypes.Wipe, Code: "W001", Duration: 30},
+		3: {Type: edltypes.Key, Code: "KB"},
+	}
+	tests := []struct {
+		event edltypes.Event
+		want  string
+	}{
+		{edltypes.Event{Number: 1}, ""},
+		{edltypes.Event{Number: 2}, "wipe W001 030 out"},
+		{edltypes.Event{Number: 2, Transition: transitions[2]}, "wipe W001 030"},
+		{edltypes.Event{Number: 3, Transition: transitions[3]}, "key KB"},
+		{edltypes.Event{Number: 4, Speed: &edltypes.Speed{FPS: -25}}, "speed -25.0 fps"},
+	}
+	for _, tt := range tests {
+		if got := opticalOf(tt.event, transitions); got != tt.want {
+			t.Errorf("opticalOf(%+v) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
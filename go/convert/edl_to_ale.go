@@ -0,0 +1,218 @@
+package convert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/format"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl/edl"
+	edltypes "lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+// ALEOptions controls how the events of an EDL become ALE rows.
+type ALEOptions struct {
+	// Rate is the frame rate of the EDL's timecodes. Drop frame follows the EDL's FCM.
+	Rate timecode.Rate
+	// VideoFormat is written as the VIDEO_FORMAT header field.
+	VideoFormat string
+	// Handles extends each clip by this many frames before Start and after End,
+	// stopping at the first and last frames of the day.
+	Handles int
+	// IncludeAudio keeps events on audio-only tracks.
+	IncludeAudio bool
+}
+
+// DefaultALEOptions returns 25 fps 1080 options without handles, keeping video events only.
+func DefaultALEOptions() ALEOptions {
+	return ALEOptions{
+		Rate:        timecode.Rate25,
+		VideoFormat: format.VideoHD1080.GetValue(),
+	}
+}
+
+// Columns always written by ToALE
+var aleColumns = []string{"Name", "Tape", "Start", "End", "Duration", "Tracks"}
+
+// Columns written by ToALE when any event has a value for them
+var aleCommentColumns = []string{"Source File", "Locators", "ASC_SOP", "ASC_SAT", "ASC_CC_XML", "Comments"}
+
+// ToALE converts the events of an EDL to ALE rows, one per event, with the
+// source range each event uses as described for eventRanges.
+func ToALE(list *edltypes.Object, opts ALEOptions) (*types.Object, error) {
+	if list == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	rate, err := edlRate(list, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handles stop at the ends of the day rather than wrapping past midnight
+	lastFrame := timecode.Timecode{Hours: 23, Minutes: 59, Seconds: 59, Frames: rate.Base() - 1}.ToFrames(rate)
+
+	var records []map[string]string
+	used := make(map[string]bool)
+	for _, r := range eventRanges(list, rate, opts.IncludeAudio) {
+		event := r.event
+		startFrame := max(r.start.ToFrames(rate)-opts.Handles, 0)
+		endFrame := min(r.end.ToFrames(rate)+opts.Handles, lastFrame)
+		values := map[string]string{
+			"Name":        r.name,
+			"Tape":        event.Reel,
+			"Start":       timecode.FromFrames(startFrame, rate).String(),
+			"End":         timecode.FromFrames(endFrame, rate).String(),
+			"Duration":    formatDuration(endFrame-startFrame, rate),
+			"Tracks":      aleTracks(event.Track),
+			"Source File": event.SourceFile,
+			"ASC_CC_XML":  event.ASCCCXML,
+			"Comments":    strings.Join(event.Comments, "; "),
+		}
+		if event.ASCSOP != nil {
+			values["ASC_SOP"] = edl.FormatSOP(*event.ASCSOP)
+		}
+		if event.ASCSAT != nil {
+			values["ASC_SAT"] = strconv.FormatFloat(*event.ASCSAT, 'f', 6, 64)
+		}
+		locators := make([]string, 0, len(event.Locators))
+		for _, loc := range event.Locators {
+			locators = append(locators, strings.TrimSpace(strings.Join([]string{loc.Timecode.String(), loc.Color, loc.Comment}, " ")))
+		}
+		values["Locators"] = strings.Join(locators, "; ")
+
+		for column, v := range values {
+			used[column] = used[column] || v != ""
+		}
+		records = append(records, values)
+	}
+
+	// Leave out comment columns that no event has a value for
+	columns := append([]string(nil), aleColumns...)
+	for _, column := range aleCommentColumns {
+		if used[column] {
+			columns = append(columns, column)
+		}
+	}
+	rows := make([][]string, len(records))
+	for i, values := range records {
+		rows[i] = make([]string, len(columns))
+		for j, column := range columns {
+			rows[i][j] = values[column]
+		}
+	}
+
+	fps := rate
+	fps.DropFrame = false
+	videoFormat := opts.VideoFormat
+	if videoFormat == "" {
+		videoFormat = format.VideoHD1080.GetValue()
+	}
+	headerFields := []types.Field{
+		format.DelimiterTab,
+		types.BaseField{Key: "VIDEO_FORMAT", Value: videoFormat},
+		format.AudioPCM48,
+		types.BaseField{Key: "FPS", Value: fps.String()},
+	}
+	return types.NewObject(headerFields, columns, rows), nil
+}
+
+// edlRate returns the rate of an EDL's timecodes, counting in drop frame if
+// the EDL's FCM is drop frame.
+func edlRate(list *edltypes.Object, rate timecode.Rate) (timecode.Rate, error) {
+	if rate.IsZero() {
+		return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext("no frame rate for EDL")
+	}
+	rate.DropFrame = false
+	if list.DropFrame() {
+		if !rate.CanDropFrame() {
+			return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("drop frame EDL at %s fps", rate))
+		}
+		rate.DropFrame = true
+	}
+	return rate, nil
+}
+
+// eventRange is the source range an event of an EDL plays.
+type eventRange struct {
+	event edltypes.Event
+	// name is the clip name, or else the reel
+	name  string
+	start timecode.Timecode
+	end   timecode.Timecode
+}
+
+// eventRanges returns the source range played by each event of an EDL.
+// Black and zero-length events are left out, as are audio-only events unless
+// includeAudio is set. The outgoing side of a dissolve or wipe is extended by
+// the transition's duration, and the range of a speed change covers the
+// source frames played.
+func eventRanges(list *edltypes.Object, rate timecode.Rate, includeAudio bool) []eventRange {
+	var ranges []eventRange
+	for i, event := range list.Events {
+		if event.Reel == "BL" || (!includeAudio && !event.HasVideo()) {
+			continue
+		}
+		name := event.ClipName
+		if event.ToClipName != "" {
+			name = event.ToClipName
+		}
+		start, end := sourceRangeOf(event, rate)
+		// A cut into a dissolve or wipe continues under the transition, and
+		// takes its clip name from the FROM CLIP NAME of the incoming event
+		if i+1 < len(list.Events) {
+			next := list.Events[i+1]
+			if next.Number == event.Number && next.Transition.Type != edltypes.Cut {
+				end = end.Add(next.Transition.Duration, rate)
+				if name == "" {
+					name = next.ClipName
+				}
+			}
+		}
+		if end.Sub(start, rate) <= 0 {
+			continue
+		}
+		if name == "" {
+			name = event.Reel
+		}
+		ranges = append(ranges, eventRange{event: event, name: name, start: start, end: end})
+	}
+	return ranges
+}
+
+// sourceRangeOf returns the source frames an event plays. With a speed
+// change this differs from the source out written on the event line.
+func sourceRangeOf(event edltypes.Event, rate timecode.Rate) (timecode.Timecode, timecode.Timecode) {
+	if event.Speed == nil || event.Speed.FPS == rate.FPS() {
+		return event.SourceIn, event.SourceOut
+	}
+	played := int(math.Round(float64(event.RecordOut.Sub(event.RecordIn, rate)) * math.Abs(event.Speed.FPS) / rate.FPS()))
+	if event.Speed.FPS < 0 {
+		// Reverse motion plays backwards from the source in
+		return event.SourceIn.Add(1-played, rate), event.SourceIn.Add(1, rate)
+	}
+	return event.SourceIn, event.SourceIn.Add(played, rate)
+}
+
+// aleTracks converts an EDL track type to the form of an ALE Tracks value.
+func aleTracks(track string) string {
+	switch strings.ToUpper(track) {
+	case "V":
+		return "V"
+	case "A":
+		return "A1"
+	case "AA":
+		return "A1A2"
+	case "B", "A/V":
+		return "VA1"
+	case "AA/V":
+		return "VA1A2"
+	case "NONE":
+		return ""
+	}
+	// A2, A3 and other single channels
+	return strings.ToUpper(track)
+}
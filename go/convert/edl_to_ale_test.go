@@ -0,0 +1,112 @@
+package convert
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libedl/edl"
+	"lib-post-interchange/timecode"
+)
+
+func TestToALE(t *testing.T) {
+	list, err := edl.ReadFile("../../samples/EDL/A001R1AA_CUT.edl")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	obj, err := ToALE(list, DefaultALEOptions())
+	if err != nil {
+		t.Fatalf("ToALE() error = %v", err)
+	}
+
+	wantColumns := []string{"Name", "Tape", "Start", "End", "Duration", "Tracks", "Source File", "Locators", "ASC_SOP", "ASC_SAT", "ASC_CC_XML"}
+	if !reflect.DeepEqual(obj.ColumnNames(), wantColumns) {
+		t.Errorf("Columns = %q, want %q", obj.ColumnNames(), wantColumns)
+	}
+	if obj.FPS.GetValue() != "25" || obj.VideoFormat.GetValue() != "1080" {
+		t.Errorf("FPS = %q, VIDEO_FORMAT = %q", obj.FPS.GetValue(), obj.VideoFormat.GetValue())
+	}
+
+	// The audio event is left out and the dissolve's outgoing cut is extended
+	// and named from the incoming event's FROM CLIP NAME
+	want := [][]string{
+		{"A001C001_240426_R1AA", "A001R1AA", "03:44:40:00", "03:44:45:00", "00:00:05:00", "V"},
+		{"A001C001_240426_R1AA", "A001R1AA", "03:44:45:00", "03:44:46:00", "00:00:01:00", "V"},
+		{"A001C002_240426_R1AA", "A001R1AA", "03:45:50:00", "03:45:55:00", "00:00:05:00", "V"},
+		{"A001C002_240426_R1AA", "A001R1AA", "03:45:58:00", "03:46:00:14", "00:00:02:14", "V"},
+	}
+	if len(obj.Rows) != len(want) {
+		t.Fatalf("Got %d rows, want %d", len(obj.Rows), len(want))
+	}
+	for i, row := range obj.Rows {
+		if got := obj.Values(row)[:6]; !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Row %d = %q, want %q", i, got, want[i])
+		}
+	}
+	comments := obj.Values(obj.Rows[2])[6:]
+	wantComments := []string{"", "01:00:07:12 RED check focus", "(1.100000 1.000000 0.950000)(0.010000 0.000000 -0.010000)(1.000000 1.000000 1.000000)", "0.900000", ""}
+	if !reflect.DeepEqual(comments, wantComments) {
+		t.Errorf("Comment columns = %q, want %q", comments, wantComments)
+	}
+
+	// The result is a valid ALE
+	data, err := ale.Write(obj)
+	if err != nil {
+		t.Fatalf("ale.Write() error = %v", err)
+	}
+	if _, err := ale.Read(data); err != nil {
+		t.Errorf("Output does not parse: %v", err)
+	}
+}
+
+func TestToALEOptions(t *testing.T) {
+	input := "TITLE: DF\nFCM: DROP FRAME\n\n" +
+		"001  TAPE1    V     C        01:00:59;28 01:01:00;02 01:00:00;00 01:00:00;02\n" +
+		"002  AX       A2    C        00:00:00;00 00:00:01;00 01:00:00;02 01:00:01;02\n" +
+		"003  TAPE2    AA/V  C        02:00:00;00 01:59:59;01 01:00:01;02 01:00:02;02\n" +
+		"M2   TAPE2       -029.9                02:00:00;00\n" +
+		"004  TAPE3    V     C        23:59:59;27 23:59:59;29 01:00:02;02 01:00:02;04\n"
+	list, err := edl.Read(input)
+	if err != nil {
+		t.Fatalf("Failed to read EDL: %v", err)
+	}
+
+	opts := ALEOptions{Rate: timecode.Rate29_97, Handles: 2, IncludeAudio: true}
+	obj, err := ToALE(list, opts)
+	if err != nil {
+		t.Fatalf("ToALE() error = %v", err)
+	}
+	if obj.FPS.GetValue() != "29.97" {
+		t.Errorf("FPS = %q, want 29.97", obj.FPS.GetValue())
+	}
+	values := func(i int, columns ...string) string {
+		var out []string
+		for _, column := range columns {
+			v, _ := obj.Rows[i].Value(column)
+			out = append(out, v)
+		}
+		return strings.Join(out, " ")
+	}
+	// Durations are lengths, written without drop frame
+	if got := values(0, "Start", "End", "Duration"); got != "01:00:59;26 01:01:00;04 00:00:00:06" {
+		t.Errorf("Drop frame row with handles = %q", got)
+	}
+	// Handles stop at midnight rather than wrapping past it
+	if got := values(1, "Tape", "Tracks", "Start", "End", "Duration"); got != "AX A2 00:00:00;00 00:00:01;02 00:00:01:02" {
+		t.Errorf("Audio row = %q", got)
+	}
+	if got := values(3, "Start", "End", "Duration"); got != "23:59:59;25 23:59:59;29 00:00:00:04" {
+		t.Errorf("Row before midnight with handles = %q", got)
+	}
+	if got := values(2, "Start", "End", "Tracks"); got != "01:59:58;29 02:00:00;03 VA1A2" {
+		t.Errorf("Reverse motion row with handles = %q", got)
+	}
+
+	if _, err := ToALE(list, ALEOptions{Rate: timecode.Rate25}); err == nil {
+		t.Error("Expected error for drop frame EDL at 25 fps")
+	}
+	if _, err := ToALE(list, ALEOptions{}); err == nil {
+		t.Error("Expected error without a frame rate")
+	}
+}
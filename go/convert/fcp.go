@@ -0,0 +1,443 @@
+package convert
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+)
+
+// Frame size of clips without Frame_width and Frame_height values
+const (
+	fcpDefaultWidth  = 1920
+	fcpDefaultHeight = 1080
+)
+
+// FCPXML metadata keys of the logging fields
+const (
+	fcpxmlReelKey        = "com.apple.proapps.studio.reel"
+	fcpxmlSceneKey       = "com.apple.proapps.studio.scene"
+	fcpxmlTakeKey        = "com.apple.proapps.studio.shot"
+	fcpxmlDescriptionKey = "com.apple.proapps.spotlight.kMDItemDescription"
+)
+
+// FCPOptions controls how ALE clips are written as Final Cut Pro XML.
+type FCPOptions struct {
+	// Name is the name of the bin or event holding the clips.
+	Name string
+	// Rate is the frame rate of the clips. The zero value means the ALE's FPS header field.
+	Rate timecode.Rate
+	// ReelColumns are the columns the reel name is taken from, in order of
+	// preference. A Source File value is used without its folder or extension.
+	ReelColumns []string
+	// MediaFolder is joined to Source File values that are not absolute
+	// paths to locate the media.
+	MediaFolder string
+}
+
+// DefaultFCPOptions returns options with reels from DefaultReelColumns, the
+// same reels Stringout writes to an EDL.
+func DefaultFCPOptions() FCPOptions {
+	return FCPOptions{ReelColumns: DefaultReelColumns}
+}
+
+// fcpClip is what both Final Cut Pro XML formats write of an ALE row.
+type fcpClip struct {
+	name        string
+	reel        string
+	path        string
+	start       timecode.Timecode
+	duration    int
+	width       int
+	height      int
+	scene       string
+	take        string
+	comments    string
+	description string
+}
+
+// fcpClips returns the clips of an ALE's rows and their frame rate.
+func fcpClips(obj *types.Object, opts FCPOptions) ([]fcpClip, timecode.Rate, error) {
+	if obj == nil {
+		return nil, timecode.Rate{}, errors.ErrOutputNilObject
+	}
+	rate, err := clipRate(obj, opts.Rate)
+	if err != nil {
+		return nil, timecode.Rate{}, err
+	}
+	reelColumns := opts.ReelColumns
+	if len(reelColumns) == 0 {
+		reelColumns = DefaultReelColumns
+	}
+
+	clips := make([]fcpClip, len(obj.Rows))
+	for i, row := range obj.Rows {
+		reel, reelErr := reelName(obj, row, reelColumns)
+		if reelErr != nil {
+			return nil, timecode.Rate{}, reelErr.WithContext(fmt.Sprintf("row %d", i))
+		}
+		start, end, err := sourceRange(row, rate, i)
+		if err != nil {
+			return nil, timecode.Rate{}, err
+		}
+		clip := fcpClip{
+			reel:     reel,
+			start:    start,
+			duration: end.Sub(start, rate),
+			width:    fcpDefaultWidth,
+			height:   fcpDefaultHeight,
+		}
+		clip.name, _ = value(row, "Name")
+		if clip.name == "" {
+			clip.name = reel
+		}
+		if file, _ := value(row, "Source File"); file != "" {
+			clip.path = mediaPath(file, opts.MediaFolder)
+		}
+		width, _ := value(row, "Frame_width")
+		height, _ := value(row, "Frame_height")
+		if w, err := strconv.Atoi(width); err == nil && w > 0 {
+			if h, err := strconv.Atoi(height); err == nil && h > 0 {
+				clip.width, clip.height = w, h
+			}
+		}
+		clip.scene, _ = value(row, "Scene")
+		clip.take, _ = value(row, "Take")
+		clip.comments, _ = value(row, "Comments")
+		clip.description, _ = value(row, "Description")
+		clips[i] = clip
+	}
+	return clips, rate, nil
+}
+
+// mediaPath returns a Source File value as a path with forward slashes,
+// joined to folder if it is not absolute.
+func mediaPath(file, folder string) string {
+	file = strings.ReplaceAll(file, `\`, "/")
+	if isAbsPath(file) || folder == "" {
+		return file
+	}
+	return path.Join(strings.ReplaceAll(folder, `\`, "/"), file)
+}
+
+// isAbsPath reports whether a path with forward slashes is absolute, on Unix
+// or with a Windows drive letter.
+func isAbsPath(p string) bool {
+	return path.IsAbs(p) || (len(p) > 2 && p[1] == ':' && p[2] == '/')
+}
+
+// fileURL returns a path as a file URL on host if it is absolute, and
+// otherwise as a relative URL.
+func fileURL(p, host string) string {
+	if !isAbsPath(p) {
+		return (&url.URL{Path: p}).String()
+	}
+	if !path.IsAbs(p) {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Host: host, Path: p}).String()
+}
+
+// xmemlRate is the rate element of Final Cut Pro 7 XML.
+type xmemlRate struct {
+	Timebase int    `xml:"timebase"`
+	NTSC     string `xml:"ntsc"`
+}
+
+// xmemlTimecode is the timecode element of a file.
+type xmemlTimecode struct {
+	Rate          xmemlRate `xml:"rate"`
+	String        string    `xml:"string"`
+	Frame         int       `xml:"frame"`
+	DisplayFormat string    `xml:"displayformat"`
+	Reel          string    `xml:"reel>name,omitempty"`
+}
+
+// xmemlFile is the file element of a clip item.
+type xmemlFile struct {
+	ID       string        `xml:"id,attr"`
+	Name     string        `xml:"name"`
+	PathURL  string        `xml:"pathurl,omitempty"`
+	Rate     xmemlRate     `xml:"rate"`
+	Duration int           `xml:"duration"`
+	Timecode xmemlTimecode `xml:"timecode"`
+	Width    int           `xml:"media>video>samplecharacteristics>width"`
+	Height   int           `xml:"media>video>samplecharacteristics>height"`
+}
+
+// xmemlClipItem is the clip item of a master clip's video track.
+type xmemlClipItem struct {
+	ID           string    `xml:"id,attr"`
+	MasterClipID string    `xml:"masterclipid"`
+	Name         string    `xml:"name"`
+	Rate         xmemlRate `xml:"rate"`
+	Duration     int       `xml:"duration"`
+	In           int       `xml:"in"`
+	Out          int       `xml:"out"`
+	File         xmemlFile `xml:"file"`
+}
+
+// xmemlLoggingInfo is the logginginfo element of a clip.
+type xmemlLoggingInfo struct {
+	Description string `xml:"description"`
+	Scene       string `xml:"scene"`
+	ShotTake    string `xml:"shottake"`
+	LogNote     string `xml:"lognote"`
+}
+
+// xmemlClip is a master clip in a bin.
+type xmemlClip struct {
+	ID           string           `xml:"id,attr"`
+	MasterClipID string           `xml:"masterclipid"`
+	IsMasterClip string           `xml:"ismasterclip"`
+	Name         string           `xml:"name"`
+	Duration     int              `xml:"duration"`
+	Rate         xmemlRate        `xml:"rate"`
+	ClipItem     xmemlClipItem    `xml:"media>video>track>clipitem"`
+	LoggingInfo  xmemlLoggingInfo `xml:"logginginfo"`
+}
+
+// xmemlDocument is the root of a Final Cut Pro 7 XML document holding a bin.
+type xmemlDocument struct {
+	XMLName xml.Name    `xml:"xmeml"`
+	Version string      `xml:"version,attr"`
+	Name    string      `xml:"bin>name"`
+	Clips   []xmemlClip `xml:"bin>children>clip"`
+}
+
+// ToXMEML returns the clips of an ALE as a bin of master clips in Final Cut
+// Pro 7 XML (xmeml version 5), as imported by Premiere Pro and others. Each
+// clip has the Start timecode, reel and Source File of its row, and Scene,
+// Take, Comments and Description as its logging info.
+func ToXMEML(obj *types.Object, opts FCPOptions) ([]byte, error) {
+	clips, rate, err := fcpClips(obj, opts)
+	if err != nil {
+		return nil, err
+	}
+	xmlRate := xmemlRate{Timebase: rate.Base(), NTSC: "FALSE"}
+	if rate.Den == 1001 {
+		xmlRate.NTSC = "TRUE"
+	}
+	displayFormat := "NDF"
+	if rate.DropFrame {
+		displayFormat = "DF"
+	}
+
+	doc := xmemlDocument{Version: "5", Name: opts.Name, Clips: make([]xmemlClip, len(clips))}
+	for i, clip := range clips {
+		masterClipID := fmt.Sprintf("masterclip-%d", i+1)
+		file := xmemlFile{
+			ID:       fmt.Sprintf("file-%d", i+1),
+			Name:     clip.name,
+			Rate:     xmlRate,
+			Duration: clip.duration,
+			Timecode: xmemlTimecode{
+				Rate:          xmlRate,
+				String:        clip.start.String(),
+				Frame:         clip.start.ToFrames(rate),
+				DisplayFormat: displayFormat,
+				Reel:          clip.reel,
+			},
+			Width:  clip.width,
+			Height: clip.height,
+		}
+		if clip.path != "" {
+			file.Name = path.Base(clip.path)
+			file.PathURL = fileURL(clip.path, "localhost")
+		}
+		doc.Clips[i] = xmemlClip{
+			ID:           masterClipID,
+			MasterClipID: masterClipID,
+			IsMasterClip: "TRUE",
+			Name:         clip.name,
+			Duration:     clip.duration,
+			Rate:         xmlRate,
+			ClipItem: xmemlClipItem{
+				ID:           fmt.Sprintf("clipitem-%d", i+1),
+				MasterClipID: masterClipID,
+				Name:         clip.name,
+				Rate:         xmlRate,
+				Duration:     clip.duration,
+				Out:          clip.duration,
+				File:         file,
+			},
+			LoggingInfo: xmemlLoggingInfo{
+				Description: clip.description,
+				Scene:       clip.scene,
+				ShotTake:    clip.take,
+				LogNote:     clip.comments,
+			},
+		}
+	}
+	return marshalXML(doc, "xmeml")
+}
+
+// fcpxmlFormat is a format resource.
+type fcpxmlFormat struct {
+	ID            string `xml:"id,attr"`
+	FrameDuration string `xml:"frameDuration,attr"`
+	Width         int    `xml:"width,attr"`
+	Height        int    `xml:"height,attr"`
+}
+
+// fcpxmlMetadata is an md element, one metadata key and value.
+type fcpxmlMetadata struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// fcpxmlAsset is an asset resource, the media of a clip.
+type fcpxmlAsset struct {
+	ID       string `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	HasVideo string `xml:"hasVideo,attr"`
+	Format   string `xml:"format,attr"`
+	MediaRep *struct {
+		Kind string `xml:"kind,attr"`
+		Src  string `xml:"src,attr"`
+	} `xml:"media-rep,omitempty"`
+}
+
+// fcpxmlAssetClip is an asset clip in an event.
+type fcpxmlAssetClip struct {
+	Ref      string           `xml:"ref,attr"`
+	Name     string           `xml:"name,attr"`
+	Start    string           `xml:"start,attr"`
+	Duration string           `xml:"duration,attr"`
+	Format   string           `xml:"format,attr"`
+	TCFormat string           `xml:"tcFormat,attr"`
+	Note     string           `xml:"note,omitempty"`
+	Metadata []fcpxmlMetadata `xml:"metadata>md"`
+}
+
+// fcpxmlEvent is an event of asset clips.
+type fcpxmlEvent struct {
+	Name  string            `xml:"name,attr"`
+	Clips []fcpxmlAssetClip `xml:"asset-clip"`
+}
+
+// fcpxmlDocument is the root of an FCPXML document holding an event.
+type fcpxmlDocument struct {
+	XMLName xml.Name       `xml:"fcpxml"`
+	Version string         `xml:"version,attr"`
+	Formats []fcpxmlFormat `xml:"resources>format"`
+	Assets  []fcpxmlAsset  `xml:"resources>asset"`
+	Event   fcpxmlEvent    `xml:"library>event"`
+}
+
+// ToFCPXML returns the clips of an ALE as FCPXML 1.10: an asset per clip,
+// locating the Source File, and an event of asset clips with the Start
+// timecode and reel, Scene and Take as metadata keys, Description as the
+// description key and Comments as the clip's notes.
+func ToFCPXML(obj *types.Object, opts FCPOptions) ([]byte, error) {
+	clips, rate, err := fcpClips(obj, opts)
+	if err != nil {
+		return nil, err
+	}
+	tcFormat := "NDF"
+	if rate.DropFrame {
+		tcFormat = "DF"
+	}
+
+	doc := fcpxmlDocument{Version: "1.10"}
+	formats := make(map[[2]int]string)
+	for _, clip := range clips {
+		size := [2]int{clip.width, clip.height}
+		if _, ok := formats[size]; !ok {
+			formats[size] = fmt.Sprintf("r%d", len(formats)+1)
+			doc.Formats = append(doc.Formats, fcpxmlFormat{
+				ID:            formats[size],
+				FrameDuration: fcpxmlTime(1, rate),
+				Width:         clip.width,
+				Height:        clip.height,
+			})
+		}
+	}
+	doc.Event.Name = opts.Name
+	for i, clip := range clips {
+		id := fmt.Sprintf("r%d", len(formats)+i+1)
+		format := formats[[2]int{clip.width, clip.height}]
+		start := fcpxmlTime(clip.start.ToFrames(rate), rate)
+		duration := fcpxmlTime(clip.duration, rate)
+		asset := fcpxmlAsset{
+			ID:       id,
+			Name:     clip.name,
+			Start:    start,
+			Duration: duration,
+			HasVideo: "1",
+			Format:   format,
+		}
+		if clip.path != "" {
+			asset.MediaRep = &struct {
+				Kind string `xml:"kind,attr"`
+				Src  string `xml:"src,attr"`
+			}{Kind: "original-media", Src: fileURL(clip.path, "")}
+		}
+		doc.Assets = append(doc.Assets, asset)
+
+		assetClip := fcpxmlAssetClip{
+			Ref:      id,
+			Name:     clip.name,
+			Start:    start,
+			Duration: duration,
+			Format:   format,
+			TCFormat: tcFormat,
+			Note:     clip.comments,
+		}
+		for _, md := range []fcpxmlMetadata{
+			{Key: fcpxmlReelKey, Value: clip.reel},
+			{Key: fcpxmlSceneKey, Value: clip.scene},
+			{Key: fcpxmlTakeKey, Value: clip.take},
+			{Key: fcpxmlDescriptionKey, Value: clip.description},
+		} {
+			if md.Value != "" {
+				assetClip.Metadata = append(assetClip.Metadata, md)
+			}
+		}
+		doc.Event.Clips = append(doc.Event.Clips, assetClip)
+	}
+	return marshalXML(doc, "fcpxml")
+}
+
+// fcpxmlTime returns a number of frames as an FCPXML rational time in
+// seconds, such as "1001/24000s", or "0s".
+func fcpxmlTime(frames int, rate timecode.Rate) string {
+	if frames == 0 {
+		return "0s"
+	}
+	num, den := frames*rate.Den, rate.Num
+	if d := gcd(num, den); d > 1 {
+		num, den = num/d, den/d
+	}
+	if den == 1 {
+		return fmt.Sprintf("%ds", num)
+	}
+	return fmt.Sprintf("%d/%ds", num, den)
+}
+
+// gcd returns the greatest common divisor of two positive integers.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// marshalXML returns a document with an XML declaration and doctype, indented with tabs.
+func marshalXML(doc any, doctype string) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	out := []byte(xml.Header + "<!DOCTYPE " + doctype + ">\n")
+	out = append(out, data...)
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,146 @@
+package convert
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/timecode"
+)
+
+// fcpLogged is an ALE with logging columns at 29.97 drop frame
+const fcpLogged = "Heading\nFIELD_DELIM\tTABS\nFPS\t29.97\n\nColumn\n" +
+	"Name\tTape\tSource File\tStart\tEnd\tScene\tTake\tComments\n\nData\n" +
+	"SC12A_T3\tB004\tC:\\Media\\B004C003.mov\t01:00:00;00\t01:00:01;00\tSC12A\t3\tboom in shot\n"
+
+func TestToXMEML(t *testing.T) {
+	obj, err := ale.ReadFile("../../samples/ALE/A001R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	opts := DefaultFCPOptions()
+	opts.Name = "A001R1AA"
+	opts.MediaFolder = "/Volumes/RAID/A001R1AA"
+	data, err := ToXMEML(obj, opts)
+	if err != nil {
+		t.Fatalf("ToXMEML() error = %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header+"<!DOCTYPE xmeml>\n<xmeml version=\"5\">") {
+		t.Errorf("Unexpected prolog:\n%.100s", data)
+	}
+	if strings.Contains(string(data), "<good>") {
+		t.Error("Empty good flag written")
+	}
+	var doc xmemlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	if doc.Name != "A001R1AA" || len(doc.Clips) != len(obj.Rows) {
+		t.Fatalf("Bin %q has %d clips, want %d", doc.Name, len(doc.Clips), len(obj.Rows))
+	}
+	file := doc.Clips[0].ClipItem.File
+	if file.PathURL != "file://localhost/Volumes/RAID/A001R1AA/A001C001_240426_R1AA.mxf" {
+		t.Errorf("pathurl = %q", file.PathURL)
+	}
+	if file.Timecode.String != "03:44:36:21" || file.Timecode.Frame != 336921 || file.Timecode.Rate != (xmemlRate{Timebase: 25, NTSC: "FALSE"}) {
+		t.Errorf("timecode = %+v", file.Timecode)
+	}
+	if file.Width != 3424 || file.Height != 2202 || doc.Clips[0].Duration != 731 {
+		t.Errorf("Frame size %dx%d, duration %d", file.Width, file.Height, doc.Clips[0].Duration)
+	}
+
+	obj, err = ale.Read(fcpLogged)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	if data, err = ToXMEML(obj, DefaultFCPOptions()); err != nil {
+		t.Fatalf("ToXMEML() error = %v", err)
+	}
+	doc = xmemlDocument{}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	clip := doc.Clips[0]
+	if clip.LoggingInfo.Scene != "SC12A" || clip.LoggingInfo.ShotTake != "3" || clip.LoggingInfo.LogNote != "boom in shot" {
+		t.Errorf("logginginfo = %+v", clip.LoggingInfo)
+	}
+	file = clip.ClipItem.File
+	if file.Timecode.Reel != "B004" || file.Timecode.DisplayFormat != "DF" || file.Rate != (xmemlRate{Timebase: 30, NTSC: "TRUE"}) {
+		t.Errorf("timecode = %+v, rate = %+v", file.Timecode, file.Rate)
+	}
+	if file.Name != "B004C003.mov" || file.PathURL != "file://localhost/C:/Media/B004C003.mov" {
+		t.Errorf("file = %q, %q", file.Name, file.PathURL)
+	}
+}
+
+func TestToFCPXML(t *testing.T) {
+	obj, err := ale.Read(fcpLogged)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	opts := DefaultFCPOptions()
+	opts.Name = "Day 4"
+	data, err := ToFCPXML(obj, opts)
+	if err != nil {
+		t.Fatalf("ToFCPXML() error = %v", err)
+	}
+	var doc fcpxmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	// media-rep is an FCPXML 1.10 element
+	if doc.Version != "1.10" {
+		t.Errorf("version = %q, want 1.10", doc.Version)
+	}
+	if len(doc.Formats) != 1 || doc.Formats[0].FrameDuration != "1001/30000s" || doc.Formats[0].Width != 1920 {
+		t.Errorf("formats = %+v", doc.Formats)
+	}
+	asset := doc.Assets[0]
+	// 01:00:00;00 is frame 107892 in drop frame, 107892 * 1001/30000 seconds
+	if asset.Start != "8999991/2500s" || asset.Duration != "1001/1000s" || asset.MediaRep == nil || asset.MediaRep.Src != "file:///C:/Media/B004C003.mov" {
+		t.Errorf("asset = %+v, %+v", asset, asset.MediaRep)
+	}
+	if doc.Event.Name != "Day 4" || len(doc.Event.Clips) != 1 {
+		t.Fatalf("event = %+v", doc.Event)
+	}
+	clip := doc.Event.Clips[0]
+	if clip.Ref != asset.ID || clip.TCFormat != "DF" || clip.Note != "boom in shot" {
+		t.Errorf("asset-clip = %+v", clip)
+	}
+	metadata := make(map[string]string)
+	for _, md := range clip.Metadata {
+		metadata[md.Key] = md.Value
+	}
+	if metadata[fcpxmlReelKey] != "B004" || metadata[fcpxmlSceneKey] != "SC12A" || metadata[fcpxmlTakeKey] != "3" {
+		t.Errorf("metadata = %v", metadata)
+	}
+	if _, ok := metadata[fcpxmlDescriptionKey]; ok {
+		t.Error("Empty Description written as metadata")
+	}
+
+	if _, err := ToFCPXML(nil, opts); err != errors.ErrOutputNilObject {
+		t.Errorf("ToFCPXML(nil) error = %v", err)
+	}
+}
+
+func TestFCPXMLTime(t *testing.T) {
+	tests := []struct {
+		frames int
+		rate   timecode.Rate
+		want   string
+	}{
+		{0, timecode.Rate25, "0s"},
+		{1, timecode.Rate25, "1/25s"},
+		{50, timecode.Rate25, "2s"},
+		{1, timecode.Rate23_976, "1001/24000s"},
+		{24, timecode.Rate23_976, "1001/1000s"},
+		{3, timecode.Rate59_94, "1001/20000s"},
+	}
+	for _, tt := range tests {
+		if got := fcpxmlTime(tt.frames, tt.rate); got != tt.want {
+			t.Errorf("fcpxmlTime(%d, %s) = %q, want %q", tt.frames, tt.rate, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/format"
+	"lib-post-interchange/libale/types"
+	flextypes "lib-post-interchange/libflex/types"
+	"lib-post-interchange/timecode"
+)
+
+// FLExOptions controls conversion between ALE rows and FLEx events.
+type FLExOptions struct {
+	// Title is written on the FLEx title record.
+	Title string
+	// Rate is the frame rate of the video timecodes. The zero value means the
+	// ALE's FPS header field, or the FPS of the FLEx edit records.
+	Rate timecode.Rate
+	// ReelColumns are the ALE columns the video reel is taken from, in order
+	// of preference.
+	ReelColumns []string
+	// VideoFormat is written as the VIDEO_FORMAT header field of an ALE.
+	VideoFormat string
+}
+
+// DefaultFLExOptions returns options with reels from DefaultReelColumns and
+// 1080 ALEs.
+func DefaultFLExOptions() FLExOptions {
+	return FLExOptions{
+		ReelColumns: DefaultReelColumns,
+		VideoFormat: format.VideoHD1080.GetValue(),
+	}
+}
+
+// Columns always written by FromFLEx
+var flexColumns = []string{"Name", "Tape", "Start", "End", "Duration", "Tracks"}
+
+// Columns written by FromFLEx when any event has a value for them
+var flexLogColumns = []string{
+	"Scene", "Take", "Description", "Camroll", "Labroll", "Soundroll", "Sound TC", "KN Start", "Pullin", "Ink Number", "Comments",
+}
+
+// FromFLEx converts the events of a FLEx telecine log to ALE rows, one per
+// event. Clips are named from the scene and take, as "12A-3", or else from
+// the video reel and edit number. The gauge and perforations of the first
+// event with a film record become the FILM_FORMAT header field.
+func FromFLEx(log *flextypes.Object, opts FLExOptions) (*types.Object, error) {
+	if log == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	if len(log.Events) == 0 {
+		return nil, errors.ErrInputEmpty.WithContext("no FLEx events")
+	}
+	rate, err := flexRate(log, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]string
+	used := make(map[string]bool)
+	filmValue := ""
+	for _, event := range log.Events {
+		start, end := event.VideoIn, event.VideoOut
+		if end.Sub(start, rate) <= 0 {
+			return nil, errors.ErrInputInvalidTimecode.WithContext(fmt.Sprintf("edit %d: Out %s is not after In %s", event.Number, end, start))
+		}
+		start.DropFrame, end.DropFrame = rate.DropFrame, rate.DropFrame
+		name := event.Scene
+		if event.Scene != "" && event.Take != "" {
+			name += "-" + event.Take
+		} else if event.Scene == "" {
+			name = fmt.Sprintf("%s_%03d", event.Reel, event.Number)
+		}
+		values := map[string]string{
+			"Name":        name,
+			"Tape":        event.Reel,
+			"Start":       start.String(),
+			"End":         end.String(),
+			"Duration":    formatDuration(end.Sub(start, rate), rate),
+			"Tracks":      "V",
+			"Scene":       event.Scene,
+			"Take":        event.Take,
+			"Description": event.Description,
+			"Camroll":     event.Camroll,
+			"Labroll":     event.Labroll,
+			"Soundroll":   event.Soundroll,
+			"KN Start":    event.Keycode,
+			"Pullin":      event.Pullin,
+			"Ink Number":  event.Ink,
+			"Comments":    strings.Join(event.Comments, "; "),
+		}
+		if !event.SoundTC.IsZero() {
+			values["Sound TC"] = event.SoundTC.String()
+		}
+		if filmValue == "" && event.Gauge != "" {
+			gauge, _ := strconv.Atoi(event.Gauge)
+			film, err := keycode.FormatFor(gauge, event.Perfs)
+			if err != nil {
+				return nil, errors.ErrInputInvalidFilmFormat.WithContext(fmt.Sprintf("edit %d: %v", event.Number, err))
+			}
+			filmValue = film.String()
+		}
+
+		for column, v := range values {
+			used[column] = used[column] || v != ""
+		}
+		records = append(records, values)
+	}
+
+	// Leave out log columns that no event has a value for
+	columns := append([]string(nil), flexColumns...)
+	for _, column := range flexLogColumns {
+		if used[column] {
+			columns = append(columns, column)
+		}
+	}
+	rows := make([][]string, len(records))
+	for i, values := range records {
+		rows[i] = make([]string, len(columns))
+		for j, column := range columns {
+			rows[i][j] = values[column]
+		}
+	}
+
+	fps := rate
+	fps.DropFrame = false
+	videoFormat := opts.VideoFormat
+	if videoFormat == "" {
+		videoFormat = format.VideoHD1080.GetValue()
+	}
+	headerFields := []types.Field{
+		format.DelimiterTab,
+		types.BaseField{Key: "VIDEO_FORMAT", Value: videoFormat},
+	}
+	if filmValue != "" {
+		headerFields = append(headerFields, types.BaseField{Key: "FILM_FORMAT", Value: filmValue})
+	}
+	headerFields = append(headerFields, format.AudioPCM48, types.BaseField{Key: "FPS", Value: fps.String()})
+	return types.NewObject(headerFields, columns, rows), nil
+}
+
+// ToFLEx converts the rows of an ALE to FLEx events, one per row, recorded on
+// field 1. Start and End are the video timecodes; a row without End uses
+// Duration. The gauge and perforations come from the FILM_FORMAT header field.
+func ToFLEx(obj *types.Object, opts FLExOptions) (*flextypes.Object, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	rate, err := clipRate(obj, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+	reelColumns := opts.ReelColumns
+	if len(reelColumns) == 0 {
+		reelColumns = DefaultReelColumns
+	}
+	var gauge string
+	var perfs int
+	if obj.FilmFormat.GetValue() != "" {
+		film, err := filmFormat(obj, keycode.Format{})
+		if err != nil {
+			return nil, err
+		}
+		gauge, perfs = strconv.Itoa(film.Gauge), film.Perfs
+	}
+
+	log := &flextypes.Object{
+		Version: flextypes.Version,
+		Title:   opts.Title,
+		Events:  make([]flextypes.Event, 0, len(obj.Rows)),
+	}
+	for i, row := range obj.Rows {
+		reel, reelErr := reelName(obj, row, reelColumns)
+		if reelErr != nil {
+			return nil, reelErr.WithContext(fmt.Sprintf("row %d", i))
+		}
+		start, end, err := sourceRange(row, rate, i)
+		if err != nil {
+			return nil, err
+		}
+		start.DropFrame, end.DropFrame = rate.DropFrame, rate.DropFrame
+		event := flextypes.Event{
+			Number:   i + 1,
+			Field:    1,
+			Reel:     reel,
+			VideoIn:  start,
+			VideoOut: end,
+			Rate:     rate,
+		}
+		event.Scene, _ = value(row, "Scene")
+		event.Take, _ = value(row, "Take")
+		event.Description, _ = value(row, "Description")
+		event.Camroll, _ = value(row, "Camroll")
+		event.Labroll, _ = value(row, "Labroll")
+		event.Soundroll, _ = value(row, "Soundroll")
+		event.Keycode, _ = value(row, "KN Start")
+		event.Pullin, _ = value(row, PulldownColumns...)
+		event.Ink, _ = value(row, "Ink Number")
+		if soundTC, column := value(row, "Sound TC"); soundTC != "" {
+			// Sound timecode may run at another rate, so it is not validated at the video rate
+			if event.SoundTC, err = timecode.Parse(soundTC); err != nil {
+				return nil, errors.ErrInputInvalidTimecode.WithContext(fmt.Sprintf("row %d, column %q: %v", i, column, err))
+			}
+		}
+		if comments, _ := value(row, "Comments"); comments != "" {
+			event.Comments = []string{comments}
+		}
+		if event.Keycode != "" || event.Pullin != "" || event.Ink != "" {
+			event.Gauge, event.Perfs = gauge, perfs
+		}
+		log.Events = append(log.Events, event)
+	}
+	return log, nil
+}
+
+// flexRate returns the frame rate of a FLEx log's video timecodes: rate if it
+// is set, or else the FPS of its edit records, which must agree. The rate
+// counts in drop frame if the FPS says so or the timecodes are written with
+// drop frame separators.
+func flexRate(log *flextypes.Object, rate timecode.Rate) (timecode.Rate, error) {
+	if rate.IsZero() {
+		for _, event := range log.Events {
+			if event.Rate.IsZero() {
+				continue
+			}
+			if !rate.IsZero() && event.Rate != rate {
+				return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("edit %d is at %s fps, not %s", event.Number, event.Rate, rate))
+			}
+			rate = event.Rate
+		}
+	}
+	if rate.IsZero() {
+		return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext("no FPS in FLEx edit records or frame rate option")
+	}
+	if log.Events[0].VideoIn.DropFrame {
+		if !rate.CanDropFrame() {
+			return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("drop frame timecode at %s fps", rate))
+		}
+		rate.DropFrame = true
+	}
+	return rate, nil
+}
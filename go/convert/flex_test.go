@@ -0,0 +1,179 @@
+package convert
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libflex/flex"
+	flextypes "lib-post-interchange/libflex/types"
+	"lib-post-interchange/timecode"
+)
+
+func TestFLExRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("../../samples/FLEx/A001R1AA.flx")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	log, err := flex.Read(string(data))
+	if err != nil {
+		t.Fatalf("flex.Read() error = %v", err)
+	}
+	obj, err := FromFLEx(log, DefaultFLExOptions())
+	if err != nil {
+		t.Fatalf("FromFLEx() error = %v", err)
+	}
+
+	wantColumns := []string{"Name", "Tape", "Start", "End", "Duration", "Tracks", "Scene", "Take", "Description", "Camroll",
+		"Labroll", "Soundroll", "Sound TC", "KN Start", "Pullin", "Ink Number", "Comments"}
+	if !reflect.DeepEqual(obj.ColumnNames(), wantColumns) {
+		t.Errorf("Columns = %v, want %v", obj.ColumnNames(), wantColumns)
+	}
+	want := []string{"12A-3", "001", "01:00:00:00", "01:00:12:10", "00:00:12:10", "V", "12A", "3", "EXT. PIER - NIGHT", "A001",
+		"L017", "S004", "13:22:05:14", "KJ 23 4512 8765+07", "A", "012 0456", ""}
+	if got := obj.Values(obj.Rows[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("Row = %v, want %v", got, want)
+	}
+	if obj.FPS.GetValue() != "29.97" || obj.FilmFormat.GetValue() != "35 mm" {
+		t.Errorf("FPS = %q, FILM_FORMAT = %q", obj.FPS.GetValue(), obj.FilmFormat.GetValue())
+	}
+
+	// The comment before the first edit has no ALE column
+	opts := DefaultFLExOptions()
+	opts.Title = log.Title
+	back, err := ToFLEx(obj, opts)
+	if err != nil {
+		t.Fatalf("ToFLEx() error = %v", err)
+	}
+	got, err := flex.Write(back)
+	if err != nil {
+		t.Fatalf("flex.Write() error = %v", err)
+	}
+	log.Comments = nil
+	wantText, _ := flex.Write(log)
+	if got != wantText {
+		t.Errorf("Round trip differs:\n%s\nwant:\n%s", got, wantText)
+	}
+}
+
+func TestToFLEx(t *testing.T) {
+	obj, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t29.97\nFILM_FORMAT\t35mm, 3 perf\n\nColumn\n" +
+		"Name\tSource File\tStart\tDuration\tKN Start\tSound TC\n\nData\n" +
+		"A\t/Volumes/Media/B004C003.mov\t01:00:00;00\t00:00:01;00\tKJ 23 4512 8765+07\t13:22:05:14\n" +
+		"B\t/Volumes/Media/B004C004.mov\t01:00:10;00\t00:00:01;00\t\t\n")
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	log, err := ToFLEx(obj, DefaultFLExOptions())
+	if err != nil {
+		t.Fatalf("ToFLEx() error = %v", err)
+	}
+	first := log.Events[0]
+	if first.Reel != "B004C003" || first.Rate != timecode.Rate29_97DF || first.VideoOut.String() != "01:00:01;00" {
+		t.Errorf("First event = %+v", first)
+	}
+	if first.Gauge != "35" || first.Perfs != 3 || first.SoundTC != timecode.MustParse("13:22:05:14") {
+		t.Errorf("Keycode and sound = %+v", first)
+	}
+	if second := log.Events[1]; second.HasFilm() || second.HasSound() || second.Number != 2 {
+		t.Errorf("Second event = %+v", second)
+	}
+
+	back, err := FromFLEx(log, DefaultFLExOptions())
+	if err != nil {
+		t.Fatalf("FromFLEx() error = %v", err)
+	}
+	if back.FilmFormat.GetValue() != "35 mm, 3 perf" {
+		t.Errorf("FILM_FORMAT = %q", back.FilmFormat.GetValue())
+	}
+	if got := back.Values(back.Rows[1])[0]; got != "B004C004_002" {
+		t.Errorf("Name = %q, want B004C004_002", got)
+	}
+
+	if _, err := ToFLEx(nil, DefaultFLExOptions()); err != errors.ErrOutputNilObject {
+		t.Errorf("ToFLEx(nil) error = %v", err)
+	}
+}
+
+func TestFromFLEx(t *testing.T) {
+	event := func(number int, rate timecode.Rate, in, out string) flextypes.Event {
+		return flextypes.Event{Number: number, Reel: "001", Rate: rate, VideoIn: timecode.MustParse(in), VideoOut: timecode.MustParse(out)}
+	}
+	tests := []struct {
+		name    string
+		log     *flextypes.Object
+		opts    func(*FLExOptions)
+		wantErr *errors.Error
+		check   func(*testing.T, []string)
+	}{
+		{
+			name: "rate option",
+			log:  &flextypes.Object{Events: []flextypes.Event{event(1, timecode.Rate{}, "01:00:00:00", "01:00:01:00")}},
+			opts: func(opts *FLExOptions) { opts.Rate = timecode.Rate25 },
+			check: func(t *testing.T, row []string) {
+				if row[0] != "001_001" || row[4] != "00:00:01:00" {
+					t.Errorf("Row = %v", row)
+				}
+			},
+		},
+		{
+			name: "drop frame duration",
+			log:  &flextypes.Object{Events: []flextypes.Event{event(1, timecode.Rate29_97DF, "01:00:00;00", "01:10:00;00")}},
+			check: func(t *testing.T, row []string) {
+				if row[2] != "01:00:00;00" || row[3] != "01:10:00;00" || row[4] != "00:09:59:12" {
+					t.Errorf("Start, End, Duration = %v, want drop frame timecodes and 00:09:59:12", row[2:5])
+				}
+			},
+		},
+		{
+			name:    "no rate",
+			log:     &flextypes.Object{Events: []flextypes.Event{event(1, timecode.Rate{}, "01:00:00:00", "01:00:01:00")}},
+			wantErr: errors.ErrInputInvalidFrameRate,
+		},
+		{
+			name: "mixed rates",
+			log: &flextypes.Object{Events: []flextypes.Event{
+				event(1, timecode.Rate29_97, "01:00:00:00", "01:00:01:00"),
+				event(2, timecode.Rate25, "01:00:00:00", "01:00:01:00"),
+			}},
+			wantErr: errors.ErrInputInvalidFrameRate,
+		},
+		{
+			name:    "out before in",
+			log:     &flextypes.Object{Events: []flextypes.Event{event(1, timecode.Rate25, "01:00:01:00", "01:00:00:00")}},
+			wantErr: errors.ErrInputInvalidTimecode,
+		},
+		{
+			name:    "no events",
+			log:     &flextypes.Object{Title: "empty"},
+			wantErr: errors.ErrInputEmpty,
+		},
+		{
+			name:    "nil",
+			wantErr: errors.ErrOutputNilObject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultFLExOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			obj, err := FromFLEx(tt.log, opts)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("FromFLEx() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromFLEx() error = %v", err)
+			}
+			tt.check(t, obj.Values(obj.Rows[0]))
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package convert
+
+import (
+	"fmt"
+
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+)
+
+// KeycodeOptions controls how the key numbers of an ALE are checked.
+type KeycodeOptions struct {
+	// Format is the film format of the key numbers. The zero value means the
+	// ALE's FILM_FORMAT header field, or 35 mm 4-perf without one.
+	Format keycode.Format
+	// Rate is the frame rate of the clips. The zero value means the ALE's FPS header field.
+	Rate timecode.Rate
+}
+
+// DefaultKeycodeOptions returns options that take the film format and frame
+// rate from the ALE's header.
+func DefaultKeycodeOptions() KeycodeOptions {
+	return KeycodeOptions{}
+}
+
+// filmFrameRate is the rate film is shot at. Video at 30 or 60 frames per
+// second holds it with pulldown.
+const filmFrameRate = 24
+
+// CheckKeycodes checks the KN Start and KN End columns of an ALE: that each
+// is a valid keycode in the film format, that both are on the same roll, and
+// that the film frames between them match the row's duration. At 29.97, 30,
+// 59.94 and 60 fps the video is taken to hold 24 fps film with pulldown, and
+// one frame of difference is allowed for the pulldown phase; at other rates
+// each video frame is one film frame. Rows without KN Start are not checked.
+// A problem with a row is returned as a diagnostic.
+func CheckKeycodes(obj *types.Object, opts KeycodeOptions) ([]RowDiagnostic, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	film, err := filmFormat(obj, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	rate, rateErr := clipRate(obj, opts.Rate)
+
+	var diagnostics []RowDiagnostic
+	for i, row := range obj.Rows {
+		name, _ := value(row, "Name")
+		report := func(format string, args ...any) {
+			diagnostics = append(diagnostics, RowDiagnostic{Row: i, Name: name, Message: fmt.Sprintf(format, args...)})
+		}
+		startValue, _ := value(row, "KN Start")
+		endValue, _ := value(row, "KN End")
+		if startValue == "" {
+			if endValue != "" {
+				report("KN End without KN Start")
+			}
+			continue
+		}
+
+		start, ok := checkKeycode(startValue, "KN Start", film, report)
+		if !ok || endValue == "" {
+			continue
+		}
+		end, ok := checkKeycode(endValue, "KN End", film, report)
+		if !ok {
+			continue
+		}
+		filmFrames, err := end.Sub(start, film)
+		if err != nil {
+			report("KN Start %s and KN End %s are on different rolls", start, end)
+			continue
+		}
+		if filmFrames <= 0 {
+			report("KN End %s is not after KN Start %s", end, start)
+			continue
+		}
+
+		// Rows without a duration are checked for their keycodes alone
+		if rateErr != nil {
+			continue
+		}
+		videoStart, videoEnd, err := sourceRange(row, rate, i)
+		if err != nil {
+			continue
+		}
+		videoFrames := videoEnd.Sub(videoStart, rate)
+		want, tolerance := videoFrames, 0
+		if base := rate.Base(); base == 30 || base == 60 {
+			want, tolerance = (videoFrames*filmFrameRate+base/2)/base, 1
+		}
+		if diff := filmFrames - want; diff > tolerance || diff < -tolerance {
+			report("KN Start %s to KN End %s is %d film frames, but the duration of %d video frames holds %d",
+				start, end, filmFrames, videoFrames, want)
+		}
+	}
+	return diagnostics, nil
+}
+
+// filmFormat returns f if it is set, or else the format in the object's
+// FILM_FORMAT header field, or 35 mm 4-perf without one.
+func filmFormat(obj *types.Object, f keycode.Format) (keycode.Format, error) {
+	if !f.IsZero() {
+		return f, nil
+	}
+	value := obj.FilmFormat.GetValue()
+	if value == "" {
+		return keycode.Format35mm, nil
+	}
+	f, err := keycode.ParseFormat(value)
+	if err != nil {
+		return keycode.Format{}, errors.ErrInputInvalidFilmFormat.WithContext(err.Error())
+	}
+	return f, nil
+}
+
+// checkKeycode parses and validates the keycode in a column, reporting a
+// problem with it.
+func checkKeycode(s, column string, film keycode.Format, report func(string, ...any)) (keycode.Keycode, bool) {
+	k, err := keycode.Parse(s)
+	if err == nil {
+		err = k.Validate(film)
+	}
+	if err != nil {
+		report("column %q: %v", column, err)
+		return keycode.Keycode{}, false
+	}
+	return k, true
+}
@@ -0,0 +1,105 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+)
+
+func TestCheckKeycodes(t *testing.T) {
+	header := "Heading\nFIELD_DELIM\tTABS\nFPS\t%s\n%s\nColumn\nName\tStart\tDuration\tKN Start\tKN End\n\nData\n"
+	read := func(fps, film, data string) string {
+		extra := ""
+		if film != "" {
+			extra = "FILM_FORMAT\t" + film + "\n"
+		}
+		return strings.Replace(strings.Replace(header, "%s", fps, 1), "%s", extra, 1) + data
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		opts    func(*KeycodeOptions)
+		want    []string
+		wantErr *errors.Error
+	}{
+		{
+			name: "24 fps at the default 35 mm",
+			input: read("24", "",
+				"A\t01:00:00:00\t00:00:01:00\tKJ 23 4512 0100+00\tKJ 23 4512 0101+08\n"+
+					"B\t01:00:00:00\t00:00:01:00\tKJ 23 4512 0100+00\tKJ 23 4512 0101+09\n"+
+					"C\t\t\tKJ 23 4512 0100+00\t\n"+
+					"D\t01:00:00:00\t00:00:01:00\t\t\n"),
+			want: []string{"row 1 (B): KN Start KJ 23 4512 0100+00 to KN End KJ 23 4512 0101+09 is 25 film frames, but the duration of 24 video frames holds 24"},
+		},
+		{
+			name: "29.97 with pulldown at 16 mm",
+			input: read("29.97", "16 mm",
+				"A\t01:00:00:00\t00:00:05:00\tEK 12 3456 0010+00\tEK 12 3456 0016+00\n"+
+					"B\t01:00:00:00\t00:00:05:00\tEK 12 3456 0010+00\tEK 12 3456 0016+01\n"+
+					"C\t01:00:00:00\t00:00:05:00\tEK 12 3456 0010+00\tEK 12 3456 0016+02\n"),
+			want: []string{"row 2 (C): KN Start EK 12 3456 0010+00 to KN End EK 12 3456 0016+02 is 122 film frames, but the duration of 150 video frames holds 120"},
+		},
+		{
+			name: "malformed and mismatched keycodes",
+			input: read("25", "35 mm, 3 perf",
+				"A\t01:00:00:00\t00:00:01:00\tKJ 23 4512 0100+22\tKJ 23 4512 0101+00\n"+
+					"B\t01:00:00:00\t00:00:01:00\tKJ 23 4512 0100+00\tKJ 23 9999 0101+00\n"+
+					"C\t01:00:00:00\t00:00:01:00\tKJ 23 4512 0100+00\tKJ 23 4512 0099+00\n"+
+					"D\t01:00:00:00\t00:00:01:00\t\tKJ 23 4512 0101+00\n"),
+			want: []string{
+				`row 0 (A): column "KN Start": keycode: [1.0] invalid keycode: KJ 23 4512 0100+22 is past the next key number at 35 mm, 3 perf`,
+				"row 1 (B): KN Start KJ 23 4512 0100+00 and KN End KJ 23 9999 0101+00 are on different rolls",
+				"row 2 (C): KN End KJ 23 4512 0099+00 is not after KN Start KJ 23 4512 0100+00",
+				"row 3 (D): KN End without KN Start",
+			},
+		},
+		{
+			name:  "format option",
+			input: read("24", "16 mm", "A\t01:00:00:00\t00:00:01:00\tKJ 23 4512 0100+00\tKJ 23 4512 0101+08\n"),
+			opts:  func(opts *KeycodeOptions) { opts.Format = keycode.Format35mm },
+		},
+		{
+			name:    "unknown film format",
+			input:   read("24", "Super 8", "A\t01:00:00:00\t00:00:01:00\tKJ 23 4512 0100+00\t\n"),
+			wantErr: errors.ErrInputInvalidFilmFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := ale.Read(tt.input)
+			if err != nil {
+				t.Fatalf("ale.Read() error = %v", err)
+			}
+			opts := DefaultKeycodeOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			diagnostics, err := CheckKeycodes(obj, opts)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("CheckKeycodes() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckKeycodes() error = %v", err)
+			}
+			var got []string
+			for _, d := range diagnostics {
+				got = append(got, d.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	if _, err := CheckKeycodes(nil, DefaultKeycodeOptions()); err != errors.ErrOutputNilObject {
+		t.Errorf("CheckKeycodes(nil) error = %v", err)
+	}
+}
@@ -0,0 +1,509 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/format"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+)
+
+// OpenTimelineIO schemas written by ToOTIO
+const (
+	otioTimelineSchema          = "Timeline.1"
+	otioStackSchema             = "Stack.1"
+	otioTrackSchema             = "Track.1"
+	otioClipSchema              = "Clip.1"
+	otioExternalReferenceSchema = "ExternalReference.1"
+	otioMissingReferenceSchema  = "MissingReference.1"
+	otioTimeRangeSchema         = "TimeRange.1"
+	otioRationalTimeSchema      = "RationalTime.1"
+)
+
+// Track kinds of an OpenTimelineIO timeline
+const (
+	otioVideo = "Video"
+	otioAudio = "Audio"
+)
+
+// Columns always written by FromOTIO
+var otioColumns = []string{"Name", "Source File", "Start", "End", "Duration", "Tracks"}
+
+// OTIOOptions controls how ALE clips are laid out as an OpenTimelineIO timeline.
+type OTIOOptions struct {
+	// Name is the timeline's name.
+	Name string
+	// RecordStart is the timeline's global start time.
+	RecordStart timecode.Timecode
+	// Rate is the frame rate of the clips. The zero value means the ALE's FPS header field.
+	Rate timecode.Rate
+}
+
+// DefaultOTIOOptions returns a timeline starting at 01:00:00:00.
+func DefaultOTIOOptions() OTIOOptions {
+	return OTIOOptions{RecordStart: timecode.Timecode{Hours: 1}}
+}
+
+// FromOTIOOptions controls how the clips of an OpenTimelineIO timeline become ALE rows.
+type FromOTIOOptions struct {
+	// VideoFormat is written as the VIDEO_FORMAT header field when the
+	// timeline does not carry the ALE's header fields.
+	VideoFormat string
+	// DropFrame counts timecodes in drop frame at 29.97 and 59.94. It is also
+	// set by drop frame Start values in the clips' ALE metadata.
+	DropFrame bool
+	// IncludeAudio keeps the clips of audio tracks.
+	IncludeAudio bool
+}
+
+// DefaultFromOTIOOptions returns 1080 options keeping the clips of video tracks only.
+func DefaultFromOTIOOptions() FromOTIOOptions {
+	return FromOTIOOptions{VideoFormat: format.VideoHD1080.GetValue()}
+}
+
+// otioRationalTime is a RationalTime: a number of frames at a rate.
+type otioRationalTime struct {
+	Schema string     `json:"OTIO_SCHEMA"`
+	Rate   otioNumber `json:"rate"`
+	Value  otioNumber `json:"value"`
+}
+
+// otioNumber is a floating point number, always written with a decimal point
+// as OpenTimelineIO expects of rates and times.
+type otioNumber float64
+
+// MarshalJSON implements the json.Marshaler interface.
+func (n otioNumber) MarshalJSON() ([]byte, error) {
+	s := strconv.FormatFloat(float64(n), 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return []byte(s), nil
+}
+
+// otioTimeRange is a TimeRange.
+type otioTimeRange struct {
+	Schema    string           `json:"OTIO_SCHEMA"`
+	StartTime otioRationalTime `json:"start_time"`
+	Duration  otioRationalTime `json:"duration"`
+}
+
+// otioMediaReference is an ExternalReference, MissingReference or other media reference.
+type otioMediaReference struct {
+	Schema         string         `json:"OTIO_SCHEMA"`
+	Name           string         `json:"name"`
+	TargetURL      string         `json:"target_url,omitempty"`
+	AvailableRange *otioTimeRange `json:"available_range"`
+	Metadata       struct{}       `json:"metadata"`
+}
+
+// otioClip is a Clip. Gaps, transitions and other track items are read into
+// it too, and told apart by Schema.
+type otioClip struct {
+	Schema         string              `json:"OTIO_SCHEMA"`
+	Name           string              `json:"name"`
+	SourceRange    *otioTimeRange      `json:"source_range"`
+	MediaReference *otioMediaReference `json:"media_reference,omitempty"`
+	// MediaReferences and ActiveMediaReferenceKey are read from Clip.2
+	MediaReferences         map[string]*otioMediaReference `json:"media_references,omitempty"`
+	ActiveMediaReferenceKey string                         `json:"active_media_reference_key,omitempty"`
+	Metadata                struct {
+		ALE otioFields `json:"ALE,omitempty"`
+	} `json:"metadata"`
+	Effects []json.RawMessage `json:"effects"`
+	Markers []json.RawMessage `json:"markers"`
+}
+
+// otioTrack is a Track.
+type otioTrack struct {
+	Schema   string     `json:"OTIO_SCHEMA"`
+	Name     string     `json:"name"`
+	Kind     string     `json:"kind"`
+	Children []otioClip `json:"children"`
+}
+
+// otioStack is a Stack of tracks.
+type otioStack struct {
+	Schema   string      `json:"OTIO_SCHEMA"`
+	Name     string      `json:"name"`
+	Children []otioTrack `json:"children"`
+}
+
+// otioTimeline is a Timeline.
+type otioTimeline struct {
+	Schema          string            `json:"OTIO_SCHEMA"`
+	Name            string            `json:"name"`
+	GlobalStartTime *otioRationalTime `json:"global_start_time"`
+	Metadata        struct {
+		ALE *otioALEMetadata `json:"ALE,omitempty"`
+	} `json:"metadata"`
+	Tracks otioStack `json:"tracks"`
+}
+
+// otioALEMetadata holds the header fields and column order of an ALE, in the
+// form written by OpenTimelineIO's ALE adapter.
+type otioALEMetadata struct {
+	Header  otioFields `json:"header"`
+	Columns []string   `json:"columns"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Metadata in
+// another form, as written by other tools, is ignored.
+func (m *otioALEMetadata) UnmarshalJSON(data []byte) error {
+	type plain otioALEMetadata
+	var p plain
+	if json.Unmarshal(data, &p) == nil {
+		*m = otioALEMetadata(p)
+	}
+	return nil
+}
+
+// otioFields is a JSON object of header fields or column values that keeps
+// the order of its keys. Values other than strings are kept as their JSON
+// text, and anything other than an object reads as no fields.
+type otioFields []types.BaseField
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f otioFields) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, field := range f {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *otioFields) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		*f = nil
+		return nil
+	}
+	var fields otioFields
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+		fields = append(fields, types.BaseField{Key: key, Value: value})
+	}
+	*f = fields
+	return nil
+}
+
+// get returns the value of a key, ignoring case.
+func (f otioFields) get(key string) string {
+	for _, field := range f {
+		if strings.EqualFold(field.Key, key) {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// ToOTIO lays the clips of an ALE end to end on the video track of an
+// OpenTimelineIO timeline, returned as .otio JSON. Each row becomes a clip
+// using the whole of its media: Start and End, or Start and Duration, are
+// the available range of a reference to the Source File. A row's values are
+// kept in the clip's "ALE" metadata, and the header fields and column order
+// in the timeline's, so that FromOTIO can restore them.
+func ToOTIO(obj *types.Object, opts OTIOOptions) ([]byte, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	rate, err := clipRate(obj, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := obj.ColumnNames()
+	track := otioTrack{
+		Schema:   otioTrackSchema,
+		Name:     "V1",
+		Kind:     otioVideo,
+		Children: make([]otioClip, 0, len(obj.Rows)),
+	}
+	for i, row := range obj.Rows {
+		start, end, err := sourceRange(row, rate, i)
+		if err != nil {
+			return nil, err
+		}
+		available := otioRange(start, end, rate)
+		reference := &otioMediaReference{Schema: otioMissingReferenceSchema, AvailableRange: available}
+		if file, _ := value(row, "Source File"); file != "" {
+			reference.Schema = otioExternalReferenceSchema
+			reference.TargetURL = otioTargetURL(file)
+		}
+		clip := otioClip{
+			Schema:         otioClipSchema,
+			SourceRange:    available,
+			MediaReference: reference,
+			Effects:        []json.RawMessage{},
+			Markers:        []json.RawMessage{},
+		}
+		clip.Name, _ = value(row, "Name")
+		values := obj.Values(row)
+		clip.Metadata.ALE = make(otioFields, len(columns))
+		for j, column := range columns {
+			clip.Metadata.ALE[j] = types.BaseField{Key: column, Value: values[j]}
+		}
+		track.Children = append(track.Children, clip)
+	}
+
+	header := make(otioFields, 0, len(obj.HeaderFields))
+	for _, field := range obj.HeaderFields {
+		if field != nil {
+			header = append(header, types.BaseField{Key: field.GetKey(), Value: field.GetValue()})
+		}
+	}
+	globalStart := otioTime(opts.RecordStart.ToFrames(rate), rate)
+	timeline := otioTimeline{
+		Schema:          otioTimelineSchema,
+		Name:            opts.Name,
+		GlobalStartTime: &globalStart,
+		Tracks: otioStack{
+			Schema:   otioStackSchema,
+			Name:     "tracks",
+			Children: []otioTrack{track},
+		},
+	}
+	timeline.Metadata.ALE = &otioALEMetadata{Header: header, Columns: columns}
+	return json.MarshalIndent(timeline, "", "    ")
+}
+
+// FromOTIO converts the clips of an OpenTimelineIO timeline in .otio JSON to
+// ALE rows, one per clip, leaving out repeats of the same media. Start and
+// End are the available range of the clip's media reference, or else its
+// source range. Name and Source File come from the clip and its reference,
+// and other columns from the clip's "ALE" metadata, as written by ToOTIO or
+// OpenTimelineIO's ALE adapter, which also keeps the header fields and
+// column order in the timeline's.
+func FromOTIO(data []byte, opts FromOTIOOptions) (*types.Object, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.ErrInputEmpty
+	}
+	var timeline otioTimeline
+	if err := json.Unmarshal(data, &timeline); err != nil {
+		return nil, errors.ErrInputFailedOTIO.WithContext(err.Error())
+	}
+	if !strings.HasPrefix(timeline.Schema, "Timeline.") {
+		return nil, errors.ErrInputFailedOTIO.WithContext(fmt.Sprintf("expected a Timeline, not %q", timeline.Schema))
+	}
+	metadata := timeline.Metadata.ALE
+	if metadata == nil {
+		metadata = &otioALEMetadata{}
+	}
+
+	var rate timecode.Rate
+	columns := append(append([]string(nil), metadata.Columns...), otioColumns...)
+	var records []map[string]string
+	seen := make(map[string]bool)
+	for _, track := range timeline.Tracks.Children {
+		if !strings.HasPrefix(track.Schema, "Track.") || !(track.Kind == otioVideo || (opts.IncludeAudio && track.Kind == otioAudio)) {
+			continue
+		}
+		for _, clip := range track.Children {
+			if !strings.HasPrefix(clip.Schema, "Clip.") {
+				continue
+			}
+			reference := clip.MediaReference
+			if reference == nil {
+				key := clip.ActiveMediaReferenceKey
+				if key == "" {
+					key = "DEFAULT_MEDIA"
+				}
+				reference = clip.MediaReferences[key]
+			}
+			r := clip.SourceRange
+			if reference != nil && reference.AvailableRange != nil {
+				r = reference.AvailableRange
+			}
+			if r == nil {
+				return nil, errors.ErrInputMissingValue.WithContext(fmt.Sprintf("clip %q: no available or source range", clip.Name))
+			}
+
+			clipRate, ok := otioRate(float64(r.StartTime.Rate))
+			if !ok {
+				return nil, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("clip %q: %v fps", clip.Name, r.StartTime.Rate))
+			}
+			if rate.IsZero() {
+				rate = clipRate
+				if opts.DropFrame || strings.ContainsAny(clip.Metadata.ALE.get("Start"), ";,.") {
+					if !rate.CanDropFrame() {
+						return nil, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("drop frame at %s fps", rate))
+					}
+					rate.DropFrame = true
+				}
+			} else if clipRate.Num != rate.Num || clipRate.Den != rate.Den {
+				return nil, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("clip %q: %s fps in a %s fps timeline", clip.Name, clipRate, rate))
+			}
+			start := timecode.FromFrames(int(math.Round(float64(r.StartTime.Value))), rate)
+			frames := r.Duration.Value
+			if r.Duration.Rate > 0 {
+				frames *= otioNumber(rate.FPS()) / r.Duration.Rate
+			}
+			length := int(math.Round(float64(frames)))
+			end := start.Add(length, rate)
+
+			// Values are keyed by the first spelling of each column name
+			values := make(map[string]string)
+			set := func(column, v string) {
+				i := columnIndex(columns, column)
+				if i < 0 {
+					i = len(columns)
+					columns = append(columns, column)
+				}
+				values[columns[i]] = v
+			}
+			get := func(column string) string {
+				return values[columns[columnIndex(columns, column)]]
+			}
+			for _, field := range clip.Metadata.ALE {
+				set(field.Key, field.Value)
+			}
+			if clip.Name != "" {
+				set("Name", clip.Name)
+			}
+			if reference != nil && reference.TargetURL != "" {
+				set("Source File", otioSourceFile(reference.TargetURL))
+			}
+			set("Start", start.String())
+			set("End", end.String())
+			set("Duration", formatDuration(length, rate))
+			if get("Tracks") == "" {
+				set("Tracks", map[string]string{otioVideo: "V", otioAudio: "A1"}[track.Kind])
+			}
+
+			key := get("Name") + "\x00" + get("Source File") + "\x00" + get("Start") + "\x00" + get("End")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			records = append(records, values)
+		}
+	}
+	if len(records) == 0 {
+		return nil, errors.ErrInputEmpty.WithContext("no clips on the timeline's tracks")
+	}
+
+	// Columns named in the timeline's metadata come first, each once
+	var names []string
+	for _, column := range columns {
+		if columnIndex(names, column) < 0 {
+			names = append(names, column)
+		}
+	}
+	rows := make([][]string, len(records))
+	for i, values := range records {
+		rows[i] = make([]string, len(names))
+		for j, column := range names {
+			rows[i][j] = values[column]
+		}
+	}
+
+	fps := rate
+	fps.DropFrame = false
+	headerFields := make([]types.Field, 0, len(metadata.Header)+1)
+	hasFPS := false
+	for _, field := range metadata.Header {
+		if field.Key == "FPS" {
+			field.Value = fps.String()
+			hasFPS = true
+		}
+		headerFields = append(headerFields, field)
+	}
+	if len(headerFields) == 0 {
+		videoFormat := opts.VideoFormat
+		if videoFormat == "" {
+			videoFormat = format.VideoHD1080.GetValue()
+		}
+		headerFields = append(headerFields,
+			format.DelimiterTab,
+			types.BaseField{Key: "VIDEO_FORMAT", Value: videoFormat},
+			format.AudioPCM48,
+		)
+	}
+	if !hasFPS {
+		headerFields = append(headerFields, types.BaseField{Key: "FPS", Value: fps.String()})
+	}
+	return types.NewObject(headerFields, names, rows), nil
+}
+
+// otioTime returns a number of frames as a RationalTime.
+func otioTime(frames int, rate timecode.Rate) otioRationalTime {
+	return otioRationalTime{Schema: otioRationalTimeSchema, Rate: otioNumber(rate.FPS()), Value: otioNumber(frames)}
+}
+
+// otioRange returns the frames from start up to end as a TimeRange.
+func otioRange(start, end timecode.Timecode, rate timecode.Rate) *otioTimeRange {
+	return &otioTimeRange{
+		Schema:    otioTimeRangeSchema,
+		StartTime: otioTime(start.ToFrames(rate), rate),
+		Duration:  otioTime(end.Sub(start, rate), rate),
+	}
+}
+
+// otioRate returns the frame rate of a RationalTime rate, which is exact for
+// whole rates and for 1000/1001 rates such as 23.976 written to any precision.
+func otioRate(fps float64) (timecode.Rate, bool) {
+	if fps <= 0 || math.IsInf(fps, 0) || math.IsNaN(fps) {
+		return timecode.Rate{}, false
+	}
+	if whole := math.Round(fps); math.Abs(fps-whole) < 1e-6 {
+		return timecode.Rate{Num: int(whole), Den: 1}, true
+	}
+	if ntsc := math.Round(fps * 1.001); math.Abs(fps-ntsc/1.001) < 1e-3 {
+		return timecode.Rate{Num: int(ntsc) * 1000, Den: 1001}, true
+	}
+	return timecode.Rate{}, false
+}
+
+// otioTargetURL returns a Source File as the target URL of a media
+// reference: a file URL for an absolute path, or else the value as written.
+func otioTargetURL(file string) string {
+	if path.IsAbs(file) {
+		return (&url.URL{Scheme: "file", Path: file}).String()
+	}
+	return file
+}
+
+// otioSourceFile returns the Source File of a media reference's target URL,
+// the path of a file URL or else the URL as written.
+func otioSourceFile(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Scheme == "file" {
+		return u.Path
+	}
+	return target
+}
@@ -0,0 +1,262 @@
+package convert
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+)
+
+func TestOTIORoundTrip(t *testing.T) {
+	obj, err := ale.ReadFile("../../samples/ALE/A001R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	opts := DefaultOTIOOptions()
+	opts.Name = "A001R1AA"
+	data, err := ToOTIO(obj, opts)
+	if err != nil {
+		t.Fatalf("ToOTIO() error = %v", err)
+	}
+
+	var timeline map[string]any
+	if err := json.Unmarshal(data, &timeline); err != nil {
+		t.Fatalf("ToOTIO() wrote invalid JSON: %v", err)
+	}
+	if timeline["OTIO_SCHEMA"] != "Timeline.1" || timeline["name"] != "A001R1AA" {
+		t.Errorf("Timeline = %v, %v", timeline["OTIO_SCHEMA"], timeline["name"])
+	}
+	for _, want := range []string{
+		`"OTIO_SCHEMA": "ExternalReference.1"`,
+		`"target_url": "A001C001_240426_R1AA.mxf"`,
+		// 03:44:36:21 at 25 fps
+		`"value": 336921.0`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("ToOTIO() output does not contain %s", want)
+		}
+	}
+
+	back, err := FromOTIO(data, DefaultFromOTIOOptions())
+	if err != nil {
+		t.Fatalf("FromOTIO() error = %v", err)
+	}
+	if !reflect.DeepEqual(back.ColumnNames(), obj.ColumnNames()) {
+		t.Errorf("Columns = %v, want %v", back.ColumnNames(), obj.ColumnNames())
+	}
+	if !reflect.DeepEqual(back.HeaderFields, obj.HeaderFields) {
+		t.Errorf("Header = %v, want %v", back.HeaderFields, obj.HeaderFields)
+	}
+	if len(back.Rows) != len(obj.Rows) {
+		t.Fatalf("Got %d rows, want %d", len(back.Rows), len(obj.Rows))
+	}
+	for i := range obj.Rows {
+		if got, want := back.Values(back.Rows[i]), obj.Values(obj.Rows[i]); !reflect.DeepEqual(got, want) {
+			t.Errorf("Row %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestToOTIO(t *testing.T) {
+	obj, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t29.97\n\nColumn\n" +
+		"Name\tSource File\tStart\tDuration\n\nData\n" +
+		"A\t/Volumes/Media/A 1.mov\t01:00:00;00\t00:00:01;00\n" +
+		"B\t\t02:00:00;00\t00:00:02;00\n")
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	data, err := ToOTIO(obj, DefaultOTIOOptions())
+	if err != nil {
+		t.Fatalf("ToOTIO() error = %v", err)
+	}
+	var timeline otioTimeline
+	if err := json.Unmarshal(data, &timeline); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	clips := timeline.Tracks.Children[0].Children
+	if len(clips) != 2 {
+		t.Fatalf("Got %d clips, want 2", len(clips))
+	}
+	if got := clips[0].MediaReference.TargetURL; got != "file:///Volumes/Media/A%201.mov" {
+		t.Errorf("target_url = %q", got)
+	}
+	if got := clips[1].MediaReference.Schema; got != "MissingReference.1" {
+		t.Errorf("Schema = %q, want MissingReference.1", got)
+	}
+	// Drop frame counts 107892 frames in the first hour
+	r := clips[0].SourceRange
+	if r.StartTime.Value != 107892 || r.Duration.Value != 30 || float64(r.StartTime.Rate) != timecode.Rate29_97.FPS() {
+		t.Errorf("source_range = %+v", r)
+	}
+
+	// Read back, the clip keeps its drop frame Start and its Duration is a
+	// length without drop frame
+	back, err := FromOTIO(data, DefaultFromOTIOOptions())
+	if err != nil {
+		t.Fatalf("FromOTIO() error = %v", err)
+	}
+	start, _ := back.Rows[0].Value("Start")
+	duration, _ := back.Rows[0].Value("Duration")
+	if start != "01:00:00;00" || duration != "00:00:01:00" {
+		t.Errorf("Start, Duration = %q, %q, want 01:00:00;00, 00:00:01:00", start, duration)
+	}
+
+	if _, err := ToOTIO(nil, DefaultOTIOOptions()); err != errors.ErrOutputNilObject {
+		t.Errorf("ToOTIO(nil) error = %v", err)
+	}
+}
+
+func TestFromOTIO(t *testing.T) {
+	// A timeline from an editing application, with 23.976 written to
+	// three decimals, a gap and a Clip.2 media reference
+	edited := `{
+    "OTIO_SCHEMA": "Timeline.1",
+    "name": "Cut",
+    "metadata": {"ALE": "not ours"},
+    "tracks": {
+        "OTIO_SCHEMA": "Stack.1",
+        "children": [
+            {
+                "OTIO_SCHEMA": "Track.1",
+                "kind": "Video",
+                "children": [
+                    {
+                        "OTIO_SCHEMA": "Clip.2",
+                        "name": "A001C001",
+                        "source_range": {"OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 100},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 24}},
+                        "media_references": {"DEFAULT_MEDIA": {
+                            "OTIO_SCHEMA": "ExternalReference.1",
+                            "target_url": "file:///media/A001C001.mov",
+                            "available_range": {"OTIO_SCHEMA": "TimeRange.1",
+                                "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 0},
+                                "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 240}}}},
+                        "active_media_reference_key": "DEFAULT_MEDIA",
+                        "metadata": {"ALE": {"Scene": "12A", "Take": 3}}
+                    },
+                    {
+                        "OTIO_SCHEMA": "Gap.1",
+                        "source_range": {"OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 0},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 24}}
+                    },
+                    {
+                        "OTIO_SCHEMA": "Clip.1",
+                        "name": "A001C001",
+                        "source_range": {"OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 200},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 24}},
+                        "media_reference": {
+                            "OTIO_SCHEMA": "ExternalReference.1",
+                            "target_url": "file:///media/A001C001.mov",
+                            "available_range": {"OTIO_SCHEMA": "TimeRange.1",
+                                "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 0},
+                                "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 240}}}
+                    }
+                ]
+            },
+            {
+                "OTIO_SCHEMA": "Track.1",
+                "kind": "Audio",
+                "children": [
+                    {
+                        "OTIO_SCHEMA": "Clip.1",
+                        "name": "MUSIC",
+                        "source_range": {"OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 48000, "value": 0},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 48000, "value": 48000}}
+                    }
+                ]
+            }
+        ]
+    }
+}`
+
+	tests := []struct {
+		name    string
+		input   string
+		opts    func(*FromOTIOOptions)
+		wantErr *errors.Error
+		check   func(*testing.T, *types.Object)
+	}{
+		{
+			name:  "edited timeline",
+			input: edited,
+			check: func(t *testing.T, obj *types.Object) {
+				wantColumns := []string{"Name", "Source File", "Start", "End", "Duration", "Tracks", "Scene", "Take"}
+				if !reflect.DeepEqual(obj.ColumnNames(), wantColumns) {
+					t.Errorf("Columns = %v, want %v", obj.ColumnNames(), wantColumns)
+				}
+				// The clip is used twice, and its media is listed once
+				if len(obj.Rows) != 1 {
+					t.Fatalf("Got %d rows, want 1", len(obj.Rows))
+				}
+				want := []string{"A001C001", "/media/A001C001.mov", "00:00:00:00", "00:00:10:00", "00:00:10:00", "V", "12A", "3"}
+				if got := obj.Values(obj.Rows[0]); !reflect.DeepEqual(got, want) {
+					t.Errorf("Row = %v, want %v", got, want)
+				}
+				if obj.FPS.GetValue() != "23.976" || obj.VideoFormat.GetValue() != "1080" {
+					t.Errorf("Header FPS = %q, VIDEO_FORMAT = %q", obj.FPS.GetValue(), obj.VideoFormat.GetValue())
+				}
+			},
+		},
+		{
+			name:    "audio at another rate",
+			input:   edited,
+			opts:    func(opts *FromOTIOOptions) { opts.IncludeAudio = true },
+			wantErr: errors.ErrInputInvalidFrameRate,
+		},
+		{
+			name:    "drop frame at 23.976",
+			input:   edited,
+			opts:    func(opts *FromOTIOOptions) { opts.DropFrame = true },
+			wantErr: errors.ErrInputInvalidFrameRate,
+		},
+		{
+			name:    "not a timeline",
+			input:   `{"OTIO_SCHEMA": "Clip.1"}`,
+			wantErr: errors.ErrInputFailedOTIO,
+		},
+		{
+			name:    "malformed",
+			input:   `{"OTIO_SCHEMA": "Timeline.1",`,
+			wantErr: errors.ErrInputFailedOTIO,
+		},
+		{
+			name:    "no clips",
+			input:   `{"OTIO_SCHEMA": "Timeline.1", "tracks": {"OTIO_SCHEMA": "Stack.1", "children": []}}`,
+			wantErr: errors.ErrInputEmpty,
+		},
+		{
+			name:    "empty",
+			input:   " ",
+			wantErr: errors.ErrInputEmpty,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultFromOTIOOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			obj, err := FromOTIO([]byte(tt.input), opts)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("FromOTIO() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromOTIO() error = %v", err)
+			}
+			tt.check(t, obj)
+		})
+	}
+}
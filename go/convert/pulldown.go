@@ -0,0 +1,278 @@
+package convert
+
+import (
+	"fmt"
+
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/timecode"
+)
+
+// PulldownOptions controls how ALE timecodes are converted between video and
+// the film it holds with 2:3 pulldown.
+type PulldownOptions struct {
+	// Rate is the frame rate of the ALE's timecodes. The zero value means the
+	// ALE's FPS header field.
+	Rate timecode.Rate
+	// Columns are the timecode columns converted. Duration is worked out
+	// again from Start and End rather than converted.
+	Columns []string
+	// DropFrame counts the video timecodes written by FilmToVideo in drop
+	// frame.
+	DropFrame bool
+}
+
+// DefaultPulldownOptions returns options converting the Start and End
+// columns, with video timecodes in non-drop frame.
+func DefaultPulldownOptions() PulldownOptions {
+	return PulldownOptions{Columns: []string{"Start", "End"}}
+}
+
+// PulldownColumns are the columns the pulldown phase of a clip's Start frame
+// is read from, in order of preference: A, B, X, C or D, as in
+// keycode.PulldownPhases.
+var PulldownColumns = []string{"Pulldown", "Pullin"}
+
+// CheckPulldown checks the pulldown column of a 29.97 or 30 fps ALE: that
+// each value is a pulldown phase, and that the clips of a tape keep one
+// cadence, with A frames the same number of frames apart in every clip. A
+// problem with a row is returned as a diagnostic.
+func CheckPulldown(obj *types.Object, opts PulldownOptions) ([]RowDiagnostic, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	rate, err := pulldownVideoRate(obj, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first row of each tape with a phase sets its cadence
+	type cadence struct{ row, offset int }
+	cadences := make(map[string]cadence)
+	var diagnostics []RowDiagnostic
+	for i, row := range obj.Rows {
+		name, _ := value(row, "Name")
+		report := func(format string, args ...any) {
+			diagnostics = append(diagnostics, RowDiagnostic{Row: i, Name: name, Message: fmt.Sprintf(format, args...)})
+		}
+		pulldown, column := value(row, PulldownColumns...)
+		if pulldown == "" {
+			continue
+		}
+		phase, err := keycode.PullinPhase(pulldown)
+		if err != nil {
+			report("column %q: %v", column, err)
+			continue
+		}
+		startValue, startColumn := value(row, "Start")
+		if startValue == "" {
+			continue
+		}
+		start, err := parseTimecode(startValue, rate, i, startColumn)
+		if err != nil {
+			report("column %q: invalid timecode %q", startColumn, startValue)
+			continue
+		}
+
+		offset := pulldownCadence(start.ToFrames(rate), phase)
+		tape, _ := value(row, DefaultReelColumns...)
+		first, ok := cadences[tape]
+		if !ok {
+			cadences[tape] = cadence{row: i, offset: offset}
+			continue
+		}
+		if offset != first.offset {
+			want := keycode.PulldownPhases[pulldownCadence(start.ToFrames(rate), first.offset)]
+			report("%s %s at Start %s breaks the cadence of row %d, which makes it %c", column, pulldown, start, first.row, want)
+		}
+	}
+	return diagnostics, nil
+}
+
+// VideoToFilm converts the timecodes of a 29.97 or 30 fps ALE to the 23.976
+// or 24 fps timecodes of the film it holds with 2:3 pulldown, and sets its
+// FPS header field to the film rate. Each clip's cadence is taken from the
+// phase of its Start frame in the pulldown column, A if it has none, and
+// each A frame's film timecode is four fifths of its video frame count,
+// rounded down. With A frames on video frames 0 and 5 of each second, whole
+// seconds of video and film timecode match. Timecodes count the film frames
+// their video frames belong to, so End stays exclusive and Duration is the
+// film frames from Start to End. The pulldown column is kept as a record of
+// the transfer.
+func VideoToFilm(obj *types.Object, opts PulldownOptions) (*types.Object, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	rate, err := pulldownVideoRate(obj, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+	film, _ := rate.FilmRate()
+
+	columns := obj.ColumnNames()
+	rows := make([][]string, len(obj.Rows))
+	for i, row := range obj.Rows {
+		offset := 0
+		if pulldown, column := value(row, PulldownColumns...); pulldown != "" {
+			phase, err := keycode.PullinPhase(pulldown)
+			if err != nil {
+				return nil, errors.ErrInputInvalidPulldown.WithContext(fmt.Sprintf("row %d, column %q: %q", i, column, pulldown))
+			}
+			startValue, startColumn := value(row, "Start")
+			if startValue == "" {
+				return nil, errors.ErrInputMissingValue.WithContext(fmt.Sprintf("row %d, column %q", i, "Start"))
+			}
+			start, err := parseTimecode(startValue, rate, i, startColumn)
+			if err != nil {
+				return nil, err
+			}
+			offset = pulldownCadence(start.ToFrames(rate), phase)
+		}
+		toFilm := func(tc timecode.Timecode) timecode.Timecode {
+			return timecode.FromFrames(pulldownFilmFrame(tc.ToFrames(rate), offset), film)
+		}
+
+		if rows[i], err = convertPulldownRow(obj, row, i, columns, rate, film, toFilm, opts); err != nil {
+			return nil, err
+		}
+	}
+	return types.NewObject(withFPS(obj.HeaderFields, film), columns, rows), nil
+}
+
+// FilmToVideo converts the timecodes of a 23.976 or 24 fps ALE to the 29.97
+// or 30 fps timecodes of a 2:3 pulldown transfer of the film, and sets its
+// FPS header field to the video rate. The transfer has A frames on video
+// frame counts divisible by five, frames 0 and 5 of each second in non-drop
+// frame, and each timecode becomes the first video frame of its film frame.
+// The phase of each Start frame is written to the pulldown column, which is
+// added as Pulldown if the ALE has none.
+func FilmToVideo(obj *types.Object, opts PulldownOptions) (*types.Object, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	rate, err := frameRate(obj, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+	video, ok := rate.VideoRate()
+	if !ok {
+		return nil, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("no 2:3 pulldown of %s fps film", rate))
+	}
+	if opts.DropFrame {
+		video.DropFrame = true
+	}
+	toVideo := func(tc timecode.Timecode) timecode.Timecode {
+		frames, _ := keycode.VideoFrame(tc.ToFrames(rate), "A")
+		return timecode.FromFrames(frames, video)
+	}
+
+	columns := obj.ColumnNames()
+	pulldownIndex := -1
+	for _, name := range PulldownColumns {
+		if pulldownIndex = columnIndex(columns, name); pulldownIndex >= 0 {
+			break
+		}
+	}
+	if pulldownIndex < 0 {
+		pulldownIndex = len(columns)
+		columns = append(columns, PulldownColumns[0])
+	}
+
+	rows := make([][]string, len(obj.Rows))
+	for i, row := range obj.Rows {
+		if rows[i], err = convertPulldownRow(obj, row, i, columns, rate, video, toVideo, opts); err != nil {
+			return nil, err
+		}
+		rows[i][pulldownIndex] = ""
+		if startValue, startColumn := value(row, "Start"); startValue != "" {
+			start, err := parseTimecode(startValue, rate, i, startColumn)
+			if err != nil {
+				return nil, err
+			}
+			frames := toVideo(start).ToFrames(video)
+			rows[i][pulldownIndex] = string(keycode.PulldownPhases[pulldownCadence(frames, 0)])
+		}
+	}
+	return types.NewObject(withFPS(obj.HeaderFields, video), columns, rows), nil
+}
+
+// convertPulldownRow returns the values of a row with the timecodes of the
+// option's columns converted from rate to outRate, and Duration worked out
+// again from the converted Start and End.
+func convertPulldownRow(obj *types.Object, row types.Row, index int, columns []string, rate, outRate timecode.Rate,
+	convert func(timecode.Timecode) timecode.Timecode, opts PulldownOptions) ([]string, error) {
+	values := make([]string, len(columns))
+	copy(values, obj.Values(row))
+	timecodeColumns := opts.Columns
+	if len(timecodeColumns) == 0 {
+		timecodeColumns = DefaultPulldownOptions().Columns
+	}
+	for _, name := range timecodeColumns {
+		j := columnIndex(columns, name)
+		if j < 0 || values[j] == "" {
+			continue
+		}
+		tc, err := parseTimecode(values[j], rate, index, columns[j])
+		if err != nil {
+			return nil, err
+		}
+		values[j] = convert(tc).String()
+	}
+
+	if j := columnIndex(columns, "Duration"); j >= 0 && values[j] != "" {
+		start, end, err := sourceRange(row, rate, index)
+		if err != nil {
+			return nil, err
+		}
+		values[j] = formatDuration(convert(end).Sub(convert(start), outRate), outRate)
+	}
+	return values, nil
+}
+
+// pulldownVideoRate returns the frame rate of an ALE's clips as clipRate
+// does, which must be a rate that holds film with 2:3 pulldown.
+func pulldownVideoRate(obj *types.Object, rate timecode.Rate) (timecode.Rate, error) {
+	rate, err := clipRate(obj, rate)
+	if err != nil {
+		return timecode.Rate{}, err
+	}
+	if _, ok := rate.FilmRate(); !ok {
+		return timecode.Rate{}, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("no 2:3 pulldown at %s fps", rate))
+	}
+	return rate, nil
+}
+
+// pulldownCadence returns frames minus n, modulo 5. Given the phase of the
+// video frame at a frame count, it is the frame count modulo 5 of the
+// transfer's A frames; given that, it is the phase of the frame.
+func pulldownCadence(frames, n int) int {
+	return ((frames-n)%5 + 5) % 5
+}
+
+// pulldownFilmFrame returns the film frame count of a video frame count, for
+// a transfer with A frames on frame counts of offset modulo 5. Each A frame's
+// film frame count is four fifths of its video frame count, rounded down.
+func pulldownFilmFrame(frames, offset int) int {
+	film, _ := keycode.FilmFrame(frames-offset, "A")
+	return film + offset*4/5
+}
+
+// withFPS returns header fields with the FPS field set to a rate, counted
+// without drop frame as ALE headers are, adding the field if there is none.
+func withFPS(fields []types.Field, rate timecode.Rate) []types.Field {
+	rate.DropFrame = false
+	fps := types.FrameRate{BaseField: types.BaseField{Key: "FPS", Value: rate.String()}}
+	result := make([]types.Field, 0, len(fields)+1)
+	found := false
+	for _, field := range fields {
+		if field != nil && field.GetKey() == "FPS" {
+			field, found = fps, true
+		}
+		result = append(result, field)
+	}
+	if !found {
+		result = append(result, fps)
+	}
+	return result
+}
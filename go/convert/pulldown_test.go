@@ -0,0 +1,184 @@
+package convert
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/timecode"
+)
+
+// pulldownVideo is a 29.97 transfer of two camera rolls, each with its own cadence
+const pulldownVideo = "Heading\nFIELD_DELIM\tTABS\nVIDEO_FORMAT\tNTSC\nFPS\t29.97\n\nColumn\n" +
+	"Name\tTape\tStart\tEnd\tDuration\tPulldown\n\nData\n" +
+	"A\tTAPE1\t01:00:00:00\t01:00:10:00\t00:00:10:00\tA\n" +
+	"B\tTAPE1\t01:00:20:03\t01:00:21:00\t\tC\n" +
+	"C\tTAPE2\t01:00:00:01\t01:00:01:01\t00:00:01:00\tA\n"
+
+func TestVideoToFilm(t *testing.T) {
+	obj, err := ale.Read(pulldownVideo)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	film, err := VideoToFilm(obj, DefaultPulldownOptions())
+	if err != nil {
+		t.Fatalf("VideoToFilm() error = %v", err)
+	}
+	want := [][]string{
+		{"A", "TAPE1", "01:00:00:00", "01:00:10:00", "00:00:10:00", "A"},
+		{"B", "TAPE1", "01:00:20:02", "01:00:21:00", "", "C"},
+		{"C", "TAPE2", "01:00:00:00", "01:00:01:00", "00:00:01:00", "A"},
+	}
+	for i := range want {
+		if got := film.Values(film.Rows[i]); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Row %d = %v, want %v", i, got, want[i])
+		}
+	}
+	if film.FPS.GetValue() != "23.976" || film.VideoFormat.GetValue() != "NTSC" {
+		t.Errorf("FPS = %q, VIDEO_FORMAT = %q", film.FPS.GetValue(), film.VideoFormat.GetValue())
+	}
+	if !obj.FPS.IsPulldownOf(film.FPS) {
+		t.Errorf("%s is not a pulldown of %s", obj.FPS.GetValue(), film.FPS.GetValue())
+	}
+
+	// Clips with A frames at frame counts divisible by five come back as they were
+	video, err := FilmToVideo(film, DefaultPulldownOptions())
+	if err != nil {
+		t.Fatalf("FilmToVideo() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if got, want := video.Values(video.Rows[i]), obj.Values(obj.Rows[i]); !reflect.DeepEqual(got, want) {
+			t.Errorf("Row %d = %v, want %v", i, got, want)
+		}
+	}
+	if video.FPS.GetValue() != "29.97" {
+		t.Errorf("FPS = %q, want 29.97", video.FPS.GetValue())
+	}
+}
+
+func TestFilmToVideo(t *testing.T) {
+	input := "Heading\nFIELD_DELIM\tTABS\nFPS\t24\n\nColumn\nName\tStart\tEnd\tDuration\n\nData\n" +
+		"A\t10:00:00:00\t10:00:01:00\t00:00:01:00\n" +
+		"B\t10:00:00:01\t10:00:00:03\t\n" +
+		"C\t10:00:00:03\t\t00:00:00:05\n"
+	obj, err := ale.Read(input)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	video, err := FilmToVideo(obj, DefaultPulldownOptions())
+	if err != nil {
+		t.Fatalf("FilmToVideo() error = %v", err)
+	}
+	if got := video.ColumnNames(); !reflect.DeepEqual(got, []string{"Name", "Start", "End", "Duration", "Pulldown"}) {
+		t.Errorf("Columns = %v", got)
+	}
+	want := [][]string{
+		{"A", "10:00:00:00", "10:00:01:00", "00:00:01:00", "A"},
+		{"B", "10:00:00:01", "10:00:00:04", "", "B"},
+		{"C", "10:00:00:04", "", "00:00:00:06", "D"},
+	}
+	for i := range want {
+		if got := video.Values(video.Rows[i]); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Row %d = %v, want %v", i, got, want[i])
+		}
+	}
+	if video.FPS.GetValue() != "30" {
+		t.Errorf("FPS = %q, want 30", video.FPS.GetValue())
+	}
+
+	opts := DefaultPulldownOptions()
+	opts.DropFrame = true
+	opts.Rate = timecode.Rate23_976
+	if video, err = FilmToVideo(obj, opts); err != nil {
+		t.Fatalf("FilmToVideo() error = %v", err)
+	}
+	if got := video.Values(video.Rows[0])[1:4]; !reflect.DeepEqual(got, []string{"10:00:36;00", "10:00:37;00", "00:00:01:00"}) {
+		t.Errorf("Drop frame Start, End, Duration = %v, want drop frame timecodes and a Duration without", got)
+	}
+	if video.FPS.GetValue() != "29.97" {
+		t.Errorf("FPS = %q, want 29.97", video.FPS.GetValue())
+	}
+}
+
+func TestPulldownErrors(t *testing.T) {
+	header := "Heading\nFIELD_DELIM\tTABS\nFPS\t%s\n\nColumn\nName\tStart\tEnd\tPulldown\n\nData\n"
+	read := func(fps, data string) string { return strings.Replace(header, "%s", fps, 1) + data }
+	tests := []struct {
+		name    string
+		input   string
+		convert func(*testing.T, string) error
+		wantErr *errors.Error
+	}{
+		{"video at 25 fps", read("25", "A\t01:00:00:00\t01:00:01:00\tA\n"), videoToFilm, errors.ErrInputInvalidFrameRate},
+		{"film at 29.97 fps", read("29.97", "A\t01:00:00:00\t01:00:01:00\tA\n"), filmToVideo, errors.ErrInputInvalidFrameRate},
+		{"invalid phase", read("29.97", "A\t01:00:00:00\t01:00:01:00\tE\n"), videoToFilm, errors.ErrInputInvalidPulldown},
+		{"phase without Start", read("29.97", "A\t\t01:00:01:00\tB\n"), videoToFilm, errors.ErrInputMissingValue},
+		{"invalid timecode", read("23.976", "A\t01:00:00:24\t01:00:01:00\t\n"), filmToVideo, errors.ErrInputInvalidTimecode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.convert(t, tt.input)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := VideoToFilm(nil, DefaultPulldownOptions()); err != errors.ErrOutputNilObject {
+		t.Errorf("VideoToFilm(nil) error = %v", err)
+	}
+}
+
+func videoToFilm(t *testing.T, input string) error {
+	t.Helper()
+	obj, err := ale.Read(input)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	_, err = VideoToFilm(obj, DefaultPulldownOptions())
+	return err
+}
+
+func filmToVideo(t *testing.T, input string) error {
+	t.Helper()
+	obj, err := ale.Read(input)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	_, err = FilmToVideo(obj, DefaultPulldownOptions())
+	return err
+}
+
+func TestCheckPulldown(t *testing.T) {
+	input := pulldownVideo +
+		"D\tTAPE1\t01:00:30:00\t01:00:31:00\t\tB\n" +
+		"E\tTAPE1\t01:00:40:00\t01:00:41:00\t\tE\n" +
+		"F\tTAPE2\t01:00:10:01\t01:00:11:00\t\tA\n" +
+		"G\tTAPE2\t01:00:20:00\t01:00:21:00\t\t\n"
+	obj, err := ale.Read(input)
+	if err != nil {
+		t.Fatalf("ale.Read() error = %v", err)
+	}
+	diagnostics, err := CheckPulldown(obj, DefaultPulldownOptions())
+	if err != nil {
+		t.Fatalf("CheckPulldown() error = %v", err)
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"row 3 (D): Pulldown B at Start 01:00:30:00 breaks the cadence of row 0, which makes it A",
+		`row 4 (E): column "Pulldown": keycode: [1.0] invalid pull-in: "E"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diagnostics = %q\nwant %q", got, want)
+	}
+
+	opts := DefaultPulldownOptions()
+	opts.Rate = timecode.Rate23_976
+	if _, err := CheckPulldown(obj, opts); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidFrameRate.Error()) {
+		t.Errorf("CheckPulldown() at 23.976 error = %v, want %v", err, errors.ErrInputInvalidFrameRate)
+	}
+}
@@ -0,0 +1,289 @@
+package convert
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl/edl"
+	edltypes "lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+// DefaultClipReelColumns are the ALE columns whose values an EDL reel name is matched against.
+var DefaultClipReelColumns = []string{"Tape", "Camroll", "Reel_name", "Source File", "Name"}
+
+// PullListOptions controls how EDL events are matched to camera clips.
+type PullListOptions struct {
+	// Rate is the frame rate of the EDL and clips. The zero value means the
+	// FPS header field of the first ALE.
+	Rate timecode.Rate
+	// Handles extends each pull by this many frames at both ends, within the clip.
+	Handles int
+	// ReelColumns are the clip columns matched against EDL reel names. A
+	// Source File value is matched without its folder or extension.
+	ReelColumns []string
+	// ReelLength is the length EDL reel names were shortened to. A reel of
+	// this length also matches clip values that begin with it. The zero value
+	// means edl.DefaultReelLength.
+	ReelLength int
+}
+
+// DefaultPullListOptions returns options without handles, matching reels
+// against DefaultClipReelColumns.
+func DefaultPullListOptions() PullListOptions {
+	return PullListOptions{
+		ReelColumns: DefaultClipReelColumns,
+		ReelLength:  edl.DefaultReelLength,
+	}
+}
+
+// Diagnostic describes an EDL event that could not be matched to a clip.
+type Diagnostic struct {
+	Event   int
+	Line    int
+	Reel    string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("event %03d (line %d, reel %s): %s", d.Event, d.Line, d.Reel, d.Message)
+}
+
+// pullClip is a camera clip that events may be matched to.
+type pullClip struct {
+	obj   *types.Object
+	row   types.Row
+	name  string
+	keys  []string
+	start timecode.Timecode
+	end   timecode.Timecode
+
+	// Set as events are matched to the clip. sourceFile is taken from the
+	// EDL if the clip has none.
+	pullStart  timecode.Timecode
+	pullEnd    timecode.Timecode
+	events     []string
+	sourceFile string
+}
+
+// PullList matches the events of an EDL to the clips of camera ALEs by reel
+// name and source timecode, and returns one row per clip used. Each row is
+// the clip's row with Start, End and Duration narrowed to the frames used by
+// all its events plus handles, Source File from the clip or the EDL's source
+// file comment, and an Events column listing the event numbers. Events that
+// match no clip, or more than one, are returned as diagnostics.
+func PullList(list *edltypes.Object, clips []*types.Object, opts PullListOptions) (*types.Object, []Diagnostic, error) {
+	if list == nil || len(clips) == 0 {
+		return nil, nil, errors.ErrOutputNilObject
+	}
+	rate, err := frameRate(clips[0], opts.Rate)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rate, err = edlRate(list, rate); err != nil {
+		return nil, nil, err
+	}
+	reelColumns := opts.ReelColumns
+	if len(reelColumns) == 0 {
+		reelColumns = DefaultClipReelColumns
+	}
+	reelLength := opts.ReelLength
+	if reelLength <= 0 {
+		reelLength = edl.DefaultReelLength
+	}
+
+	candidates, err := pullClips(clips, reelColumns, rate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var diagnostics []Diagnostic
+	var used []*pullClip
+	for _, r := range eventRanges(list, rate, false) {
+		event := r.event
+		clip, message := matchClip(r, candidates, rate, reelLength)
+		if clip == nil {
+			diagnostics = append(diagnostics, Diagnostic{Event: event.Number, Line: event.Line, Reel: event.Reel, Message: message})
+			continue
+		}
+
+		if len(clip.events) == 0 {
+			clip.pullStart, clip.pullEnd = r.start, r.end
+			used = append(used, clip)
+		}
+		if r.start.Sub(clip.pullStart, rate) < 0 {
+			clip.pullStart = r.start
+		}
+		if r.end.Sub(clip.pullEnd, rate) > 0 {
+			clip.pullEnd = r.end
+		}
+		if number := strconv.Itoa(event.Number); !containsString(clip.events, number) {
+			clip.events = append(clip.events, number)
+		}
+		if clip.sourceFile == "" {
+			clip.sourceFile = event.SourceFile
+		}
+	}
+
+	return pullListObject(clips, used, opts.Handles, rate), diagnostics, nil
+}
+
+// pullClips reads the reel keys and timecode range of every clip.
+func pullClips(clips []*types.Object, reelColumns []string, rate timecode.Rate) ([]*pullClip, error) {
+	var candidates []*pullClip
+	seen := make(map[string]bool)
+	for _, obj := range clips {
+		for i, row := range obj.Rows {
+			name, _ := value(row, "Name")
+			start, end, err := sourceRange(row, rate, i)
+			if err != nil {
+				return nil, err
+			}
+			// The same clip may be logged in more than one ALE
+			id := name + "\x00" + start.String()
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			sourceFile, _ := value(row, "Source File")
+			clip := &pullClip{obj: obj, row: row, name: name, start: start, end: end, sourceFile: sourceFile}
+			for _, column := range reelColumns {
+				if key, _ := value(row, column); key != "" {
+					clip.keys = append(clip.keys, reelKey(column, key))
+				}
+			}
+			candidates = append(candidates, clip)
+		}
+	}
+	return candidates, nil
+}
+
+// matchClip returns the one clip whose reel names the event and whose
+// timecode range holds the event's source range, or else a message saying
+// why there is none.
+func matchClip(r eventRange, candidates []*pullClip, rate timecode.Rate, reelLength int) (*pullClip, string) {
+	keys := eventKeys(r.event)
+	var reelMatches, matches []*pullClip
+	for _, clip := range candidates {
+		if !matchesReel(keys, clip.keys, reelLength) {
+			continue
+		}
+		reelMatches = append(reelMatches, clip)
+		if clip.start.Sub(r.start, rate) <= 0 && r.end.Sub(clip.end, rate) <= 0 {
+			matches = append(matches, clip)
+		}
+	}
+	switch {
+	case len(reelMatches) == 0:
+		return nil, "no clip with this reel"
+	case len(matches) == 0:
+		return nil, fmt.Sprintf("source %s-%s is outside clips %s", r.start, r.end, clipNames(reelMatches))
+	case len(matches) > 1:
+		return nil, fmt.Sprintf("source %s-%s matches clips %s", r.start, r.end, clipNames(matches))
+	}
+	return matches[0], ""
+}
+
+// eventKeys returns the names an event may be matched by: its reel, source
+// file and clip name.
+func eventKeys(event edltypes.Event) []string {
+	keys := []string{event.Reel}
+	if event.SourceFile != "" {
+		keys = append(keys, reelKey("Source File", event.SourceFile))
+	}
+	if event.ClipName != "" {
+		keys = append(keys, event.ClipName)
+	}
+	return keys
+}
+
+// reelKey returns the value of a reel column as matched against EDL reels.
+func reelKey(column, value string) string {
+	if strings.EqualFold(column, "Source File") {
+		value = path.Base(strings.ReplaceAll(value, `\`, "/"))
+		value = strings.TrimSuffix(value, path.Ext(value))
+	}
+	return value
+}
+
+// matchesReel reports whether any event key names the clip, either exactly
+// or as a reel name shortened to reelLength.
+func matchesReel(eventKeys, clipKeys []string, reelLength int) bool {
+	for _, eventKey := range eventKeys {
+		for _, clipKey := range clipKeys {
+			if strings.EqualFold(eventKey, clipKey) {
+				return true
+			}
+			if len(eventKey) == reelLength && len(clipKey) > reelLength && strings.EqualFold(clipKey[:reelLength], eventKey) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pullListObject builds the pull list ALE. Its columns are those of all the
+// clip ALEs in the order first seen, with Source File and Events added.
+func pullListObject(clips []*types.Object, used []*pullClip, handles int, rate timecode.Rate) *types.Object {
+	var columns []string
+	index := make(map[string]int)
+	addColumn := func(name string) {
+		if _, ok := index[name]; !ok {
+			index[name] = len(columns)
+			columns = append(columns, name)
+		}
+	}
+	for _, obj := range clips {
+		for _, name := range obj.ColumnNames() {
+			addColumn(name)
+		}
+	}
+	for _, name := range []string{"Name", "Source File", "Start", "End", "Duration", "Events"} {
+		addColumn(name)
+	}
+
+	rows := make([][]string, 0, len(used))
+	for _, clip := range used {
+		// Handles stop at the ends of the clip rather than wrapping past midnight
+		startFrame := max(clip.pullStart.ToFrames(rate)-handles, clip.start.ToFrames(rate))
+		endFrame := min(clip.pullEnd.ToFrames(rate)+handles, clip.end.ToFrames(rate))
+		start, end := timecode.FromFrames(startFrame, rate), timecode.FromFrames(endFrame, rate)
+
+		row := make([]string, len(columns))
+		names := clip.obj.ColumnNames()
+		for i, v := range clip.obj.Values(clip.row) {
+			row[index[names[i]]] = v
+		}
+		row[index["Source File"]] = clip.sourceFile
+		row[index["Start"]] = start.String()
+		row[index["End"]] = end.String()
+		row[index["Duration"]] = formatDuration(endFrame-startFrame, rate)
+		row[index["Events"]] = strings.Join(clip.events, ",")
+		rows = append(rows, row)
+	}
+	return types.NewObject(clips[0].HeaderFields, columns, rows)
+}
+
+// clipNames lists the names of clips for a diagnostic.
+func clipNames(clips []*pullClip) string {
+	names := make([]string, len(clips))
+	for i, clip := range clips {
+		names[i] = clip.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// containsString reports whether a slice contains a string.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package convert
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl/edl"
+)
+
+func TestPullListSample(t *testing.T) {
+	list, err := edl.ReadFile("../../samples/EDL/A001R1AA_CUT.edl")
+	if err != nil {
+		t.Fatalf("Failed to read sample EDL: %v", err)
+	}
+	clips, err := ale.ReadFile("../../samples/ALE/A001R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample ALE: %v", err)
+	}
+
+	opts := DefaultPullListOptions()
+	opts.Handles = 12
+	obj, diagnostics, err := PullList(list, []*types.Object{clips}, opts)
+	if err != nil {
+		t.Fatalf("PullList() error = %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("Unexpected diagnostics: %v", diagnostics)
+	}
+
+	// Both clips share the reel A001R1AA and are told apart by timecode. The
+	// second clip's two events are merged into one pull.
+	want := [][]string{
+		{"A001C001_240426_R1AA", "A001C001_240426_R1AA.mxf", "03:44:39:13", "03:44:46:12", "00:00:06:24", "1,2"},
+		{"A001C002_240426_R1AA", "A001C002_240426_R1AA.mxf", "03:45:49:13", "03:46:01:01", "00:00:11:13", "2,3"},
+	}
+	if len(obj.Rows) != len(want) {
+		t.Fatalf("Got %d rows, want %d", len(obj.Rows), len(want))
+	}
+	for i, row := range obj.Rows {
+		var got []string
+		for _, column := range []string{"Name", "Source File", "Start", "End", "Duration", "Events"} {
+			v, _ := row.Value(column)
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Row %d = %q, want %q", i, got, want[i])
+		}
+	}
+	if v, _ := obj.Rows[0].Value("Camera_model"); v != "ALEXA Mini" {
+		t.Errorf("Camera_model = %q, want clip columns carried over", v)
+	}
+
+	// The result is a valid ALE
+	data, err := ale.Write(obj)
+	if err != nil {
+		t.Fatalf("ale.Write() error = %v", err)
+	}
+	if _, err := ale.Read(data); err != nil {
+		t.Errorf("Output does not parse: %v", err)
+	}
+}
+
+func TestPullList(t *testing.T) {
+	clips, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t25\n\nColumn\n" +
+		"Name\tTape\tStart\tEnd\n\nData\n" +
+		"B001C001\tB001R2CD_LONGREEL\t10:00:00:00\t10:00:10:00\n" +
+		"C001C001\tSAMEREEL\t11:00:00:00\t11:00:10:00\n" +
+		"C001C002\tSAMEREEL\t11:00:05:00\t11:00:20:00\n")
+	if err != nil {
+		t.Fatalf("Failed to read ALE: %v", err)
+	}
+	list, err := edl.Read("TITLE: TEST\nFCM: NON-DROP FRAME\n\n" +
+		"001  B001R2CD V     C        10:00:08:00 10:00:09:00 01:00:00:00 01:00:01:00\n" +
+		"* SOURCE FILE: /media/B001C001.mov\n" +
+		"002  B001R2CD V     C        10:00:09:00 10:00:11:00 01:00:01:00 01:00:03:00\n" +
+		"003  UNKNOWN  V     C        10:00:00:00 10:00:01:00 01:00:03:00 01:00:04:00\n" +
+		"004  SAMEREEL V     C        11:00:06:00 11:00:07:00 01:00:04:00 01:00:05:00\n" +
+		"005  SAMEREEL V     C        11:00:12:00 11:00:13:00 01:00:05:00 01:00:06:00\n")
+	if err != nil {
+		t.Fatalf("Failed to read EDL: %v", err)
+	}
+
+	opts := DefaultPullListOptions()
+	opts.Handles = 50
+	obj, diagnostics, err := PullList(list, []*types.Object{clips, clips}, opts)
+	if err != nil {
+		t.Fatalf("PullList() error = %v", err)
+	}
+
+	// The truncated reel matches by prefix, handles stop at the clip's ends,
+	// the Source File comment fills in the missing column, and the clip
+	// logged twice is only matched once
+	want := [][]string{
+		{"B001C001", "/media/B001C001.mov", "10:00:06:00", "10:00:10:00", "1"},
+		{"C001C002", "", "11:00:10:00", "11:00:15:00", "5"},
+	}
+	if len(obj.Rows) != len(want) {
+		t.Fatalf("Got %d rows, want %d", len(obj.Rows), len(want))
+	}
+	for i, row := range obj.Rows {
+		var got []string
+		for _, column := range []string{"Name", "Source File", "Start", "End", "Events"} {
+			v, _ := row.Value(column)
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Row %d = %q, want %q", i, got, want[i])
+		}
+	}
+
+	wantDiagnostics := []string{
+		"event 002 (line 6, reel B001R2CD): source 10:00:09:00-10:00:11:00 is outside clips B001C001",
+		"event 003 (line 7, reel UNKNOWN): no clip with this reel",
+		"event 004 (line 8, reel SAMEREEL): source 11:00:06:00-11:00:07:00 matches clips C001C001, C001C002",
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(wantDiagnostics, "\n") {
+		t.Errorf("Diagnostics =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantDiagnostics, "\n"))
+	}
+
+	if _, _, err := PullList(list, nil, opts); err == nil {
+		t.Error("Expected error without clips")
+	}
+}
+
+func TestPullListMidnightDropFrame(t *testing.T) {
+	clips, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t29.97\n\nColumn\n" +
+		"Name\tTape\tStart\tEnd\n\nData\n" +
+		"M001C001\tM001\t00:00:00;00\t00:20:00;00\n")
+	if err != nil {
+		t.Fatalf("Failed to read ALE: %v", err)
+	}
+	list, err := edl.Read("TITLE: TEST\nFCM: DROP FRAME\n\n" +
+		"001  M001     V     C        00:00:00;00 00:10:00;00 01:00:00;00 01:10:00;00\n")
+	if err != nil {
+		t.Fatalf("Failed to read EDL: %v", err)
+	}
+
+	opts := DefaultPullListOptions()
+	opts.Handles = 10
+	obj, _, err := PullList(list, []*types.Object{clips}, opts)
+	if err != nil {
+		t.Fatalf("PullList() error = %v", err)
+	}
+	if len(obj.Rows) != 1 {
+		t.Fatalf("Got %d rows, want 1", len(obj.Rows))
+	}
+
+	// Handles stop at the clip's start at midnight, and Duration is a length
+	// without drop frame
+	var got []string
+	for _, column := range []string{"Start", "End", "Duration"} {
+		v, _ := obj.Rows[0].Value(column)
+		got = append(got, v)
+	}
+	if want := []string{"00:00:00;00", "00:10:00;10", "00:09:59:22"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Start, End, Duration = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package convert
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+	"lib-post-interchange/libedl/edl"
+	edltypes "lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+// DefaultReelColumns are the ALE columns a reel name is taken from, in order of preference.
+var DefaultReelColumns = []string{"Tape", "Camroll", "Source File"}
+
+// StringoutOptions controls how ALE clips are laid out as an EDL.
+type StringoutOptions struct {
+	// Title is written on the EDL's TITLE line.
+	Title string
+	// RecordStart is the record timecode of the first event.
+	RecordStart timecode.Timecode
+	// Rate is the frame rate of the clips. The zero value means the ALE's FPS header field.
+	Rate timecode.Rate
+	// ReelColumns are the columns the reel name is taken from, in order of
+	// preference. A Source File value is used without its folder or extension.
+	ReelColumns []string
+	// Track is the track type of every event.
+	Track string
+}
+
+// DefaultStringoutOptions returns video events recorded from 01:00:00:00,
+// with reels from DefaultReelColumns.
+func DefaultStringoutOptions() StringoutOptions {
+	return StringoutOptions{
+		RecordStart: timecode.Timecode{Hours: 1},
+		ReelColumns: DefaultReelColumns,
+		Track:       "V",
+	}
+}
+
+// Stringout lays the clips of an ALE end to end as the events of an EDL, one
+// cut event per row. Start and End are the source timecodes; a row without
+// End uses Duration. Name and Source File are carried as clip name and source
+// file comments, and ASC_SOP and ASC_SAT values as ASC CDL comments.
+func Stringout(obj *types.Object, opts StringoutOptions) (*edltypes.Object, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	rate, err := clipRate(obj, opts.Rate)
+	if err != nil {
+		return nil, err
+	}
+	reelColumns := opts.ReelColumns
+	if len(reelColumns) == 0 {
+		reelColumns = DefaultReelColumns
+	}
+	track := opts.Track
+	if track == "" {
+		track = "V"
+	}
+
+	list := &edltypes.Object{
+		Title:  opts.Title,
+		FCM:    edltypes.FCMFor(rate),
+		Events: make([]edltypes.Event, 0, len(obj.Rows)),
+	}
+	record := opts.RecordStart
+	for i, row := range obj.Rows {
+		reel, reelErr := reelName(obj, row, reelColumns)
+		if reelErr != nil {
+			return nil, reelErr.WithContext(fmt.Sprintf("row %d", i))
+		}
+		sourceIn, sourceOut, err := sourceRange(row, rate, i)
+		if err != nil {
+			return nil, err
+		}
+		duration := sourceOut.Sub(sourceIn, rate)
+		event := edltypes.Event{
+			Number:    i + 1,
+			Reel:      reel,
+			Track:     track,
+			SourceIn:  sourceIn,
+			SourceOut: sourceOut,
+			RecordIn:  record,
+			RecordOut: record.Add(duration, rate),
+		}
+		event.ClipName, _ = value(row, "Name")
+		event.SourceFile, _ = value(row, "Source File")
+		if err := readASCColumns(row, &event, i); err != nil {
+			return nil, err
+		}
+		list.Events = append(list.Events, event)
+		record = event.RecordOut
+	}
+	return list, nil
+}
+
+// reelName returns the reel of a row from the first reel column with a value,
+// falling back to the ALE's TAPE header field.
+func reelName(obj *types.Object, row types.Row, columns []string) (string, *errors.Error) {
+	reel, column := value(row, columns...)
+	if reel == "" {
+		reel = strings.TrimSpace(obj.Tape.GetValue())
+	}
+	if reel == "" {
+		return "", errors.ErrInputMissingValue.WithContext(fmt.Sprintf("no reel in %s", strings.Join(columns, ", ")))
+	}
+	if strings.EqualFold(column, "Source File") {
+		reel = path.Base(strings.ReplaceAll(reel, `\`, "/"))
+		reel = strings.TrimSuffix(reel, path.Ext(reel))
+	}
+	return reel, nil
+}
+
+// sourceRange returns the Start and End timecodes of a row, computing End
+// from a non-drop-frame Duration if it is missing.
+func sourceRange(row types.Row, rate timecode.Rate, index int) (timecode.Timecode, timecode.Timecode, error) {
+	startValue, startColumn := value(row, "Start")
+	if startValue == "" {
+		return timecode.Timecode{}, timecode.Timecode{}, errors.ErrInputMissingValue.WithContext(fmt.Sprintf("row %d, column %q", index, "Start"))
+	}
+	start, err := parseTimecode(startValue, rate, index, startColumn)
+	if err != nil {
+		return timecode.Timecode{}, timecode.Timecode{}, err
+	}
+
+	var end timecode.Timecode
+	if endValue, endColumn := value(row, "End"); endValue != "" {
+		if end, err = parseTimecode(endValue, rate, index, endColumn); err != nil {
+			return timecode.Timecode{}, timecode.Timecode{}, err
+		}
+	} else if durationValue, durationColumn := value(row, "Duration"); durationValue != "" {
+		// A duration is a length, counted without drop frame as formatDuration writes it
+		lengthRate := rate
+		lengthRate.DropFrame = false
+		duration, err := parseTimecode(durationValue, lengthRate, index, durationColumn)
+		if err != nil {
+			return timecode.Timecode{}, timecode.Timecode{}, err
+		}
+		end = start.Add(duration.ToFrames(lengthRate), rate)
+	} else {
+		return timecode.Timecode{}, timecode.Timecode{}, errors.ErrInputMissingValue.WithContext(fmt.Sprintf("row %d: no End or Duration", index))
+	}
+
+	if end.Sub(start, rate) <= 0 {
+		return timecode.Timecode{}, timecode.Timecode{}, errors.ErrInputInvalidTimecode.WithContext(fmt.Sprintf("row %d: End %s is not after Start %s", index, end, start))
+	}
+	return start, end, nil
+}
+
+// readASCColumns sets an event's ASC CDL values from the ASC_SOP and ASC_SAT columns of a row.
+func readASCColumns(row types.Row, event *edltypes.Event, index int) error {
+	if v, column := value(row, "ASC_SOP"); v != "" {
+		sop, err := edl.ParseSOP(v)
+		if err != nil {
+			return errors.ErrInputInvalidCDL.WithContext(fmt.Sprintf("row %d, column %q: %v", index, column, err))
+		}
+		event.ASCSOP = &sop
+	}
+	if v, column := value(row, "ASC_SAT"); v != "" {
+		sat, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.ErrInputInvalidCDL.WithContext(fmt.Sprintf("row %d, column %q: %q is not a number", index, column, v))
+		}
+		event.ASCSAT = &sat
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libedl/edl"
+	edltypes "lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+func TestStringoutSample(t *testing.T) {
+	obj, err := ale.ReadFile("../../samples/ALE/A901R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	list, err := Stringout(obj, DefaultStringoutOptions())
+	if err != nil {
+		t.Fatalf("Stringout() error = %v", err)
+	}
+	if len(list.Events) != len(obj.Rows) {
+		t.Fatalf("Got %d events, want %d", len(list.Events), len(obj.Rows))
+	}
+
+	first := list.Events[0]
+	if first.Reel != "A901C001_240426_R1AA" || first.ClipName != "A901C001_240426_R1AA" {
+		t.Errorf("Reel = %q, ClipName = %q", first.Reel, first.ClipName)
+	}
+	if first.RecordIn.String() != "01:00:00:00" {
+		t.Errorf("RecordIn = %s, want 01:00:00:00", first.RecordIn)
+	}
+	if first.ASCSOP == nil || first.ASCSAT == nil {
+		t.Error("Expected ASC CDL values")
+	}
+	for i := 1; i < len(list.Events); i++ {
+		if list.Events[i].RecordIn != list.Events[i-1].RecordOut {
+			t.Errorf("Event %d does not follow event %d", i+1, i)
+		}
+	}
+
+	// The reels are shortened to CMX3600's limit when written
+	data, err := edl.Write(list)
+	if err != nil {
+		t.Fatalf("edl.Write() error = %v", err)
+	}
+	if !strings.Contains(data, "001  A901C001 V     C        ") || !strings.Contains(data, "*ASC_SOP (1.000000 1.000000 1.000000)") {
+		t.Errorf("Unexpected EDL:\n%s", data)
+	}
+}
+
+func TestStringout(t *testing.T) {
+	tests := []struct {
+		name    string
+		fps     string
+		ale     string
+		opts    func(*StringoutOptions)
+		wantErr *errors.Error
+		check   func(*testing.T, *edltypes.Object)
+	}{
+		{
+			name: "reel preference and duration",
+			ale: "Name\tTape\tCamroll\tStart\tEnd\tDuration\n" +
+				"A\tTAPE1\tROLL1\t10:00:00:00\t10:00:02:00\t\n" +
+				"B\t\tROLL2\t11:00:00:00\t\t00:00:01:12\n",
+			check: func(t *testing.T, list *edltypes.Object) {
+				if list.Events[0].Reel != "TAPE1" || list.Events[1].Reel != "ROLL2" {
+					t.Errorf("Reels = %q, %q", list.Events[0].Reel, list.Events[1].Reel)
+				}
+				if got := list.Events[1].SourceOut.String(); got != "11:00:01:12" {
+					t.Errorf("SourceOut = %s, want 11:00:01:12", got)
+				}
+				if got := list.Events[1].RecordOut.String(); got != "10:00:03:12" {
+					t.Errorf("RecordOut = %s, want 10:00:03:12", got)
+				}
+			},
+			opts: func(opts *StringoutOptions) { opts.RecordStart = timecode.MustParse("10:00:00:00") },
+		},
+		{
+			name: "drop frame",
+			fps:  "29.97",
+			ale:  "Name\tTape\tStart\tEnd\nA\tTAPE1\t10:00:59;28\t10:01:00;04\n",
+			check: func(t *testing.T, list *edltypes.Object) {
+				if list.FCM != edltypes.DropFrame {
+					t.Errorf("FCM = %q, want DROP FRAME", list.FCM)
+				}
+				if got := list.Events[0].RecordOut.String(); got != "01:00:00;04" {
+					t.Errorf("RecordOut = %s, want 01:00:00;04", got)
+				}
+			},
+		},
+		{
+			name: "drop frame with non-drop-frame durations",
+			fps:  "29.97",
+			ale: "Name\tTape\tStart\tDuration\n" +
+				"A\tTAPE1\t10:00:00;00\t00:01:00:00\n" +
+				"B\tTAPE1\t10:00:00;00\t00:09:59:12\n",
+			check: func(t *testing.T, list *edltypes.Object) {
+				if got := list.Events[0].SourceOut.String(); got != "10:01:00;02" {
+					t.Errorf("SourceOut = %s, want 10:01:00;02", got)
+				}
+				if got := list.Events[1].SourceOut.String(); got != "10:10:00;00" {
+					t.Errorf("SourceOut = %s, want 10:10:00;00", got)
+				}
+			},
+		},
+		{
+			name:    "missing reel",
+			ale:     "Name\tStart\tEnd\nA\t10:00:00:00\t10:00:02:00\n",
+			wantErr: errors.ErrInputMissingValue,
+		},
+		{
+			name:    "missing end",
+			ale:     "Name\tTape\tStart\nA\tTAPE1\t10:00:00:00\n",
+			wantErr: errors.ErrInputMissingValue,
+		},
+		{
+			name:    "end before start",
+			ale:     "Name\tTape\tStart\tEnd\nA\tTAPE1\t10:00:02:00\t10:00:00:00\n",
+			wantErr: errors.ErrInputInvalidTimecode,
+		},
+		{
+			name:    "invalid timecode for rate",
+			ale:     "Name\tTape\tStart\tEnd\nA\tTAPE1\t10:00:00:29\t10:00:02:00\n",
+			wantErr: errors.ErrInputInvalidTimecode,
+		},
+		{
+			name:    "invalid ASC_SOP",
+			ale:     "Name\tTape\tStart\tEnd\tASC_SOP\nA\tTAPE1\t10:00:00:00\t10:00:02:00\t(1 1 1)\n",
+			wantErr: errors.ErrInputInvalidCDL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fps := tt.fps
+			if fps == "" {
+				fps = "25"
+			}
+			obj, err := ale.Read("Heading\nFIELD_DELIM\tTABS\nFPS\t" + fps + "\n\nColumn\n" +
+				strings.Replace(tt.ale, "\n", "\n\nData\n", 1))
+			if err != nil {
+				t.Fatalf("Failed to read ALE: %v", err)
+			}
+			opts := DefaultStringoutOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			list, err := Stringout(obj, opts)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("Stringout() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stringout() error = %v", err)
+			}
+			tt.check(t, list)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package errors
+
+import "fmt"
+
+// ErrorCategory represents the main category of an error
+type ErrorCategory int32
+
+// Error categories
+const (
+	CategoryInput ErrorCategory = iota + 1
+	CategoryOutput
+)
+
+// Error represents a keycode error.
+type Error struct {
+	Category    ErrorCategory
+	SubCategory int32
+	Message     string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("keycode: [%d.%d] %s", e.Category, e.SubCategory, e.Message)
+}
+
+// Code returns the unique error code
+func (e *Error) Code() int32 {
+	return int32(e.Category)*1000 + e.SubCategory
+}
+
+// WithContext returns a new Error with additional context appended to the message
+func (e *Error) WithContext(context string) *Error {
+	return &Error{
+		Category:    e.Category,
+		SubCategory: e.SubCategory,
+		Message:     e.Message + ": " + context,
+	}
+}
+
+// Error definitions for keycodes, feet+frames, film formats and pulldown phases
+var (
+	// Input errors
+	ErrInputInvalidKeycode = &Error{
+		Category: CategoryInput,
+		Message:  "invalid keycode",
+	}
+	ErrInputInvalidFeetFrames = &Error{
+		Category: CategoryInput,
+		Message:  "invalid feet+frames",
+	}
+	ErrInputInvalidFormat = &Error{
+		Category: CategoryInput,
+		Message:  "invalid film format",
+	}
+	ErrInputDifferentRolls = &Error{
+		Category: CategoryInput,
+		Message:  "keycodes from different rolls",
+	}
+	ErrInputInvalidPullin = &Error{
+		Category: CategoryInput,
+		Message:  "invalid pull-in",
+	}
+)
+
+// IsCategory checks if an error belongs to a specific category
+func IsCategory(err error, category ErrorCategory) bool {
+	if keycodeErr, ok := err.(*Error); ok {
+		return keycodeErr.Code()/1000 == int32(category)
+	}
+	return false
+}
+
+// IsError checks if an error matches a specific category and subcategory
+func IsError(err error, category ErrorCategory, subCategory int32) bool {
+	if keycodeErr, ok := err.(*Error); ok {
+		code := keycodeErr.Code()
+		return code/1000 == int32(category) && code%1000 == subCategory
+	}
+	return false
+}
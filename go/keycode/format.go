@@ -0,0 +1,160 @@
+package keycode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"lib-post-interchange/keycode/errors"
+)
+
+// Format is a film gauge and perforation layout. Positions on the film are
+// counted in perforations, so layouts whose frames do not divide a foot,
+// such as 35 mm 3-perf, are exact.
+type Format struct {
+	// Gauge is the film width in millimetres.
+	Gauge int
+	// Perfs is the number of perforations per frame.
+	Perfs int
+	// FootPerfs is the number of perforations in a foot of film.
+	FootPerfs int
+	// KeyPerfs is the number of perforations from one key number to the next.
+	KeyPerfs int
+}
+
+// Film formats. Key numbers are printed every foot on 35 mm, every 20
+// frames on 16 mm and every 120 perforations on 65 mm.
+var (
+	Format16mm      = Format{Gauge: 16, Perfs: 1, FootPerfs: 40, KeyPerfs: 20}
+	Format35mm      = Format{Gauge: 35, Perfs: 4, FootPerfs: 64, KeyPerfs: 64}
+	Format35mm2Perf = Format{Gauge: 35, Perfs: 2, FootPerfs: 64, KeyPerfs: 64}
+	Format35mm3Perf = Format{Gauge: 35, Perfs: 3, FootPerfs: 64, KeyPerfs: 64}
+	// Format35mm8Perf is the horizontal VistaVision layout.
+	Format35mm8Perf = Format{Gauge: 35, Perfs: 8, FootPerfs: 64, KeyPerfs: 64}
+	Format65mm      = Format{Gauge: 65, Perfs: 5, FootPerfs: 64, KeyPerfs: 120}
+)
+
+// formats lists the known formats, the usual layout of each gauge first.
+var formats = []Format{Format16mm, Format35mm, Format35mm3Perf, Format35mm2Perf, Format35mm8Perf, Format65mm}
+
+// FormatFor returns the format of a gauge with a number of perforations per
+// frame. Zero perforations means the usual layout of the gauge.
+func FormatFor(gauge, perfs int) (Format, error) {
+	for _, f := range formats {
+		if f.Gauge == gauge && (perfs == 0 || f.Perfs == perfs) {
+			return f, nil
+		}
+	}
+	if perfs == 0 {
+		return Format{}, errors.ErrInputInvalidFormat.WithContext(fmt.Sprintf("%d mm", gauge))
+	}
+	return Format{}, errors.ErrInputInvalidFormat.WithContext(fmt.Sprintf("%d mm, %d perf", gauge, perfs))
+}
+
+// ParseFormat parses a FILM_FORMAT value: "35 mm", "16mm" or "35 mm, 3 perf".
+func ParseFormat(s string) (Format, error) {
+	numbers := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	rest := strings.ToLower(strings.Join(strings.FieldsFunc(s, unicode.IsDigit), " "))
+	if len(numbers) == 0 || len(numbers) > 2 || strings.Trim(rest, " ,-mperf") != "" {
+		return Format{}, errors.ErrInputInvalidFormat.WithContext(fmt.Sprintf("%q", s))
+	}
+	gauge, _ := strconv.Atoi(numbers[0])
+	perfs := 0
+	if len(numbers) == 2 {
+		perfs, _ = strconv.Atoi(numbers[1])
+	}
+	f, err := FormatFor(gauge, perfs)
+	if err != nil {
+		return Format{}, errors.ErrInputInvalidFormat.WithContext(fmt.Sprintf("%q", s))
+	}
+	return f, nil
+}
+
+// String formats the format as a FILM_FORMAT value, such as "35 mm", with
+// the perforations added when they are not the usual number for the gauge,
+// as in "35 mm, 3 perf".
+func (f Format) String() string {
+	if f.IsZero() {
+		return ""
+	}
+	s := fmt.Sprintf("%d mm", f.Gauge)
+	if usual, err := FormatFor(f.Gauge, 0); err != nil || usual.Perfs != f.Perfs {
+		s += fmt.Sprintf(", %d perf", f.Perfs)
+	}
+	return s
+}
+
+// IsZero reports whether the format is unset.
+func (f Format) IsZero() bool {
+	return f.Perfs == 0 || f.FootPerfs == 0 || f.KeyPerfs == 0
+}
+
+// FeetFrames is a film length or position in feet and frames, written as
+// "123+08".
+type FeetFrames struct {
+	Feet   int
+	Frames int
+}
+
+// ParseFeetFrames parses feet+frames written as "123+08".
+func ParseFeetFrames(s string) (FeetFrames, error) {
+	feet, frames, ok := strings.Cut(strings.TrimSpace(s), "+")
+	if !ok || !isDigits(feet) || !isDigits(frames) {
+		return FeetFrames{}, errors.ErrInputInvalidFeetFrames.WithContext(fmt.Sprintf("%q", s))
+	}
+	var ff FeetFrames
+	ff.Feet, _ = strconv.Atoi(feet)
+	ff.Frames, _ = strconv.Atoi(frames)
+	return ff, nil
+}
+
+// String formats feet+frames as "123+08".
+func (ff FeetFrames) String() string {
+	return fmt.Sprintf("%d+%02d", ff.Feet, ff.Frames)
+}
+
+// Validate checks that the frames fit in the foot. Where frames do not
+// divide a foot, as on 35 mm 3-perf, feet hold different numbers of frames;
+// a frame belongs to the foot in which it starts.
+func (ff FeetFrames) Validate(f Format) error {
+	if ff.Feet < 0 || ff.Frames < 0 || ff.Frames >= footFrame(ff.Feet+1, f)-footFrame(ff.Feet, f) {
+		return errors.ErrInputInvalidFeetFrames.WithContext(fmt.Sprintf("%s at %s", ff, f))
+	}
+	return nil
+}
+
+// ToFrames returns the number of frames from 0+00 in the format.
+func (ff FeetFrames) ToFrames(f Format) int {
+	return footFrame(ff.Feet, f) + ff.Frames
+}
+
+// FeetFramesFromFrames returns the feet+frames of a frame count from 0+00
+// in the format.
+func FeetFramesFromFrames(frames int, f Format) FeetFrames {
+	feet := floorDiv(frames*f.Perfs, f.FootPerfs)
+	return FeetFrames{Feet: feet, Frames: frames - footFrame(feet, f)}
+}
+
+// footFrame returns the first frame that starts in a foot.
+func footFrame(feet int, f Format) int {
+	return -floorDiv(-feet*f.FootPerfs, f.Perfs)
+}
+
+// floorDiv divides rounding towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,98 @@
+package keycode
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/keycode/errors"
+	"lib-post-interchange/libale/format"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: format.Film16mm.GetValue(), want: Format16mm},
+		{input: format.Film35mm.GetValue(), want: Format35mm},
+		{input: format.Film65mm.GetValue(), want: Format65mm},
+		{input: "35mm", want: Format35mm},
+		{input: "35 mm, 3 perf", want: Format35mm3Perf},
+		{input: "35mm 2-perf", want: Format35mm2Perf},
+		{input: "35 mm, 4 perf", want: Format35mm},
+		{input: "16 mm, 2 perf", wantErr: true},
+		{input: "70 mm", wantErr: true},
+		{input: "Super 35", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err != nil && (err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidFormat.Error())) {
+			t.Errorf("ParseFormat(%q) error = %v, want ErrInputInvalidFormat", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatString(t *testing.T) {
+	tests := map[Format]string{
+		Format16mm:      format.Film16mm.GetValue(),
+		Format35mm:      format.Film35mm.GetValue(),
+		Format65mm:      format.Film65mm.GetValue(),
+		Format35mm3Perf: "35 mm, 3 perf",
+		{}:              "",
+	}
+	for f, want := range tests {
+		if got := f.String(); got != want {
+			t.Errorf("%+v.String() = %q, want %q", f, got, want)
+		}
+	}
+}
+
+func TestFeetFrames(t *testing.T) {
+	tests := []struct {
+		frames int
+		format Format
+		want   string
+	}{
+		{0, Format35mm, "0+00"},
+		{16, Format35mm, "1+00"},
+		{1975, Format35mm, "123+07"},
+		{40, Format16mm, "1+00"},
+		// 3-perf feet hold 22, 21 and 21 frames
+		{21, Format35mm3Perf, "0+21"},
+		{22, Format35mm3Perf, "1+00"},
+		{43, Format35mm3Perf, "2+00"},
+		{64, Format35mm3Perf, "3+00"},
+		{13, Format65mm, "1+00"},
+	}
+	for _, tt := range tests {
+		ff := FeetFramesFromFrames(tt.frames, tt.format)
+		if ff.String() != tt.want {
+			t.Errorf("FeetFramesFromFrames(%d, %s) = %s, want %s", tt.frames, tt.format, ff, tt.want)
+		}
+		if err := ff.Validate(tt.format); err != nil {
+			t.Errorf("%s.Validate(%s) error = %v", ff, tt.format, err)
+		}
+		parsed, err := ParseFeetFrames(tt.want)
+		if err != nil || parsed.ToFrames(tt.format) != tt.frames {
+			t.Errorf("ParseFeetFrames(%q).ToFrames() = %d, %v, want %d", tt.want, parsed.ToFrames(tt.format), err, tt.frames)
+		}
+	}
+
+	if err := (FeetFrames{Feet: 1, Frames: 21}).Validate(Format35mm3Perf); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidFeetFrames.Error()) {
+		t.Errorf("Validate() of 1+21 at 3-perf error = %v", err)
+	}
+	for _, s := range []string{"123", "12+x", "+08", "-1+00"} {
+		if _, err := ParseFeetFrames(s); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidFeetFrames.Error()) {
+			t.Errorf("ParseFeetFrames(%q) error = %v", s, err)
+		}
+	}
+}
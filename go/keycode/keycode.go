@@ -0,0 +1,146 @@
+// Package keycode provides film key number (KeyKode) and feet+frames
+// arithmetic for the film formats of ALE FILM_FORMAT header fields.
+package keycode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/keycode/errors"
+)
+
+// keycodePattern matches a keycode with its spaces removed, such as
+// "KJ2345128765+07" or, with a perforation offset, "KJ2345128765+07.2".
+var keycodePattern = regexp.MustCompile(`^([A-Z]{2})(\d{6})(\d{4})\+(\d{1,3})(?:\.(\d))?$`)
+
+// Keycode is the key number of a frame: the key number printed on the film
+// edge before it and the frames from there.
+type Keycode struct {
+	// Stock is the manufacturer and film stock code, such as "KJ".
+	Stock string
+	// Prefix is the six-digit number that identifies the roll, such as
+	// "234512", written as "23 4512".
+	Prefix string
+	// Count is the four-digit count of the key number, which increases by
+	// one at each key number.
+	Count int
+	// Frames is the number of frames after the key number.
+	Frames int
+	// Perf is the number of perforations after the frames, for layouts such
+	// as 35 mm 3-perf whose frames do not all start at a key number.
+	Perf int
+}
+
+// Parse parses a keycode written as "KJ 23 4512 8765+07", with or without
+// the spaces. A perforation offset may follow the frames, as in "+07.2".
+func Parse(s string) (Keycode, error) {
+	compact := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	m := keycodePattern.FindStringSubmatch(compact)
+	if m == nil {
+		return Keycode{}, errors.ErrInputInvalidKeycode.WithContext(fmt.Sprintf("%q", s))
+	}
+	k := Keycode{Stock: m[1], Prefix: m[2]}
+	k.Count, _ = strconv.Atoi(m[3])
+	k.Frames, _ = strconv.Atoi(m[4])
+	if m[5] != "" {
+		k.Perf, _ = strconv.Atoi(m[5])
+	}
+	return k, nil
+}
+
+// MustParse is like Parse but panics if the keycode is invalid.
+func MustParse(s string) Keycode {
+	k, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// String formats the keycode as "KJ 23 4512 8765+07", adding the
+// perforation offset, as in "+07.2", when there is one.
+func (k Keycode) String() string {
+	prefix := k.Prefix
+	if len(prefix) == 6 {
+		prefix = prefix[:2] + " " + prefix[2:]
+	}
+	s := fmt.Sprintf("%s %s %04d+%02d", k.Stock, prefix, k.Count, k.Frames)
+	if k.Perf != 0 {
+		s += fmt.Sprintf(".%d", k.Perf)
+	}
+	return s
+}
+
+// Validate checks that the count has four digits and that the frames and
+// perforations fall before the next key number in the format.
+func (k Keycode) Validate(f Format) error {
+	switch {
+	case len(k.Stock) != 2 || len(k.Prefix) != 6 || !isDigits(k.Prefix):
+		return errors.ErrInputInvalidKeycode.WithContext(fmt.Sprintf("%q", k))
+	case k.Count < 0 || k.Count > 9999:
+		return errors.ErrInputInvalidKeycode.WithContext(fmt.Sprintf("%s count out of range", k))
+	case k.Frames < 0 || k.Perf < 0 || k.Perf >= f.Perfs || k.Frames*f.Perfs+k.Perf >= f.KeyPerfs:
+		return errors.ErrInputInvalidKeycode.WithContext(fmt.Sprintf("%s is past the next key number at %s", k, f))
+	}
+	return nil
+}
+
+// SameRoll reports whether two keycodes have the same stock and prefix.
+func (k Keycode) SameRoll(u Keycode) bool {
+	return k.Stock == u.Stock && k.Prefix == u.Prefix
+}
+
+// ToFrames returns the number of frames from key number count 0 of the
+// roll in the format. A perforation offset is left out.
+func (k Keycode) ToFrames(f Format) int {
+	return floorDiv(k.perfs(f), f.Perfs)
+}
+
+// ToFeetFrames returns the feet+frames of the keycode from key number
+// count 0 of the roll in the format.
+func (k Keycode) ToFeetFrames(f Format) FeetFrames {
+	return FeetFramesFromFrames(k.ToFrames(f), f)
+}
+
+// FromFrames returns the keycode of a roll a number of frames from its key
+// number count 0 in the format.
+func FromFrames(stock, prefix string, frames int, f Format) Keycode {
+	return fromPerfs(stock, prefix, frames*f.Perfs, f)
+}
+
+// Add returns the keycode a number of frames later in the format, keeping
+// its perforation offset.
+func (k Keycode) Add(frames int, f Format) Keycode {
+	return fromPerfs(k.Stock, k.Prefix, k.perfs(f)+frames*f.Perfs, f)
+}
+
+// Sub returns the number of frames from u to k in the format. The keycodes
+// must be on the same roll.
+func (k Keycode) Sub(u Keycode, f Format) (int, error) {
+	if !k.SameRoll(u) {
+		return 0, errors.ErrInputDifferentRolls.WithContext(fmt.Sprintf("%s and %s", k, u))
+	}
+	return floorDiv(k.perfs(f)-u.perfs(f), f.Perfs), nil
+}
+
+// perfs returns the position of the keycode in perforations from key
+// number count 0.
+func (k Keycode) perfs(f Format) int {
+	return k.Count*f.KeyPerfs + k.Frames*f.Perfs + k.Perf
+}
+
+// fromPerfs returns the keycode at a position in perforations from key
+// number count 0.
+func fromPerfs(stock, prefix string, perfs int, f Format) Keycode {
+	count := floorDiv(perfs, f.KeyPerfs)
+	offset := perfs - count*f.KeyPerfs
+	return Keycode{
+		Stock:  stock,
+		Prefix: prefix,
+		Count:  count,
+		Frames: offset / f.Perfs,
+		Perf:   offset % f.Perfs,
+	}
+}
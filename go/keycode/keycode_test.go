@@ -0,0 +1,119 @@
+package keycode
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/keycode/errors"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Keycode
+		wantErr bool
+	}{
+		{input: "KJ 23 4512 8765+07", want: Keycode{Stock: "KJ", Prefix: "234512", Count: 8765, Frames: 7}},
+		{input: "kj2345128765+07", want: Keycode{Stock: "KJ", Prefix: "234512", Count: 8765, Frames: 7}},
+		{input: " EK 12 3456 0001+00.2 ", want: Keycode{Stock: "EK", Prefix: "123456", Count: 1, Frames: 0, Perf: 2}},
+		{input: "KJ 23 4512 8765", wantErr: true},
+		{input: "KJ 23 451 8765+07", wantErr: true},
+		{input: "K1 23 4512 8765+07", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err != nil && (err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidKeycode.Error())) {
+			t.Errorf("Parse(%q) error = %v, want ErrInputInvalidKeycode", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestKeycodeString(t *testing.T) {
+	tests := map[Keycode]string{
+		{Stock: "KJ", Prefix: "234512", Count: 8765, Frames: 7}:     "KJ 23 4512 8765+07",
+		{Stock: "EK", Prefix: "123456", Count: 12, Frames: 19}:      "EK 12 3456 0012+19",
+		{Stock: "KJ", Prefix: "234512", Count: 1, Perf: 2}:          "KJ 23 4512 0001+00.2",
+		{Stock: "KJ", Prefix: "234512", Count: 1, Frames: 100}:      "KJ 23 4512 0001+100",
+		{Stock: "KJ", Prefix: "2345", Count: 1, Frames: 1, Perf: 0}: "KJ 2345 0001+01",
+	}
+	for k, want := range tests {
+		if got := k.String(); got != want {
+			t.Errorf("%+v.String() = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestKeycodeValidate(t *testing.T) {
+	tests := []struct {
+		keycode string
+		format  Format
+		wantErr bool
+	}{
+		{"KJ 23 4512 8765+15", Format35mm, false},
+		{"KJ 23 4512 8765+16", Format35mm, true},
+		{"KJ 23 4512 8765+21", Format35mm3Perf, false},
+		{"KJ 23 4512 8765+21.1", Format35mm3Perf, true},
+		{"KJ 23 4512 8765+00.4", Format35mm, true},
+		{"EK 12 3456 0001+19", Format16mm, false},
+		{"EK 12 3456 0001+20", Format16mm, true},
+		{"KJ 23 4512 0001+23", Format65mm, false},
+		{"KJ 23 4512 0001+24", Format65mm, true},
+	}
+	for _, tt := range tests {
+		err := MustParse(tt.keycode).Validate(tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s.Validate(%s) error = %v, wantErr %v", tt.keycode, tt.format, err, tt.wantErr)
+		}
+	}
+	if err := (Keycode{Stock: "KJ", Prefix: "234512", Count: 10000}).Validate(Format35mm); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidKeycode.Error()) {
+		t.Errorf("Validate() of count 10000 error = %v", err)
+	}
+}
+
+func TestKeycodeArithmetic(t *testing.T) {
+	tests := []struct {
+		start  string
+		frames int
+		format Format
+		want   string
+	}{
+		{"KJ 23 4512 8765+07", 9, Format35mm, "KJ 23 4512 8766+00"},
+		{"KJ 23 4512 8765+07", 100, Format35mm, "KJ 23 4512 8771+11"},
+		{"KJ 23 4512 8765+07", -8, Format35mm, "KJ 23 4512 8764+15"},
+		{"EK 12 3456 0010+00", 45, Format16mm, "EK 12 3456 0012+05"},
+		{"KJ 23 4512 0000+00", 22, Format35mm3Perf, "KJ 23 4512 0001+00.2"},
+		{"KJ 23 4512 0000+00", 64, Format35mm3Perf, "KJ 23 4512 0003+00"},
+		{"KJ 23 4512 0000+00", 24, Format65mm, "KJ 23 4512 0001+00"},
+	}
+	for _, tt := range tests {
+		start := MustParse(tt.start)
+		got := start.Add(tt.frames, tt.format)
+		if got.String() != tt.want {
+			t.Errorf("%s.Add(%d, %s) = %s, want %s", tt.start, tt.frames, tt.format, got, tt.want)
+		}
+		if n, err := got.Sub(start, tt.format); err != nil || n != tt.frames {
+			t.Errorf("%s.Sub(%s) = %d, %v, want %d", got, tt.start, n, err, tt.frames)
+		}
+		if back := FromFrames(start.Stock, start.Prefix, got.ToFrames(tt.format), tt.format); got.Perf == 0 && back != got {
+			t.Errorf("FromFrames(%s.ToFrames()) = %s", got, back)
+		}
+	}
+
+	if _, err := MustParse("KJ 23 4512 0001+00").Sub(MustParse("KJ 23 4513 0001+00"), Format35mm); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputDifferentRolls.Error()) {
+		t.Errorf("Sub() across rolls error = %v, want ErrInputDifferentRolls", err)
+	}
+	if got := MustParse("KJ 23 4512 0123+08").ToFeetFrames(Format35mm); got != (FeetFrames{Feet: 123, Frames: 8}) {
+		t.Errorf("ToFeetFrames() = %s, want 123+08", got)
+	}
+	if got := MustParse("EK 12 3456 0123+08").ToFeetFrames(Format16mm); got != (FeetFrames{Feet: 61, Frames: 28}) {
+		t.Errorf("ToFeetFrames() at 16 mm = %s, want 61+28", got)
+	}
+}
@@ -0,0 +1,64 @@
+package keycode
+
+import (
+	"fmt"
+	"strings"
+
+	"lib-post-interchange/keycode/errors"
+)
+
+// PulldownPhases are the Pullin values of the five video frames of a 3:2
+// pulldown cycle, in order. X is the frame whose fields come from the B and
+// C film frames.
+const PulldownPhases = "ABXCD"
+
+// pulldownFilmFrames is the film frame, within the four of a cycle, of the
+// first field of each video frame of the cycle.
+var pulldownFilmFrames = [5]int{0, 1, 1, 2, 3}
+
+// pulldownVideoFrames is the first video frame, within the five of a cycle,
+// of each film frame of the cycle.
+var pulldownVideoFrames = [4]int{0, 1, 3, 4}
+
+// PullinPhase returns the position of a Pullin value in the pulldown cycle,
+// from 0 for A to 4 for D. An empty pullin means A.
+func PullinPhase(pullin string) (int, error) {
+	p := strings.ToUpper(strings.TrimSpace(pullin))
+	if p == "" {
+		return 0, nil
+	}
+	phase := strings.Index(PulldownPhases, p)
+	if phase < 0 || len(p) != 1 {
+		return 0, errors.ErrInputInvalidPullin.WithContext(fmt.Sprintf("%q", pullin))
+	}
+	return phase, nil
+}
+
+// FilmFrame returns the film frame of a video frame in a 3:2 pulldown
+// transfer, counted from the film frame of video frame 0, whose phase is
+// pullin. A video frame belongs to the film frame of its first field, so
+// that any five consecutive video frames hold four film frames and the film
+// frames of abutting video ranges neither overlap nor leave gaps. An empty
+// pullin means A.
+func FilmFrame(videoFrame int, pullin string) (int, error) {
+	phase, err := PullinPhase(pullin)
+	if err != nil {
+		return 0, err
+	}
+	position := videoFrame + phase
+	cycle := floorDiv(position, 5)
+	return cycle*4 + pulldownFilmFrames[position-cycle*5] - pulldownFilmFrames[phase], nil
+}
+
+// VideoFrame returns the first video frame of a film frame in a 3:2
+// pulldown transfer, the inverse of FilmFrame. It is before video frame 0
+// for film frame 0 when pullin is X, as that frame starts on the B frame.
+func VideoFrame(filmFrame int, pullin string) (int, error) {
+	phase, err := PullinPhase(pullin)
+	if err != nil {
+		return 0, err
+	}
+	position := filmFrame + pulldownFilmFrames[phase]
+	cycle := floorDiv(position, 4)
+	return cycle*5 + pulldownVideoFrames[position-cycle*4] - phase, nil
+}
@@ -0,0 +1,89 @@
+package keycode
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/keycode/errors"
+)
+
+func TestFilmFrame(t *testing.T) {
+	tests := []struct {
+		pullin string
+		want   []int
+	}{
+		{"A", []int{0, 1, 1, 2, 3, 4, 5, 5, 6, 7}},
+		{"", []int{0, 1, 1, 2, 3, 4, 5, 5, 6, 7}},
+		{"X", []int{0, 1, 2, 3, 4, 4, 5, 6, 7, 8}},
+		{"d", []int{0, 1, 2, 2, 3, 4, 5, 6, 6, 7}},
+	}
+	for _, tt := range tests {
+		for frame, want := range tt.want {
+			got, err := FilmFrame(frame, tt.pullin)
+			if err != nil || got != want {
+				t.Errorf("FilmFrame(%d, %q) = %d, %v, want %d", frame, tt.pullin, got, err, want)
+			}
+		}
+	}
+	// Five video frames hold four film frames wherever they start
+	for start := -7; start < 7; start++ {
+		from, _ := FilmFrame(start, "B")
+		to, _ := FilmFrame(start+5, "B")
+		if to-from != 4 {
+			t.Errorf("FilmFrame() from %d to %d holds %d film frames, want 4", start, start+5, to-from)
+		}
+	}
+	for _, pullin := range []string{"E", "AB"} {
+		if _, err := FilmFrame(0, pullin); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidPullin.Error()) {
+			t.Errorf("FilmFrame(0, %q) error = %v, want ErrInputInvalidPullin", pullin, err)
+		}
+	}
+}
+
+func TestVideoFrame(t *testing.T) {
+	tests := []struct {
+		pullin string
+		want   []int
+	}{
+		{"A", []int{0, 1, 3, 4, 5, 6, 8, 9}},
+		{"X", []int{-1, 1, 2, 3, 4, 6, 7, 8}},
+		{"c", []int{0, 1, 2, 3, 5, 6, 7, 8}},
+	}
+	for _, tt := range tests {
+		for frame, want := range tt.want {
+			got, err := VideoFrame(frame, tt.pullin)
+			if err != nil || got != want {
+				t.Errorf("VideoFrame(%d, %q) = %d, %v, want %d", frame, tt.pullin, got, err, want)
+			}
+		}
+	}
+	// VideoFrame is the inverse of FilmFrame for every phase
+	for _, pullin := range PulldownPhases {
+		for film := -9; film < 9; film++ {
+			video, _ := VideoFrame(film, string(pullin))
+			if got, _ := FilmFrame(video, string(pullin)); got != film {
+				t.Errorf("FilmFrame(VideoFrame(%d, %q)) = %d", film, pullin, got)
+			}
+			if before, _ := FilmFrame(video-1, string(pullin)); before != film-1 {
+				t.Errorf("VideoFrame(%d, %q) = %d is not the first video frame", film, pullin, video)
+			}
+		}
+	}
+	if _, err := VideoFrame(0, "Q"); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidPullin.Error()) {
+		t.Errorf("VideoFrame(0, \"Q\") error = %v, want ErrInputInvalidPullin", err)
+	}
+}
+
+func TestPullinPhase(t *testing.T) {
+	tests := map[string]int{"": 0, "A": 0, "b": 1, " X ": 2, "C": 3, "D": 4}
+	for pullin, want := range tests {
+		if got, err := PullinPhase(pullin); err != nil || got != want {
+			t.Errorf("PullinPhase(%q) = %d, %v, want %d", pullin, got, err, want)
+		}
+	}
+	for _, pullin := range []string{"E", "AB", "1"} {
+		if _, err := PullinPhase(pullin); err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputInvalidPullin.Error()) {
+			t.Errorf("PullinPhase(%q) error = %v, want ErrInputInvalidPullin", pullin, err)
+		}
+	}
+}
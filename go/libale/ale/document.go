@@ -0,0 +1,336 @@
+package ale
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/format"
+	"lib-post-interchange/libale/types"
+)
+
+// Document pairs a parsed ALE object with the concrete syntax it was read from.
+// Writing a Document reproduces the original bytes for every part of the file
+// that has not been changed through Object: line endings, blank lines, header
+// lines skipped by the parser and tabs inside header values are all kept.
+type Document struct {
+	Object *types.Object
+
+	prelude  []rawLine
+	rows     []rawRow
+	trailing []rawLine
+
+	header  []types.BaseField
+	columns []string
+	newline string
+}
+
+// lineKind classifies a raw line of an ALE file.
+type lineKind int
+
+const (
+	lineOther lineKind = iota
+	lineHeaderField
+	lineColumns
+	lineRow
+)
+
+// rawLine is a single line of input, split from its line ending.
+type rawLine struct {
+	kind   lineKind
+	text   string
+	ending string
+	index  int
+}
+
+// rawRow is a data row together with the blank lines that preceded it.
+type rawRow struct {
+	trivia []rawLine
+	line   rawLine
+	fields []string
+}
+
+// ReadDocumentFile reads an ALE file from the filesystem, keeping its layout.
+func ReadDocumentFile(filepath string) (*Document, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return ReadDocument(string(data))
+}
+
+// ReadDocument parses ALE data from a string, keeping its layout so that
+// WriteDocument can reproduce it.
+func ReadDocument(input string) (*Document, error) {
+	obj, err := Read(input)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Object: obj}
+	lines := splitLines(input)
+	if len(lines) > 0 && lines[0].ending != "" {
+		doc.newline = lines[0].ending
+	} else {
+		doc.newline = "\n"
+	}
+
+	// Follow the same section structure as read, which has already
+	// accepted this input, so the classification below cannot fail.
+	i := 0
+	doc.prelude = append(doc.prelude, lines[i]) // Heading
+	i++
+	fieldIndex := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line.text == "" {
+			doc.prelude = append(doc.prelude, line)
+			i++
+			break
+		}
+		if strings.Contains(line.text, "\t") {
+			line.kind = lineHeaderField
+			line.index = fieldIndex
+			fieldIndex++
+		}
+		doc.prelude = append(doc.prelude, line)
+	}
+	doc.prelude = append(doc.prelude, lines[i]) // Column
+	i++
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line.text != "" {
+			line.kind = lineColumns
+			doc.prelude = append(doc.prelude, line)
+			i++
+			break
+		}
+		doc.prelude = append(doc.prelude, line)
+	}
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		doc.prelude = append(doc.prelude, line)
+		if line.text == format.Data {
+			i++
+			break
+		}
+	}
+	var trivia []rawLine
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line.text == "" || line.text == format.Data {
+			trivia = append(trivia, line)
+			continue
+		}
+		fields, _ := readTSVLine(line.text)
+		line.kind = lineRow
+		line.index = len(doc.rows)
+		doc.rows = append(doc.rows, rawRow{trivia: trivia, line: line, fields: fields})
+		trivia = nil
+	}
+	doc.trailing = trivia
+
+	// Snapshot what was parsed so later edits to Object can be detected.
+	for _, field := range obj.HeaderFields {
+		doc.header = append(doc.header, types.BaseField{Key: field.GetKey(), Value: field.GetValue()})
+	}
+	doc.columns = obj.ColumnNames()
+
+	return doc, nil
+}
+
+// WriteDocumentFile writes a Document to a file at the specified path. Only
+// the options that apply when writing to a file are used: the document keeps
+// its own line endings and formatting.
+func WriteDocumentFile(filepath string, doc *Document, opts WriteOptions) error {
+	data, err := WriteDocument(doc)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath, []byte(data), opts)
+}
+
+// WriteDocument converts a Document back to ALE format. Lines whose content
+// is unchanged are written exactly as they were read; changed lines are
+// regenerated with the line ending they originally had.
+func WriteDocument(doc *Document) (string, error) {
+	if doc == nil || doc.Object == nil {
+		return "", errors.ErrOutputNilObject
+	}
+	ale := doc.Object
+
+	var builder strings.Builder
+	// A line read without a complete line ending is finished before
+	// anything is written after it, so nothing is joined onto it
+	last := "\n"
+	writeLine := func(text, ending string) {
+		switch last {
+		case "":
+			builder.WriteString(doc.newline)
+		case "\r":
+			builder.WriteString("\n")
+		}
+		builder.WriteString(text)
+		builder.WriteString(ending)
+		last = ending
+	}
+
+	// Position after which header fields added to Object are inserted
+	lastField := 0
+	for i, line := range doc.prelude {
+		if line.kind == lineHeaderField {
+			lastField = i
+		}
+	}
+
+	matched := matchHeaderFields(doc.header, ale.HeaderFields)
+	for i, line := range doc.prelude {
+		switch line.kind {
+		case lineHeaderField:
+			j := -1
+			if line.index < len(matched) {
+				j = matched[line.index]
+			}
+			if j < 0 {
+				break
+			}
+			if field := ale.HeaderFields[j]; field.GetValue() != doc.header[line.index].Value {
+				writeLine(headerLine(field), line.ending)
+			} else {
+				writeLine(line.text, line.ending)
+			}
+		case lineColumns:
+			names := ale.ColumnNames()
+			if slices.Equal(names, doc.columns) {
+				writeLine(line.text, line.ending)
+				break
+			}
+			text := tableLine(names)
+			if isSectionBreak(text) {
+				return "", errors.ErrOutputUnreadableLine.WithContext(fmt.Sprintf("column line %q", text))
+			}
+			writeLine(text, line.ending)
+		default:
+			writeLine(line.text, line.ending)
+		}
+		if i == lastField {
+			for j, field := range ale.HeaderFields {
+				if field == nil || slices.Contains(matched, j) {
+					continue
+				}
+				writeLine(headerLine(field), doc.newline)
+			}
+		}
+	}
+
+	columnsChanged := !slices.Equal(ale.ColumnNames(), doc.columns)
+	emitted := make(map[int]bool, len(doc.rows))
+	for i, row := range ale.Rows {
+		values := ale.Values(row)
+		if text := tableLine(values); isSectionBreak(text) {
+			return "", errors.ErrOutputUnreadableLine.WithContext(fmt.Sprintf("row %d %q", i, text))
+		}
+		if row.Order >= 0 && row.Order < len(doc.rows) && !emitted[row.Order] {
+			original := doc.rows[row.Order]
+			emitted[row.Order] = true
+			for _, line := range original.trivia {
+				writeLine(line.text, line.ending)
+			}
+			if !columnsChanged && equalFields(values, original.fields) {
+				writeLine(original.line.text, original.line.ending)
+				continue
+			}
+			if !columnsChanged && len(original.fields) > len(values) {
+				values = append(values, original.fields[len(values):]...)
+			}
+			ending := original.line.ending
+			if ending == "" {
+				ending = doc.newline
+			}
+			writeLine(tableLine(values), ending)
+			continue
+		}
+		writeLine(tableLine(values), doc.newline)
+	}
+	for _, line := range doc.trailing {
+		writeLine(line.text, line.ending)
+	}
+
+	return builder.String(), nil
+}
+
+// splitLines splits input into lines, keeping each line's ending.
+// As with bufio.ScanLines, "\n" ends a line and a "\r" before it belongs to the ending.
+func splitLines(input string) []rawLine {
+	var lines []rawLine
+	for len(input) > 0 {
+		i := strings.IndexByte(input, '\n')
+		if i < 0 {
+			text, ending := input, ""
+			if strings.HasSuffix(text, "\r") {
+				text, ending = text[:len(text)-1], "\r"
+			}
+			lines = append(lines, rawLine{text: text, ending: ending})
+			break
+		}
+		text, ending := input[:i], "\n"
+		if strings.HasSuffix(text, "\r") {
+			text, ending = text[:len(text)-1], "\r\n"
+		}
+		lines = append(lines, rawLine{text: text, ending: ending})
+		input = input[i+1:]
+	}
+	return lines
+}
+
+// headerLine regenerates a header field line, replacing any illegal characters.
+func headerLine(field types.Field) string {
+	key, _ := sanitize(field.GetKey(), illegalInTable, SanitizeReplace)
+	value, _ := sanitize(field.GetValue(), illegalInHeaderValue, SanitizeReplace)
+	return key + "\t" + value
+}
+
+// tableLine regenerates a column or data line, replacing any illegal characters.
+func tableLine(fields []string) string {
+	sanitized := make([]string, len(fields))
+	for i, field := range fields {
+		sanitized[i], _ = sanitize(field, illegalInTable, SanitizeReplace)
+	}
+	return strings.Join(sanitized, "\t")
+}
+
+// matchHeaderFields returns, for each header field read, the index of the
+// Object's field with the same key, or -1 if it has been removed. Fields are
+// matched by key in order, so removing one field leaves the lines of the
+// others in place, and repeated keys match in the order they were read.
+func matchHeaderFields(original []types.BaseField, fields []types.Field) []int {
+	matched := make([]int, len(original))
+	used := make([]bool, len(fields))
+	for i, field := range original {
+		matched[i] = -1
+		for j, candidate := range fields {
+			if !used[j] && candidate != nil && candidate.GetKey() == field.Key {
+				matched[i], used[j] = j, true
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// equalFields reports whether row values match the fields originally read.
+// Missing trailing fields read as empty, just as the parser pads them.
+func equalFields(values, fields []string) bool {
+	for i, value := range values {
+		original := ""
+		if i < len(fields) {
+			original = fields[i]
+		}
+		if value != original {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+package ale
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+)
+
+func TestWriteDocumentRoundTripSamples(t *testing.T) {
+	files, err := filepath.Glob("../../../samples/ALE/*.ale")
+	if err != nil {
+		t.Fatalf("Failed to glob sample files: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("No .ale files found in samples directory")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read sample file: %v", err)
+			}
+			doc, err := ReadDocument(string(data))
+			if err != nil {
+				t.Fatalf("ReadDocument() error = %v", err)
+			}
+			output, err := WriteDocument(doc)
+			if err != nil {
+				t.Fatalf("WriteDocument() error = %v", err)
+			}
+			if output != string(data) {
+				t.Errorf("WriteDocument() output differs from input")
+			}
+		})
+	}
+}
+
+func TestWriteDocumentPreservesLayout(t *testing.T) {
+	input := "Heading\r\n" +
+		"FIELD_DELIM\tTABS\r\n" +
+		"a note without a tab\r\n" +
+		"TAPE\tA001\tB\r\n" +
+		"\r\n" +
+		"Column\r\n" +
+		"\r\n" +
+		"Name\tScene\tTake\t\r\n" +
+		"\r\n" +
+		"Data\r\n" +
+		"A001\t1\t1\t\r\n" +
+		"\r\n" +
+		"A002\t1\t2\textra\r\n" +
+		"\r\n"
+
+	tests := []struct {
+		name string
+		edit func(*types.Object)
+		want string
+	}{
+		{
+			name: "unchanged",
+			edit: func(*types.Object) {},
+			want: input,
+		},
+		{
+			name: "edit one value",
+			edit: func(obj *types.Object) {
+				setValue(obj, 1, "Take", "3")
+			},
+			want: strings.Replace(input, "A002\t1\t2\textra\r\n", "A002\t1\t3\textra\r\n", 1),
+		},
+		{
+			name: "edit header value",
+			edit: func(obj *types.Object) {
+				obj.HeaderFields[0] = types.BaseField{Key: "FIELD_DELIM", Value: "TABS2"}
+			},
+			want: strings.Replace(input, "FIELD_DELIM\tTABS\r\n", "FIELD_DELIM\tTABS2\r\n", 1),
+		},
+		{
+			name: "add header field",
+			edit: func(obj *types.Object) {
+				obj.HeaderFields = append(obj.HeaderFields, types.BaseField{Key: "FPS", Value: "25"})
+			},
+			want: strings.Replace(input, "TAPE\tA001\tB\r\n", "TAPE\tA001\tB\r\nFPS\t25\r\n", 1),
+		},
+		{
+			name: "remove header field",
+			edit: func(obj *types.Object) {
+				obj.HeaderFields = obj.HeaderFields[1:]
+			},
+			want: strings.Replace(input, "FIELD_DELIM\tTABS\r\n", "", 1),
+		},
+		{
+			name: "remove header field and edit another",
+			edit: func(obj *types.Object) {
+				obj.HeaderFields = []types.Field{types.BaseField{Key: "TAPE", Value: "A002"}}
+			},
+			want: strings.Replace(strings.Replace(input, "FIELD_DELIM\tTABS\r\n", "", 1), "TAPE\tA001\tB\r\n", "TAPE\tA002\r\n", 1),
+		},
+		{
+			name: "remove row",
+			edit: func(obj *types.Object) {
+				obj.Rows = obj.Rows[1:]
+			},
+			want: strings.Replace(input, "A001\t1\t1\t\r\n", "", 1),
+		},
+		{
+			name: "append row",
+			edit: func(obj *types.Object) {
+				row := types.Row{Columns: obj.Columns, ValueMap: map[types.Column]types.Value{}, Order: -1}
+				for _, col := range obj.Columns {
+					row.ValueMap[col] = types.StringValue{Column: col, Value: "x"}
+				}
+				obj.Rows = append(obj.Rows, row)
+			},
+			want: strings.TrimSuffix(input, "\r\n") + "x\tx\tx\tx\r\n\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ReadDocument(input)
+			if err != nil {
+				t.Fatalf("ReadDocument() error = %v", err)
+			}
+			tt.edit(doc.Object)
+			got, err := WriteDocument(doc)
+			if err != nil {
+				t.Fatalf("WriteDocument() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("WriteDocument() output:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteDocumentNoFinalLineEnding(t *testing.T) {
+	input := "Heading\nFIELD_DELIM\tTABS\n\nColumn\nName\tTake\n\nData\nA001\t1"
+
+	doc, err := ReadDocument(input)
+	if err != nil {
+		t.Fatalf("ReadDocument() error = %v", err)
+	}
+	unchanged, err := WriteDocument(doc)
+	if err != nil {
+		t.Fatalf("WriteDocument() error = %v", err)
+	}
+	if unchanged != input {
+		t.Errorf("WriteDocument() output:\ngot  %q\nwant %q", unchanged, input)
+	}
+
+	row := types.Row{Columns: doc.Object.Columns, ValueMap: map[types.Column]types.Value{}, Order: -1}
+	for _, col := range doc.Object.Columns {
+		row.ValueMap[col] = types.StringValue{Column: col, Value: "x"}
+	}
+	doc.Object.Rows = append(doc.Object.Rows, row)
+	got, err := WriteDocument(doc)
+	if err != nil {
+		t.Fatalf("WriteDocument() error = %v", err)
+	}
+	if want := input + "\nx\tx\n"; got != want {
+		t.Errorf("WriteDocument() output:\ngot  %q\nwant %q", got, want)
+	}
+	obj, err := Read(got)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(obj.Rows) != 2 {
+		t.Errorf("Read() found %d rows, want 2", len(obj.Rows))
+	}
+}
+
+func TestWriteDocumentUnreadableRow(t *testing.T) {
+	doc, err := ReadDocument("Heading\nFIELD_DELIM\tTABS\n\nColumn\nName\n\nData\nA001\n")
+	if err != nil {
+		t.Fatalf("ReadDocument() error = %v", err)
+	}
+	setValue(doc.Object, 0, "Name", "Data")
+	if _, err := WriteDocument(doc); err == nil || !strings.HasPrefix(err.Error(), errors.ErrOutputUnreadableLine.Error()) {
+		t.Errorf("WriteDocument() error = %v, want %v", err, errors.ErrOutputUnreadableLine)
+	}
+}
+
+func TestWriteDocumentNil(t *testing.T) {
+	if _, err := WriteDocument(nil); err == nil {
+		t.Error("Expected error when writing nil document, got nil")
+	}
+}
+
+// setValue replaces the value of a named column in a row.
+func setValue(obj *types.Object, row int, name, value string) {
+	for _, col := range obj.Columns {
+		if col.Name == name {
+			obj.Rows[row].ValueMap[col] = types.StringValue{Column: col, Value: value}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package ale
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+
+	"lib-post-interchange/libale/errors"
+)
+
+// backupTimeFormat is the timestamp appended to the name of backup files.
+const backupTimeFormat = "20060102T150405"
+
+// maxBackups is the number of backups of one file that can be made within
+// the same second, each numbered after its timestamp.
+const maxBackups = 1000
+
+// writeFileAtomic writes data to path so that readers only ever see the old
+// file or the complete new one. Data goes to a temporary file in the same
+// directory, which is synced and then renamed over path. With NoOverwrite
+// the temporary file is hard linked to path instead, which fails if path
+// exists, so a file created after the check is never replaced.
+func writeFileAtomic(path string, data []byte, opts WriteOptions) error {
+	// A new file is created with the usual mode less the umask, as
+	// os.WriteFile does; a replaced file keeps its mode unless ResetMode
+	var perm fs.FileMode
+	existing, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if opts.NoOverwrite {
+			return errors.ErrOutputFileExists.WithContext(path)
+		}
+		if !existing.Mode().IsRegular() {
+			return errors.ErrOutputFailedWrite.WithContext(fmt.Sprintf("%s is not a regular file", path))
+		}
+		if !opts.ResetMode {
+			perm = existing.Mode().Perm()
+		}
+		if opts.Backup {
+			if err := backupFile(path, existing.Mode().Perm(), time.Now()); err != nil {
+				return err
+			}
+		}
+	case !os.IsNotExist(err):
+		return errors.ErrOutputFailedWrite.WithContext(err.Error())
+	}
+
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := createTemp(dir, base)
+	if err != nil {
+		return errors.ErrOutputFailedWrite.WithContext(err.Error())
+	}
+	tmpName := tmp.Name()
+	// Remove the temporary file unless it has been renamed into place
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.ErrOutputFailedWrite.WithContext(err.Error())
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.ErrOutputFailedWrite.WithContext(err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.ErrOutputFailedWrite.WithContext(err.Error())
+	}
+	if perm != 0 {
+		if err := os.Chmod(tmpName, perm); err != nil {
+			return errors.ErrOutputFailedWrite.WithContext(err.Error())
+		}
+	}
+	if opts.NoOverwrite {
+		if err := os.Link(tmpName, path); err != nil {
+			if os.IsExist(err) {
+				return errors.ErrOutputFileExists.WithContext(path)
+			}
+			return errors.ErrOutputFailedWrite.WithContext(err.Error())
+		}
+	} else if err := os.Rename(tmpName, path); err != nil {
+		return errors.ErrOutputFailedWrite.WithContext(err.Error())
+	}
+
+	// Sync the directory so the rename itself is durable. Not every
+	// platform supports this, so failures are ignored.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// createTemp creates a temporary file for base in dir. Unlike os.CreateTemp
+// it asks for mode 0644, so the file gets the umask as a new file would.
+func createTemp(dir, base string) (*os.File, error) {
+	for {
+		name := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", base, rand.Uint32()))
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+}
+
+// backupFile copies path to a timestamped .bak file alongside it. Backups
+// made within the same second are numbered, as <file>.<timestamp>.1.bak and
+// so on.
+func backupFile(path string, perm fs.FileMode, now time.Time) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return errors.ErrOutputFailedBackup.WithContext(err.Error())
+	}
+	defer src.Close()
+
+	stamp := now.Format(backupTimeFormat)
+	var dst *os.File
+	for n := 0; n < maxBackups; n++ {
+		backupPath := fmt.Sprintf("%s.%s.bak", path, stamp)
+		if n > 0 {
+			backupPath = fmt.Sprintf("%s.%s.%d.bak", path, stamp, n)
+		}
+		dst, err = os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return errors.ErrOutputFailedBackup.WithContext(err.Error())
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return errors.ErrOutputFailedBackup.WithContext(err.Error())
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return errors.ErrOutputFailedBackup.WithContext(err.Error())
+	}
+	if err := dst.Close(); err != nil {
+		return errors.ErrOutputFailedBackup.WithContext(err.Error())
+	}
+	return nil
+}
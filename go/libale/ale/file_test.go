@@ -0,0 +1,150 @@
+package ale
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lib-post-interchange/libale/errors"
+)
+
+func TestWriteFileWithOptions(t *testing.T) {
+	obj, err := ReadFile("../../../samples/ALE/A001R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	want, err := Write(obj)
+	if err != nil {
+		t.Fatalf("Failed to write ALE object: %v", err)
+	}
+
+	t.Run("new file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.ale")
+		if err := WriteFile(path, obj); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		if string(got) != want {
+			t.Error("WriteFile() output does not match Write()")
+		}
+		assertOnlyFiles(t, filepath.Dir(path), "out.ale")
+	})
+
+	t.Run("refuse overwrite", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.ale")
+		if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		opts := DefaultWriteOptions()
+		opts.NoOverwrite = true
+		err := WriteFileWithOptions(path, obj, opts)
+		if !errors.IsCategory(err, errors.CategoryOutput) {
+			t.Fatalf("WriteFileWithOptions() error = %v, want output error", err)
+		}
+		got, _ := os.ReadFile(path)
+		if string(got) != "original" {
+			t.Error("Existing file was modified")
+		}
+		assertOnlyFiles(t, filepath.Dir(path), "out.ale")
+	})
+
+	t.Run("backup and preserve mode", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.ale")
+		if err := os.WriteFile(path, []byte("original"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		opts := DefaultWriteOptions()
+		opts.Backup = true
+		if err := WriteFileWithOptions(path, obj, opts); err != nil {
+			t.Fatalf("WriteFileWithOptions() error = %v", err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("File mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+		}
+
+		backups, _ := filepath.Glob(path + ".*.bak")
+		if len(backups) != 1 {
+			t.Fatalf("Found %d backup files, want 1", len(backups))
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(backups[0], path+"."), ".bak")
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			t.Errorf("Backup file %q is not timestamped: %v", backups[0], err)
+		}
+		got, _ := os.ReadFile(backups[0])
+		if string(got) != "original" {
+			t.Errorf("Backup content = %q, want %q", got, "original")
+		}
+	})
+
+	t.Run("reset mode", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.ale")
+		if err := os.WriteFile(path, []byte("original"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		opts := DefaultWriteOptions()
+		opts.ResetMode = true
+		if err := WriteFileWithOptions(path, obj, opts); err != nil {
+			t.Fatalf("WriteFileWithOptions() error = %v", err)
+		}
+		// A new file gets 0644 less the umask, as os.WriteFile gives it
+		reference := filepath.Join(t.TempDir(), "reference")
+		if err := os.WriteFile(reference, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		wantInfo, _ := os.Stat(reference)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != wantInfo.Mode().Perm() {
+			t.Errorf("File mode = %v, want %v", info.Mode().Perm(), wantInfo.Mode().Perm())
+		}
+	})
+}
+
+func TestBackupFileSameSecond(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.ale")
+	if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	for range 3 {
+		if err := backupFile(path, 0644, now); err != nil {
+			t.Fatalf("backupFile() error = %v", err)
+		}
+	}
+	stamp := now.Format(backupTimeFormat)
+	assertOnlyFiles(t, filepath.Dir(path), "out.ale",
+		"out.ale."+stamp+".bak", "out.ale."+stamp+".1.bak", "out.ale."+stamp+".2.bak")
+	for _, name := range []string{".bak", ".1.bak", ".2.bak"} {
+		if _, err := os.Stat(path + "." + stamp + name); err != nil {
+			t.Errorf("Missing backup: %v", err)
+		}
+	}
+}
+
+// assertOnlyFiles checks that dir contains exactly the named files, so no temporary files are left behind.
+func assertOnlyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(names) {
+		var found []string
+		for _, entry := range entries {
+			found = append(found, entry.Name())
+		}
+		t.Errorf("Directory contains %v, want %v", found, names)
+	}
+}
@@ -36,7 +36,7 @@ func Read(input string) (*types.Object, error) {
 		Columns:      columns,
 		Rows:         rows,
 	}
-	assignHeaderFields(&ale)
+	ale.AssignHeaderFields()
 	return &ale, nil
 }
 
@@ -229,23 +229,3 @@ func makeRowsFromDataRows(rows [][]string, columns []types.Column) ([]types.Row,
 
 	return aleRows, nil
 }
-
-// assignHeaderFields assigns header fields to their specific types in the Object.
-func assignHeaderFields(ale *types.Object) {
-	for _, field := range ale.HeaderFields {
-		switch field.GetKey() {
-		case "FIELD_DELIM":
-			ale.FieldDelimiter = types.FieldDelimiter{BaseField: types.BaseField{Key: field.GetKey(), Value: field.GetValue()}}
-		case "FPS":
-			ale.FPS = types.FrameRate{BaseField: types.BaseField{Key: field.GetKey(), Value: field.GetValue()}}
-		case "AUDIO_FORMAT":
-			ale.AudioFormat = types.AudioFormat{BaseField: types.BaseField{Key: field.GetKey(), Value: field.GetValue()}}
-		case "VIDEO_FORMAT":
-			ale.VideoFormat = types.VideoFormat{BaseField: types.BaseField{Key: field.GetKey(), Value: field.GetValue()}}
-		case "FILM_FORMAT":
-			ale.FilmFormat = types.FilmFormat{BaseField: types.BaseField{Key: field.GetKey(), Value: field.GetValue()}}
-		case "TAPE":
-			ale.Tape = types.Tape{BaseField: types.BaseField{Key: field.GetKey(), Value: field.GetValue()}}
-		}
-	}
-}
@@ -2,7 +2,6 @@ package ale
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"lib-post-interchange/libale/errors"
@@ -10,55 +9,215 @@ import (
 	"lib-post-interchange/libale/types"
 )
 
+// LineEnding selects the line terminator used when writing ALE data.
+type LineEnding int
+
+const (
+	// LF terminates lines with "\n".
+	LF LineEnding = iota
+	// CRLF terminates lines with "\r\n", as expected by Windows Avid suites.
+	CRLF
+)
+
+// String returns the line terminator.
+func (l LineEnding) String() string {
+	if l == CRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// Sanitize selects how characters that would break the ALE table structure
+// are handled when they appear in values, column names or header fields.
+type Sanitize int
+
+const (
+	// SanitizeReplace replaces each illegal character with a space.
+	SanitizeReplace Sanitize = iota
+	// SanitizeStrip removes illegal characters.
+	SanitizeStrip
+	// SanitizeFail returns an error naming the offending row and column.
+	SanitizeFail
+)
+
+// WriteOptions controls how an ALE object is written.
+type WriteOptions struct {
+	// LineEnding is the line terminator used for every line.
+	LineEnding LineEnding
+	// TrailingBlankLine ends the file with an empty line after the last row.
+	TrailingBlankLine bool
+	// Sanitize selects how tabs and line breaks in values and names are handled.
+	Sanitize Sanitize
+	// Projection selects, orders and renames the columns written.
+	// The object being written is not modified.
+	Projection types.Projection
+
+	// The remaining options only apply when writing to a file.
+
+	// Backup keeps a copy of a file being replaced, named <file>.<timestamp>.bak.
+	Backup bool
+	// NoOverwrite refuses to replace an existing file.
+	NoOverwrite bool
+	// ResetMode gives a replaced file the permission bits of a new file
+	// rather than keeping its own.
+	ResetMode bool
+}
+
+// DefaultWriteOptions returns the options used by Write and WriteFile.
+func DefaultWriteOptions() WriteOptions {
+	return WriteOptions{
+		LineEnding: LF,
+		Sanitize:   SanitizeReplace,
+	}
+}
+
 // WriteFile writes an ALE object to a file at the specified path.
 func WriteFile(filepath string, ale *types.Object) error {
-	data, err := Write(ale)
+	return WriteFileWithOptions(filepath, ale, DefaultWriteOptions())
+}
+
+// WriteFileWithOptions writes an ALE object to a file at the specified path using the given options.
+// The file is replaced atomically, so a failed write never leaves a truncated ALE behind.
+func WriteFileWithOptions(filepath string, ale *types.Object, opts WriteOptions) error {
+	data, err := WriteWithOptions(ale, opts)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath, []byte(data), 0644)
+	return writeFileAtomic(filepath, []byte(data), opts)
 }
 
 // Write converts an ALE object to its string representation in ALE format.
 func Write(ale *types.Object) (string, error) {
+	return WriteWithOptions(ale, DefaultWriteOptions())
+}
+
+// WriteWithOptions converts an ALE object to its string representation in ALE format using the given options.
+func WriteWithOptions(ale *types.Object, opts WriteOptions) (string, error) {
 	if ale == nil {
 		return "", errors.ErrOutputNilObject
 	}
+	if !opts.Projection.IsZero() {
+		projected, err := ale.Project(opts.Projection)
+		if err != nil {
+			return "", err
+		}
+		ale = projected
+	}
 
+	newline := opts.LineEnding.String()
 	var builder strings.Builder
 
 	// Write Heading section
-	builder.WriteString(format.Heading + "\n")
+	builder.WriteString(format.Heading + newline)
 
 	// Write header fields
 	for _, field := range ale.HeaderFields {
-		builder.WriteString(fmt.Sprintf("%s\t%s\n", field.GetKey(), field.GetValue()))
+		if field == nil {
+			continue
+		}
+		key, err := sanitize(field.GetKey(), illegalInTable, opts.Sanitize)
+		if err != nil {
+			return "", errors.ErrOutputIllegalHeaderField.WithContext(fmt.Sprintf("key %q", field.GetKey()))
+		}
+		value, err := sanitize(field.GetValue(), illegalInHeaderValue, opts.Sanitize)
+		if err != nil {
+			return "", errors.ErrOutputIllegalHeaderField.WithContext(fmt.Sprintf("value of %q", field.GetKey()))
+		}
+		builder.WriteString(key + "\t" + value + newline)
 	}
-	builder.WriteString("\n")
+	builder.WriteString(newline)
 
 	// Write Column section
-	builder.WriteString(format.Column + "\n")
+	builder.WriteString(format.Column + newline)
 
 	// Write column names
 	columnNames := make([]string, len(ale.Columns))
 	for _, col := range ale.Columns {
-		columnNames[col.Order] = col.Name
+		name, err := sanitize(col.Name, illegalInTable, opts.Sanitize)
+		if err != nil {
+			return "", errors.ErrOutputIllegalColumnName.WithContext(fmt.Sprintf("column %q", col.Name))
+		}
+		columnNames[col.Order] = name
+	}
+	columnsLine := strings.Join(columnNames, "\t")
+	if isSectionBreak(columnsLine) {
+		return "", errors.ErrOutputUnreadableLine.WithContext(fmt.Sprintf("column line %q", columnsLine))
 	}
-	builder.WriteString(strings.Join(columnNames, "\t") + "\n\n")
+	builder.WriteString(columnsLine + newline + newline)
 
 	// Write Data section
-	builder.WriteString(format.Data + "\n")
+	builder.WriteString(format.Data + newline)
 
 	// Write rows
-	for _, row := range ale.Rows {
+	for i, row := range ale.Rows {
 		values := make([]string, len(ale.Columns))
 		for _, col := range ale.Columns {
-			if val, ok := row.ValueMap[col]; ok {
-				values[col.Order] = val.String()
+			if val, ok := row.ValueMap[col]; ok && val != nil {
+				value, err := sanitize(val.String(), illegalInTable, opts.Sanitize)
+				if err != nil {
+					return "", errors.ErrOutputIllegalValue.WithContext(fmt.Sprintf("row %d, column %q", i, col.Name))
+				}
+				values[col.Order] = value
 			}
 		}
-		builder.WriteString(strings.Join(values, "\t") + "\n")
+		line := strings.Join(values, "\t")
+		if isSectionBreak(line) {
+			return "", errors.ErrOutputUnreadableLine.WithContext(fmt.Sprintf("row %d %q", i, line))
+		}
+		builder.WriteString(line + newline)
+	}
+
+	if opts.TrailingBlankLine {
+		builder.WriteString(newline)
 	}
 
 	return builder.String(), nil
 }
+
+// Characters that cannot appear unescaped in each part of an ALE file.
+// Header values may contain tabs, which the reader turns into spaces.
+const (
+	illegalInTable       = "\t\r\n"
+	illegalInHeaderValue = "\r\n"
+)
+
+// errIllegalCharacter is returned by sanitize when SanitizeFail finds an illegal character.
+var errIllegalCharacter = &errors.Error{
+	Category: errors.CategoryOutput,
+	Message:  "illegal character",
+}
+
+// isSectionBreak reports whether a column or data line would be read as a
+// blank line or the Data section name rather than as table content, as a
+// single column holding an empty value or "Data" would. No character can be
+// escaped in an ALE, so such a line cannot be written.
+func isSectionBreak(line string) bool {
+	return line == "" || line == format.Data
+}
+
+// sanitize applies the sanitization mode to any of the illegal characters found in s.
+func sanitize(s, illegal string, mode Sanitize) (string, error) {
+	if !strings.ContainsAny(s, illegal) {
+		return s, nil
+	}
+	switch mode {
+	case SanitizeFail:
+		return "", errIllegalCharacter
+	case SanitizeStrip:
+		return strings.Map(func(r rune) rune {
+			if strings.ContainsRune(illegal, r) {
+				return -1
+			}
+			return r
+		}, s), nil
+	default:
+		// A CRLF pair becomes a single space rather than two
+		s = strings.ReplaceAll(s, "\r\n", " ")
+		return strings.Map(func(r rune) rune {
+			if strings.ContainsRune(illegal, r) {
+				return ' '
+			}
+			return r
+		}, s), nil
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"lib-post-interchange/libale/errors"
 	"lib-post-interchange/libale/types"
 )
 
@@ -276,3 +277,154 @@ func TestWriteNilObject(t *testing.T) {
 		t.Errorf("Expected error message to contain 'cannot write nil ALE object', got %q", err.Error())
 	}
 }
+
+func TestWriteWithOptions(t *testing.T) {
+	newObject := func(value string) *types.Object {
+		cols := []types.Column{{Name: "Name", Order: 0}, {Name: "Comments", Order: 1}}
+		return &types.Object{
+			HeaderFields: []types.Field{
+				types.FieldDelimiter{BaseField: types.BaseField{Key: "FIELD_DELIM", Value: "TABS"}},
+			},
+			Columns: cols,
+			Rows: []types.Row{
+				{
+					Columns: cols,
+					ValueMap: map[types.Column]types.Value{
+						cols[0]: types.StringValue{Column: cols[0], Value: "A001"},
+						cols[1]: types.StringValue{Column: cols[1], Value: value},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		opts    WriteOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "default",
+			value: "ok",
+			opts:  DefaultWriteOptions(),
+			want:  "Heading\nFIELD_DELIM\tTABS\n\nColumn\nName\tComments\n\nData\nA001\tok\n",
+		},
+		{
+			name:  "crlf with trailing blank line",
+			value: "ok",
+			opts:  WriteOptions{LineEnding: CRLF, TrailingBlankLine: true},
+			want:  "Heading\r\nFIELD_DELIM\tTABS\r\n\r\nColumn\r\nName\tComments\r\n\r\nData\r\nA001\tok\r\n\r\n",
+		},
+		{
+			name:  "replace illegal characters",
+			value: "line one\r\nline\ttwo\n",
+			opts:  WriteOptions{Sanitize: SanitizeReplace},
+			want:  "Heading\nFIELD_DELIM\tTABS\n\nColumn\nName\tComments\n\nData\nA001\tline one line two \n",
+		},
+		{
+			name:  "strip illegal characters",
+			value: "line one\nline\ttwo",
+			opts:  WriteOptions{Sanitize: SanitizeStrip},
+			want:  "Heading\nFIELD_DELIM\tTABS\n\nColumn\nName\tComments\n\nData\nA001\tline onelinetwo\n",
+		},
+		{
+			name:    "fail on illegal characters",
+			value:   "line one\nline two",
+			opts:    WriteOptions{Sanitize: SanitizeFail},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WriteWithOptions(newObject(tt.value), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.IsCategory(err, errors.CategoryOutput) {
+					t.Errorf("Expected output error, got %v", err)
+				}
+				if !strings.Contains(err.Error(), `row 0, column "Comments"`) {
+					t.Errorf("Expected error to name row and column, got %q", err.Error())
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("WriteWithOptions() output:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteUnreadableLines(t *testing.T) {
+	newObject := func(name, value string) *types.Object {
+		cols := []types.Column{{Name: name, Order: 0}}
+		return &types.Object{
+			Columns: cols,
+			Rows: []types.Row{{
+				Columns:  cols,
+				ValueMap: map[types.Column]types.Value{cols[0]: types.StringValue{Column: cols[0], Value: value}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		column string
+		value  string
+		opts   WriteOptions
+		want   string
+	}{
+		{name: "empty value", column: "Name", value: "", opts: DefaultWriteOptions(), want: "row 0"},
+		{name: "Data value", column: "Name", value: "Data", opts: DefaultWriteOptions(), want: "row 0"},
+		{name: "value stripped to nothing", column: "Name", value: "\r\n", opts: WriteOptions{Sanitize: SanitizeStrip}, want: "row 0"},
+		{name: "Data column", column: "Data", value: "A001", opts: DefaultWriteOptions(), want: "column line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WriteWithOptions(newObject(tt.column, tt.value), tt.opts)
+			if err == nil || !strings.HasPrefix(err.Error(), errors.ErrOutputUnreadableLine.Error()) {
+				t.Fatalf("WriteWithOptions() error = %v, want %v", err, errors.ErrOutputUnreadableLine)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error to name the %s, got %q", tt.want, err.Error())
+			}
+		})
+	}
+
+	// A one-column row with a value that reads back is still written
+	if _, err := WriteWithOptions(newObject("Name", "A001"), DefaultWriteOptions()); err != nil {
+		t.Errorf("WriteWithOptions() error = %v", err)
+	}
+}
+
+func TestWriteWithProjection(t *testing.T) {
+	obj, err := ReadFile("../../../samples/ALE/A901R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	columnCount := len(obj.Columns)
+
+	opts := DefaultWriteOptions()
+	opts.Projection = types.Projection{
+		Include: []string{"Start", "Name", "Reel_name"},
+		Rename:  map[string]string{"Reel_name": "Camroll"},
+	}
+	output, err := WriteWithOptions(obj, opts)
+	if err != nil {
+		t.Fatalf("WriteWithOptions() error = %v", err)
+	}
+	if !strings.Contains(output, "Column\nStart\tName\tCamroll\n") {
+		t.Errorf("Projected column line not found in output:\n%s", output)
+	}
+	if !strings.Contains(output, "\n00:22:14:12\tA901C001_240426_R1AA\tA901R1AA\n") {
+		t.Errorf("Projected row not found in output:\n%s", output)
+	}
+	if len(obj.Columns) != columnCount {
+		t.Errorf("Source object columns changed: got %d, want %d", len(obj.Columns), columnCount)
+	}
+}
@@ -0,0 +1,340 @@
+// Package csv converts ALE objects to and from CSV.
+package csv
+
+import (
+	"bytes"
+	stdcsv "encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/format"
+	"lib-post-interchange/libale/mapping"
+	"lib-post-interchange/libale/types"
+)
+
+// Quoting selects when CSV fields are quoted on output.
+type Quoting int
+
+const (
+	// QuoteMinimal quotes only fields that need it.
+	QuoteMinimal Quoting = iota
+	// QuoteAll quotes every field.
+	QuoteAll
+)
+
+// HeaderRow selects whether the first CSV record holds column names.
+type HeaderRow int
+
+const (
+	// HeaderRowAuto detects whether the first record is a header row when reading.
+	// When writing, a header row is always written.
+	HeaderRowAuto HeaderRow = iota
+	// HeaderRowPresent treats the first record as column names.
+	HeaderRowPresent
+	// HeaderRowAbsent treats every record as data.
+	HeaderRowAbsent
+)
+
+// HeaderFields selects how ALE header fields such as FPS are carried alongside CSV data.
+type HeaderFields int
+
+const (
+	// HeaderFieldsOmit drops header fields on output and ignores them on input.
+	HeaderFieldsOmit HeaderFields = iota
+	// HeaderFieldsComment carries header fields as "# KEY: VALUE" lines before the CSV records.
+	HeaderFieldsComment
+	// HeaderFieldsSidecar carries header fields in a JSON file next to the CSV file.
+	HeaderFieldsSidecar
+)
+
+// Options controls how ALE objects are converted to and from CSV.
+type Options struct {
+	// Delimiter separates fields. The zero value means a comma.
+	Delimiter rune
+	// Quoting selects when fields are quoted on output.
+	Quoting Quoting
+	// CRLF ends output records with "\r\n" instead of "\n".
+	CRLF bool
+	// HeaderRow selects whether the first record holds column names.
+	HeaderRow HeaderRow
+	// HeaderFields selects how ALE header fields are carried.
+	HeaderFields HeaderFields
+	// Projection selects, orders and renames columns. On input it applies to the CSV column names.
+	Projection types.Projection
+	// Profile, if set, maps columns after reading or before writing.
+	Profile *mapping.Profile
+}
+
+// DefaultOptions returns comma-delimited options with a header row and no header fields.
+func DefaultOptions() Options {
+	return Options{Delimiter: ','}
+}
+
+// commentPrefix starts a line carrying an ALE header field.
+const commentPrefix = "# "
+
+// WriteFile writes an ALE object to a CSV file. With HeaderFieldsSidecar,
+// the header fields are written to SidecarPath(filepath).
+func WriteFile(filepath string, obj *types.Object, opts Options) error {
+	data, err := Write(obj, opts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath, []byte(data), 0644); err != nil {
+		return err
+	}
+	if opts.HeaderFields == HeaderFieldsSidecar {
+		sidecar, err := WriteSidecar(obj)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(SidecarPath(filepath), sidecar, 0644)
+	}
+	return nil
+}
+
+// Write converts an ALE object to CSV. With HeaderFieldsSidecar, the header
+// fields are not included and should be written separately with WriteSidecar.
+func Write(obj *types.Object, opts Options) (string, error) {
+	if obj == nil {
+		return "", errors.ErrOutputNilObject
+	}
+	obj, err := transform(obj, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	newline := "\n"
+	if opts.CRLF {
+		newline = "\r\n"
+	}
+
+	if opts.HeaderFields == HeaderFieldsComment {
+		for _, field := range obj.HeaderFields {
+			if field == nil {
+				continue
+			}
+			buf.WriteString(commentPrefix + field.GetKey() + ": " + field.GetValue() + newline)
+		}
+	}
+
+	records := make([][]string, 0, len(obj.Rows)+1)
+	if opts.HeaderRow != HeaderRowAbsent {
+		records = append(records, obj.ColumnNames())
+	}
+	for _, row := range obj.Rows {
+		records = append(records, obj.Values(row))
+	}
+
+	if opts.Quoting == QuoteAll {
+		delimiter := string(delimiterOf(opts))
+		for _, record := range records {
+			quoted := make([]string, len(record))
+			for i, field := range record {
+				quoted[i] = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+			}
+			buf.WriteString(strings.Join(quoted, delimiter) + newline)
+		}
+		return buf.String(), nil
+	}
+
+	w := stdcsv.NewWriter(&buf)
+	w.Comma = delimiterOf(opts)
+	w.UseCRLF = opts.CRLF
+	if err := w.WriteAll(records); err != nil {
+		return "", errors.ErrOutputFailedCSV.WithContext(err.Error())
+	}
+	return buf.String(), nil
+}
+
+// ReadFile reads a CSV file into an ALE object. With HeaderFieldsSidecar,
+// header fields are read from SidecarPath(filepath) if it exists.
+func ReadFile(filepath string, opts Options) (*types.Object, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	var sidecarFields []types.Field
+	if opts.HeaderFields == HeaderFieldsSidecar {
+		sidecar, err := os.ReadFile(SidecarPath(filepath))
+		switch {
+		case err == nil:
+			if sidecarFields, err = ReadSidecar(sidecar); err != nil {
+				return nil, err
+			}
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	return read(string(data), opts, sidecarFields)
+}
+
+// Read parses CSV data into an ALE object. A FIELD_DELIM header field is
+// always present on the result so it can be written as an ALE.
+func Read(input string, opts Options) (*types.Object, error) {
+	return read(input, opts, nil)
+}
+
+// read parses CSV data as Read does, adding header fields read from a
+// sidecar file.
+func read(input string, opts Options, sidecarFields []types.Field) (*types.Object, error) {
+	var headerFields []types.Field
+
+	// Spreadsheet applications often prefix CSV files with a byte order mark
+	input = strings.TrimPrefix(input, "\ufeff")
+
+	// Leading comment lines may carry header fields
+	for opts.HeaderFields == HeaderFieldsComment && strings.HasPrefix(input, commentPrefix) {
+		line, rest, _ := strings.Cut(input, "\n")
+		input = rest
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimRight(line, "\r"), "#"))
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			headerFields = append(headerFields, types.BaseField{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+		}
+	}
+
+	r := stdcsv.NewReader(strings.NewReader(input))
+	r.Comma = delimiterOf(opts)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.ErrInputFailedCSV.WithContext(err.Error())
+	}
+	if len(records) == 0 {
+		return nil, errors.ErrInputEmpty.WithContext("no CSV records")
+	}
+
+	var columns []string
+	hasHeader := opts.HeaderRow == HeaderRowPresent ||
+		(opts.HeaderRow == HeaderRowAuto && IsHeaderRow(records[0]))
+	if hasHeader {
+		columns = records[0]
+		records = records[1:]
+	} else {
+		width := 0
+		for _, record := range records {
+			width = max(width, len(record))
+		}
+		for i := 0; i < width; i++ {
+			columns = append(columns, fmt.Sprintf("Column %d", i+1))
+		}
+	}
+
+	headerFields = mergeHeaderFields([]types.Field{format.DelimiterTab}, headerFields)
+	headerFields = mergeHeaderFields(headerFields, sidecarFields)
+	obj := types.NewObject(headerFields, columns, records)
+	if err := obj.ValidateColumns(); err != nil {
+		return nil, errors.ErrInputFailedColumns.WithContext(err.Error())
+	}
+	return transform(obj, opts)
+}
+
+// transform applies the projection and mapping profile in the options.
+func transform(obj *types.Object, opts Options) (*types.Object, error) {
+	var err error
+	if !opts.Projection.IsZero() {
+		if obj, err = obj.Project(opts.Projection); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Profile != nil {
+		if obj, err = mapping.Apply(obj, opts.Profile); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// looksLikeData matches values that are unlikely to be column names:
+// numbers, timecodes and dates.
+var looksLikeData = regexp.MustCompile(`^[-+]?[\d.,:;/]+$`)
+
+// IsHeaderRow reports whether a CSV record looks like a row of column names:
+// every field is non-empty, no name repeats and none looks like a number,
+// timecode or date.
+func IsHeaderRow(record []string) bool {
+	seen := make(map[string]bool, len(record))
+	for _, field := range record {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] || looksLikeData.MatchString(field) {
+			return false
+		}
+		seen[field] = true
+	}
+	return len(record) > 0
+}
+
+// SidecarPath returns the path of the header field sidecar for a CSV file.
+func SidecarPath(csvPath string) string {
+	return strings.TrimSuffix(csvPath, filepath.Ext(csvPath)) + ".header.json"
+}
+
+// sidecarField is the JSON form of a header field, matching types.Object's JSON output.
+type sidecarField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// WriteSidecar encodes an object's header fields as JSON.
+func WriteSidecar(obj *types.Object) ([]byte, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	fields := make([]sidecarField, 0, len(obj.HeaderFields))
+	for _, field := range obj.HeaderFields {
+		if field != nil {
+			fields = append(fields, sidecarField{Key: field.GetKey(), Value: field.GetValue()})
+		}
+	}
+	data, err := json.MarshalIndent(map[string][]sidecarField{"header_fields": fields}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ReadSidecar decodes header fields written by WriteSidecar.
+func ReadSidecar(data []byte) ([]types.Field, error) {
+	var sidecar map[string][]sidecarField
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return nil, errors.ErrInputFailedHeading.WithContext(err.Error())
+	}
+	fields := make([]types.Field, 0, len(sidecar["header_fields"]))
+	for _, field := range sidecar["header_fields"] {
+		fields = append(fields, types.BaseField{Key: field.Key, Value: field.Value})
+	}
+	return fields, nil
+}
+
+// mergeHeaderFields returns base with each of fields replacing any field of the same key or appended.
+func mergeHeaderFields(base, fields []types.Field) []types.Field {
+	merged := append([]types.Field(nil), base...)
+	for _, field := range fields {
+		replaced := false
+		for i, existing := range merged {
+			if existing != nil && existing.GetKey() == field.GetKey() {
+				merged[i] = field
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
+// delimiterOf returns the delimiter in the options, defaulting to a comma.
+func delimiterOf(opts Options) rune {
+	if opts.Delimiter == 0 {
+		return ','
+	}
+	return opts.Delimiter
+}
@@ -0,0 +1,146 @@
+package csv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/types"
+)
+
+func TestWriteRead(t *testing.T) {
+	obj, err := ale.ReadFile("../../../samples/ALE/A001R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "default", opts: DefaultOptions()},
+		{name: "semicolon quote all crlf", opts: Options{Delimiter: ';', Quoting: QuoteAll, CRLF: true}},
+		{name: "comment header fields", opts: Options{HeaderFields: HeaderFieldsComment}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Write(obj, tt.opts)
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			got, err := Read(data, tt.opts)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.ColumnNames(), obj.ColumnNames()) {
+				t.Errorf("Columns = %v, want %v", got.ColumnNames(), obj.ColumnNames())
+			}
+			if len(got.Rows) != len(obj.Rows) {
+				t.Fatalf("Got %d rows, want %d", len(got.Rows), len(obj.Rows))
+			}
+			for i := range obj.Rows {
+				if !reflect.DeepEqual(got.Values(got.Rows[i]), obj.Values(obj.Rows[i])) {
+					t.Errorf("Row %d values differ", i)
+				}
+			}
+			if got.FieldDelimiter.GetValue() != "TABS" {
+				t.Errorf("FieldDelimiter = %q, want TABS", got.FieldDelimiter.GetValue())
+			}
+			if tt.opts.HeaderFields == HeaderFieldsComment && got.FPS.GetValue() != "25" {
+				t.Errorf("FPS = %q, want 25", got.FPS.GetValue())
+			}
+		})
+	}
+}
+
+func TestReadHeaderRowDetection(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		opts        Options
+		wantColumns []string
+		wantRows    int
+	}{
+		{
+			name:        "header row detected",
+			input:       "Name,Start,End\nA001,01:00:00:00,01:00:10:00\n",
+			wantColumns: []string{"Name", "Start", "End"},
+			wantRows:    1,
+		},
+		{
+			name:        "no header row",
+			input:       "A001,01:00:00:00,01:00:10:00\nA002,01:00:10:00,01:00:20:00\n",
+			wantColumns: []string{"Column 1", "Column 2", "Column 3"},
+			wantRows:    2,
+		},
+		{
+			name:        "header row forced absent",
+			input:       "Name,Start,End\n",
+			opts:        Options{HeaderRow: HeaderRowAbsent},
+			wantColumns: []string{"Column 1", "Column 2", "Column 3"},
+			wantRows:    1,
+		},
+		{
+			name:        "tab delimited with mapping",
+			input:       "Name\tReel_name\nA001C001\tA001R1AA\n",
+			opts:        Options{Delimiter: '\t', Projection: types.Projection{Rename: map[string]string{"Reel_name": "Camroll"}}},
+			wantColumns: []string{"Name", "Camroll"},
+			wantRows:    1,
+		},
+		{
+			name:        "hash lines are data without comment header fields",
+			input:       "# Shot,Note\n# 12,wide\n",
+			wantColumns: []string{"# Shot", "Note"},
+			wantRows:    1,
+		},
+		{
+			name:        "hash lines are header fields in comment mode",
+			input:       "# FPS: 25\nName,Start\nA001,01:00:00:00\n",
+			opts:        Options{HeaderFields: HeaderFieldsComment},
+			wantColumns: []string{"Name", "Start"},
+			wantRows:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.ColumnNames(), tt.wantColumns) {
+				t.Errorf("Columns = %v, want %v", got.ColumnNames(), tt.wantColumns)
+			}
+			if len(got.Rows) != tt.wantRows {
+				t.Errorf("Got %d rows, want %d", len(got.Rows), tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestSidecar(t *testing.T) {
+	obj, err := ale.ReadFile("../../../samples/ALE/A001R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "clips.csv")
+	opts := Options{HeaderFields: HeaderFieldsSidecar}
+	if err := WriteFile(path, obj, opts); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "clips.header.json")); err != nil {
+		t.Fatalf("Sidecar not written: %v", err)
+	}
+
+	got, err := ReadFile(path, opts)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got.FPS.GetValue() != "25" || got.VideoFormat.GetValue() != "CUSTOM" {
+		t.Errorf("Header fields not restored: FPS = %q, VIDEO_FORMAT = %q", got.FPS.GetValue(), got.VideoFormat.GetValue())
+	}
+}
@@ -87,6 +87,50 @@ var (
 		Category: CategoryInput,
 		Message:  "row has mismatched column count",
 	}
+	ErrInputFailedCSV = &Error{
+		Category: CategoryInput,
+		Message:  "failed to parse CSV",
+	}
+	ErrInputFailedXLSX = &Error{
+		Category: CategoryInput,
+		Message:  "failed to read XLSX workbook",
+	}
+	ErrInputMalformedProfile = &Error{
+		Category: CategoryInput,
+		Message:  "malformed mapping profile",
+	}
+	ErrInputUnknownProfile = &Error{
+		Category: CategoryInput,
+		Message:  "unknown mapping profile",
+	}
+	ErrInputMissingValue = &Error{
+		Category: CategoryInput,
+		Message:  "missing value",
+	}
+	ErrInputInvalidTimecode = &Error{
+		Category: CategoryInput,
+		Message:  "invalid timecode",
+	}
+	ErrInputInvalidFrameRate = &Error{
+		Category: CategoryInput,
+		Message:  "invalid frame rate",
+	}
+	ErrInputInvalidCDL = &Error{
+		Category: CategoryInput,
+		Message:  "invalid ASC CDL value",
+	}
+	ErrInputFailedOTIO = &Error{
+		Category: CategoryInput,
+		Message:  "failed to read OpenTimelineIO timeline",
+	}
+	ErrInputInvalidFilmFormat = &Error{
+		Category: CategoryInput,
+		Message:  "invalid film format",
+	}
+	ErrInputInvalidPulldown = &Error{
+		Category: CategoryInput,
+		Message:  "invalid pulldown phase",
+	}
 
 	// Output errors
 	ErrOutputNilObject = &Error{
@@ -109,6 +153,50 @@ var (
 		Category: CategoryOutput,
 		Message:  "row value map cannot be nil",
 	}
+	ErrOutputIllegalValue = &Error{
+		Category: CategoryOutput,
+		Message:  "value contains illegal characters",
+	}
+	ErrOutputIllegalColumnName = &Error{
+		Category: CategoryOutput,
+		Message:  "column name contains illegal characters",
+	}
+	ErrOutputIllegalHeaderField = &Error{
+		Category: CategoryOutput,
+		Message:  "header field contains illegal characters",
+	}
+	ErrOutputUnreadableLine = &Error{
+		Category: CategoryOutput,
+		Message:  "line would be read as a blank line or section name",
+	}
+	ErrOutputUnknownColumn = &Error{
+		Category: CategoryOutput,
+		Message:  "unknown column",
+	}
+	ErrOutputDuplicateColumn = &Error{
+		Category: CategoryOutput,
+		Message:  "duplicate column name",
+	}
+	ErrOutputFailedCSV = &Error{
+		Category: CategoryOutput,
+		Message:  "failed to write CSV",
+	}
+	ErrOutputFailedXLSX = &Error{
+		Category: CategoryOutput,
+		Message:  "failed to write XLSX workbook",
+	}
+	ErrOutputFileExists = &Error{
+		Category: CategoryOutput,
+		Message:  "refusing to overwrite existing file",
+	}
+	ErrOutputFailedWrite = &Error{
+		Category: CategoryOutput,
+		Message:  "failed to write file",
+	}
+	ErrOutputFailedBackup = &Error{
+		Category: CategoryOutput,
+		Message:  "failed to back up existing file",
+	}
 )
 
 // IsCategory checks if an error belongs to a specific category
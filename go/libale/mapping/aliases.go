@@ -0,0 +1,74 @@
+package mapping
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Aliases maps canonical column names to alternative names for the same
+// column. All comparisons are case-insensitive. A name may belong to only
+// one canonical column, which Validate checks.
+type Aliases map[string][]string
+
+// Validate checks that no name, ignoring case, is given for two canonical
+// columns, so that every name resolves to one column.
+func (a Aliases) Validate() error {
+	owners := make(map[string]string)
+	for _, canonical := range a.canonicalNames() {
+		for _, name := range append([]string{canonical}, a[canonical]...) {
+			key := strings.ToLower(name)
+			if owner, ok := owners[key]; ok && owner != canonical {
+				return fmt.Errorf("%q is a name of both %q and %q", name, owner, canonical)
+			}
+			owners[key] = canonical
+		}
+	}
+	return nil
+}
+
+// Resolve returns the canonical name for a column name or any of its aliases,
+// ignoring case. Names that are not known are returned unchanged. Aliases
+// that pass Validate give each name to at most one canonical column.
+func (a Aliases) Resolve(name string) string {
+	for _, canonical := range a.canonicalNames() {
+		aliases := a[canonical]
+		if strings.EqualFold(canonical, name) {
+			return canonical
+		}
+		for _, alias := range aliases {
+			if strings.EqualFold(alias, name) {
+				return canonical
+			}
+		}
+	}
+	return name
+}
+
+// Find returns the first of columns that refers to name: the column named
+// name itself, ignoring case, or one named as an alias of the same canonical
+// column.
+func (a Aliases) Find(columns []string, name string) (string, bool) {
+	for _, col := range columns {
+		if strings.EqualFold(col, name) {
+			return col, true
+		}
+	}
+	canonical := a.Resolve(name)
+	for _, col := range columns {
+		if strings.EqualFold(a.Resolve(col), canonical) {
+			return col, true
+		}
+	}
+	return "", false
+}
+
+// canonicalNames returns the canonical names in sorted order.
+func (a Aliases) canonicalNames() []string {
+	names := make([]string, 0, len(a))
+	for canonical := range a {
+		names = append(names, canonical)
+	}
+	sort.Strings(names)
+	return names
+}
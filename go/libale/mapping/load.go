@@ -0,0 +1,101 @@
+package mapping
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+)
+
+// builtinProfiles holds the profiles shipped with the library.
+//
+//go:embed profiles/*.json
+var builtinProfiles embed.FS
+
+// LoadFile reads a mapping profile from a JSON or YAML file.
+// The format is chosen by the file extension, falling back to the content.
+func LoadFile(filepath string) (*Profile, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(path.Ext(filepath)) {
+	case ".json":
+		return LoadJSON(data)
+	case ".yaml", ".yml":
+		return LoadYAML(data)
+	}
+	return Load(data)
+}
+
+// Load parses a mapping profile, detecting whether it is JSON or YAML.
+func Load(data []byte) (*Profile, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return LoadJSON(data)
+	}
+	return LoadYAML(data)
+}
+
+// LoadJSON parses a mapping profile from JSON.
+func LoadJSON(data []byte) (*Profile, error) {
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, errors.ErrInputMalformedProfile.WithContext(err.Error())
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// LoadYAML parses a mapping profile from YAML. Profiles only hold names, so
+// only this subset of YAML is read:
+//
+//   - block mappings of "key: value", indented with spaces
+//   - block sequences of "- item", which may sit at the indent of their key
+//   - flow sequences and mappings on one line, such as [A, "B"] or {a: b}
+//   - plain, single-quoted and double-quoted scalars, all read as strings;
+//     double quotes understand the \n, \t, \" and \\ escapes
+//   - comments starting with "#", and a "---" before the document
+//
+// Anchors and aliases, tags, block scalars (| and >), explicit keys, flow
+// collections spanning lines, several documents and tab indentation are
+// errors.
+func LoadYAML(data []byte) (*Profile, error) {
+	value, err := decodeYAML(string(data))
+	if err != nil {
+		return nil, errors.ErrInputMalformedProfile.WithContext(err.Error())
+	}
+	// Reuse the JSON field definitions by converting the decoded YAML
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.ErrInputMalformedProfile.WithContext(err.Error())
+	}
+	return LoadJSON(encoded)
+}
+
+// Builtin returns the built-in profile with the given name, ignoring case.
+func Builtin(name string) (*Profile, error) {
+	data, err := builtinProfiles.ReadFile("profiles/" + strings.ToLower(name) + ".json")
+	if err != nil {
+		return nil, errors.ErrInputUnknownProfile.WithContext(name)
+	}
+	return LoadJSON(data)
+}
+
+// BuiltinNames returns the names of the built-in profiles.
+func BuiltinNames() []string {
+	entries, _ := builtinProfiles.ReadDir("profiles")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,345 @@
+// Package mapping renames, merges, splits and drops ALE columns according to
+// reusable mapping profiles, such as those converting camera ALEs to
+// Avid-standard column names.
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+)
+
+// Action is the operation performed by a mapping rule.
+type Action string
+
+// Rule actions
+const (
+	// ActionRename renames a single column.
+	ActionRename Action = "rename"
+	// ActionMerge joins several columns into one, separated by Separator.
+	ActionMerge Action = "merge"
+	// ActionSplit splits one column into several at Separator.
+	ActionSplit Action = "split"
+	// ActionDrop removes columns.
+	ActionDrop Action = "drop"
+)
+
+// Names is a list of column names. In a profile it may be written as a
+// single string or as a list of strings.
+type Names []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *Names) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*n = Names{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a column name or list of column names")
+	}
+	*n = Names(list)
+	return nil
+}
+
+// Rule is a single column mapping operation.
+type Rule struct {
+	Action    Action `json:"action"`
+	From      Names  `json:"from"`
+	To        Names  `json:"to,omitempty"`
+	Separator string `json:"separator,omitempty"`
+}
+
+// Profile is a named, ordered set of mapping rules.
+type Profile struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Aliases     Aliases `json:"aliases,omitempty"`
+	Rules       []Rule  `json:"rules"`
+}
+
+// Validate checks that every rule in the profile is well formed and that no
+// alias belongs to two canonical columns.
+func (p *Profile) Validate() error {
+	if p == nil {
+		return errors.ErrInputMalformedProfile.WithContext("nil profile")
+	}
+	if err := p.Aliases.Validate(); err != nil {
+		return errors.ErrInputMalformedProfile.WithContext(fmt.Sprintf("aliases: %v", err))
+	}
+	for i, rule := range p.Rules {
+		if err := rule.validate(); err != nil {
+			return errors.ErrInputMalformedProfile.WithContext(fmt.Sprintf("rule %d: %v", i, err))
+		}
+	}
+	return nil
+}
+
+// validate checks the number of source and target columns for the rule's action.
+func (r Rule) validate() error {
+	switch r.Action {
+	case ActionRename:
+		if len(r.From) != 1 || len(r.To) != 1 {
+			return fmt.Errorf("rename needs one source and one target column")
+		}
+	case ActionMerge:
+		if len(r.From) < 1 || len(r.To) != 1 {
+			return fmt.Errorf("merge needs source columns and one target column")
+		}
+	case ActionSplit:
+		if len(r.From) != 1 || len(r.To) < 1 {
+			return fmt.Errorf("split needs one source column and target columns")
+		}
+	case ActionDrop:
+		if len(r.From) < 1 || len(r.To) != 0 {
+			return fmt.Errorf("drop needs source columns and no target columns")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+	for _, name := range append(append(Names{}, r.From...), r.To...) {
+		if name == "" {
+			return fmt.Errorf("empty column name")
+		}
+	}
+	return nil
+}
+
+// Apply returns a copy of the Object with the profile's rules applied in order.
+// Source columns are found case-insensitively, through the profile's aliases
+// if needed. Rules whose source columns are not present are skipped, so one
+// profile can serve ALEs with differing column sets. A nil or invalid profile
+// is an error. The source Object is not modified.
+func Apply(obj *types.Object, profile *Profile) (*types.Object, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	t := newTable(obj)
+	for _, rule := range profile.Rules {
+		switch rule.Action {
+		case ActionRename:
+			from := t.find(profile.Aliases, rule.From[0])
+			if from < 0 {
+				continue
+			}
+			if to := t.index(rule.To[0]); to >= 0 && to != from {
+				// The target already exists: fill its blanks and drop the source
+				for _, row := range t.rows {
+					if row[to] == "" {
+						row[to] = row[from]
+					}
+				}
+				t.remove(from)
+				continue
+			}
+			t.names[from] = rule.To[0]
+		case ActionMerge:
+			var sources []int
+			for _, name := range rule.From {
+				if i := t.find(profile.Aliases, name); i >= 0 {
+					sources = append(sources, i)
+				}
+			}
+			if len(sources) == 0 {
+				continue
+			}
+			separator := rule.Separator
+			if separator == "" {
+				separator = " "
+			}
+			merged := make([]string, len(t.rows))
+			for r, row := range t.rows {
+				var parts []string
+				for _, i := range sources {
+					if row[i] != "" {
+						parts = append(parts, row[i])
+					}
+				}
+				merged[r] = strings.Join(parts, separator)
+			}
+			at := t.replace(sources, rule.To)
+			for r, row := range t.rows {
+				row[at] = merged[r]
+			}
+		case ActionSplit:
+			from := t.find(profile.Aliases, rule.From[0])
+			if from < 0 {
+				continue
+			}
+			separator := rule.Separator
+			if separator == "" {
+				separator = " "
+			}
+			parts := make([][]string, len(t.rows))
+			for r, row := range t.rows {
+				parts[r] = strings.SplitN(row[from], separator, len(rule.To))
+			}
+			at := t.replace([]int{from}, rule.To)
+			for r, row := range t.rows {
+				for i := range rule.To {
+					if i < len(parts[r]) {
+						row[at+i] = strings.TrimSpace(parts[r][i])
+					}
+				}
+			}
+		case ActionDrop:
+			for _, name := range rule.From {
+				if i := t.find(profile.Aliases, name); i >= 0 {
+					t.remove(i)
+				}
+			}
+		}
+	}
+
+	return t.object(obj)
+}
+
+// table is a mutable, column-ordered copy of an Object's data.
+type table struct {
+	names []string
+	rows  [][]string
+	order []int
+}
+
+// newTable copies an Object's columns and values in column output order.
+func newTable(obj *types.Object) *table {
+	columns := make([]types.Column, len(obj.Columns))
+	for _, col := range obj.Columns {
+		if col.Order >= 0 && col.Order < len(columns) {
+			columns[col.Order] = col
+		}
+	}
+	t := &table{names: make([]string, len(columns))}
+	for i, col := range columns {
+		t.names[i] = col.Name
+	}
+	for _, row := range obj.Rows {
+		values := make([]string, len(columns))
+		for i, col := range columns {
+			if val, ok := row.ValueMap[col]; ok && val != nil {
+				values[i] = val.String()
+			}
+		}
+		t.rows = append(t.rows, values)
+		t.order = append(t.order, row.Order)
+	}
+	return t
+}
+
+// index returns the position of the column with exactly this name, or -1.
+func (t *table) index(name string) int {
+	for i, n := range t.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// find returns the position of the column matching name or one of its aliases, or -1.
+func (t *table) find(aliases Aliases, name string) int {
+	if i := t.index(name); i >= 0 {
+		return i
+	}
+	if col, ok := aliases.Find(t.names, name); ok {
+		return t.index(col)
+	}
+	return -1
+}
+
+// remove deletes the column at position i.
+func (t *table) remove(i int) {
+	t.names = append(t.names[:i], t.names[i+1:]...)
+	for r, row := range t.rows {
+		t.rows[r] = append(row[:i], row[i+1:]...)
+	}
+}
+
+// replace removes the source columns and inserts empty target columns where
+// the first source was, returning the position of the first target.
+func (t *table) replace(sources []int, targets []string) int {
+	at := sources[0]
+	for _, i := range sources {
+		at = min(at, i)
+	}
+	removed := make(map[int]bool, len(sources))
+	for _, i := range sources {
+		removed[i] = true
+	}
+	// Targets replace any existing columns of the same name
+	for i, name := range t.names {
+		for _, target := range targets {
+			if name == target {
+				removed[i] = true
+			}
+		}
+	}
+
+	var names []string
+	rows := make([][]string, len(t.rows))
+	insert := func() {
+		names = append(names, targets...)
+		for r := range rows {
+			rows[r] = append(rows[r], make([]string, len(targets))...)
+		}
+	}
+	inserted := false
+	position := 0
+	for i, name := range t.names {
+		if i == at {
+			position = len(names)
+			insert()
+			inserted = true
+		}
+		if removed[i] {
+			continue
+		}
+		names = append(names, name)
+		for r, row := range t.rows {
+			rows[r] = append(rows[r], row[i])
+		}
+	}
+	if !inserted {
+		position = len(names)
+		insert()
+	}
+	t.names = names
+	t.rows = rows
+	return position
+}
+
+// object builds a new Object from the table, keeping the source's header.
+func (t *table) object(source *types.Object) (*types.Object, error) {
+	columns := make([]types.Column, len(t.names))
+	seen := make(map[string]bool, len(t.names))
+	for i, name := range t.names {
+		if seen[name] {
+			return nil, errors.ErrOutputDuplicateColumn.WithContext(name)
+		}
+		seen[name] = true
+		columns[i] = types.Column{Name: name, Order: i}
+	}
+
+	rows := make([]types.Row, len(t.rows))
+	for r, values := range t.rows {
+		valueMap := make(map[types.Column]types.Value, len(columns))
+		for i, col := range columns {
+			valueMap[col] = types.StringValue{Column: col, Value: values[i]}
+		}
+		rows[r] = types.Row{Columns: columns, ValueMap: valueMap, Order: t.order[r]}
+	}
+
+	obj := *source
+	obj.HeaderFields = append([]types.Field(nil), source.HeaderFields...)
+	obj.Columns = columns
+	obj.Rows = rows
+	return &obj, nil
+}
@@ -0,0 +1,266 @@
+package mapping
+
+import (
+	"reflect"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+)
+
+const testALE = `Heading
+FIELD_DELIM	TABS
+FPS	25
+
+Column
+Name	reel_name	Scene Take	Look_name	Uuid
+
+Data
+A001C001	A001R1AA	12-3	ARRI 709	1234
+`
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     Profile
+		wantColumns []string
+		wantValues  []string
+	}{
+		{
+			name: "rename case-insensitively",
+			profile: Profile{Rules: []Rule{
+				{Action: ActionRename, From: Names{"Reel_name"}, To: Names{"Camroll"}},
+			}},
+			wantColumns: []string{"Name", "Camroll", "Scene Take", "Look_name", "Uuid"},
+			wantValues:  []string{"A001C001", "A001R1AA", "12-3", "ARRI 709", "1234"},
+		},
+		{
+			name: "rename through alias",
+			profile: Profile{
+				Aliases: Aliases{"LUT": {"Look_name", "Look Name"}},
+				Rules: []Rule{
+					{Action: ActionRename, From: Names{"LUT"}, To: Names{"LUT"}},
+				},
+			},
+			wantColumns: []string{"Name", "reel_name", "Scene Take", "LUT", "Uuid"},
+			wantValues:  []string{"A001C001", "A001R1AA", "12-3", "ARRI 709", "1234"},
+		},
+		{
+			name: "split and drop",
+			profile: Profile{Rules: []Rule{
+				{Action: ActionSplit, From: Names{"Scene Take"}, To: Names{"Scene", "Take"}, Separator: "-"},
+				{Action: ActionDrop, From: Names{"Uuid", "Missing"}},
+			}},
+			wantColumns: []string{"Name", "reel_name", "Scene", "Take", "Look_name"},
+			wantValues:  []string{"A001C001", "A001R1AA", "12", "3", "ARRI 709"},
+		},
+		{
+			name: "merge",
+			profile: Profile{Rules: []Rule{
+				{Action: ActionMerge, From: Names{"Reel_name", "Name"}, To: Names{"Clip"}, Separator: "/"},
+			}},
+			wantColumns: []string{"Clip", "Scene Take", "Look_name", "Uuid"},
+			wantValues:  []string{"A001R1AA/A001C001", "12-3", "ARRI 709", "1234"},
+		},
+		{
+			name: "missing source column is skipped",
+			profile: Profile{Rules: []Rule{
+				{Action: ActionRename, From: Names{"Tape"}, To: Names{"Camroll"}},
+			}},
+			wantColumns: []string{"Name", "reel_name", "Scene Take", "Look_name", "Uuid"},
+			wantValues:  []string{"A001C001", "A001R1AA", "12-3", "ARRI 709", "1234"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := ale.Read(testALE)
+			if err != nil {
+				t.Fatalf("Failed to read ALE: %v", err)
+			}
+			got, err := Apply(obj, &tt.profile)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			columns, values := firstRow(got)
+			if !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("Apply() columns = %q, want %q", columns, tt.wantColumns)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Apply() values = %q, want %q", values, tt.wantValues)
+			}
+			if len(obj.Columns) != 5 || obj.Columns[1].Name != "reel_name" {
+				t.Error("Apply() modified the source object")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	jsonProfile := `{
+    "name": "custom",
+    "aliases": {"Camroll": ["Reel_name", "Reel"]},
+    "rules": [
+        {"action": "rename", "from": "Reel_name", "to": "Camroll"},
+        {"action": "drop", "from": ["Uuid", "Sup_version"]}
+    ]
+}`
+	yamlProfile := `# Custom profile
+name: custom
+aliases:
+  Camroll: [Reel_name, "Reel"]
+rules:
+  - action: rename
+    from: Reel_name
+    to: Camroll
+  - action: drop
+    from:
+      - Uuid
+      - 'Sup_version'
+`
+	want := &Profile{
+		Name:    "custom",
+		Aliases: Aliases{"Camroll": {"Reel_name", "Reel"}},
+		Rules: []Rule{
+			{Action: ActionRename, From: Names{"Reel_name"}, To: Names{"Camroll"}},
+			{Action: ActionDrop, From: Names{"Uuid", "Sup_version"}},
+		},
+	}
+
+	for name, data := range map[string]string{"json": jsonProfile, "yaml": yamlProfile} {
+		t.Run(name, func(t *testing.T) {
+			got, err := Load([]byte(data))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("invalid rule", func(t *testing.T) {
+		if _, err := Load([]byte("rules:\n  - action: rename\n    from: [A, B]\n    to: C\n")); err == nil {
+			t.Error("Expected error for rename with two source columns")
+		}
+	})
+
+	t.Run("alias of two columns", func(t *testing.T) {
+		data := `{"aliases": {"Camroll": ["Reel"], "Tape": ["reel"]}, "rules": []}`
+		if _, err := Load([]byte(data)); !errors.IsCategory(err, errors.CategoryInput) {
+			t.Errorf("Load() error = %v, want input error", err)
+		}
+	})
+}
+
+func TestLoadYAMLErrors(t *testing.T) {
+	tests := map[string]string{
+		"tab indentation":      "rules:\n\t- action: drop\n",
+		"unexpected indent":    "name: custom\n    description: x\n",
+		"duplicate key":        "name: a\nname: b\n",
+		"not a key":            "name: a\njust text\n",
+		"unclosed flow":        "aliases:\n  Camroll: [Reel, Tape\n",
+		"flow across lines":    "aliases:\n  Camroll: [Reel,\n    Tape]\n",
+		"unterminated quote":   "name: \"custom\n",
+		"trailing text":        "aliases: {Camroll: [Reel]} extra\n",
+		"anchor":               "name: &n custom\n",
+		"alias":                "name: *n\n",
+		"tag":                  "name: !!str custom\n",
+		"block scalar":         "description: |\n  text\n",
+		"explicit key":         "? name\n: custom\n",
+		"second document":      "name: a\n---\nname: b\n",
+		"wrong type for rules": "rules: drop\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadYAML([]byte(data)); !errors.IsCategory(err, errors.CategoryInput) {
+				t.Errorf("LoadYAML() error = %v, want input error", err)
+			}
+		})
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	names := BuiltinNames()
+	if !reflect.DeepEqual(names, []string{"arri", "red", "sony"}) {
+		t.Errorf("BuiltinNames() = %v", names)
+	}
+	for _, name := range names {
+		if _, err := Builtin(name); err != nil {
+			t.Errorf("Builtin(%q) error = %v", name, err)
+		}
+	}
+	if _, err := Builtin("unknown"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+
+	// The ARRI profile converts the sample camera ALE
+	obj, err := ale.ReadFile("../../../samples/ALE/A901R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	profile, _ := Builtin("ARRI")
+	got, err := Apply(obj, profile)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	columns, values := firstRow(got)
+	want := map[string]string{"Camroll": "A901R1AA", "LUT": "ARRI 709.AML", "Camera": "A"}
+	for i, col := range columns {
+		if v, ok := want[col]; ok && values[i] != v {
+			t.Errorf("Column %q = %q, want %q", col, values[i], v)
+		}
+		delete(want, col)
+	}
+	if len(want) > 0 {
+		t.Errorf("Missing columns after mapping: %v", want)
+	}
+}
+
+func TestApplyNilProfile(t *testing.T) {
+	obj, err := ale.Read(testALE)
+	if err != nil {
+		t.Fatalf("Failed to read ALE: %v", err)
+	}
+	if _, err := Apply(obj, nil); !errors.IsCategory(err, errors.CategoryInput) {
+		t.Errorf("Apply(nil) error = %v, want input error", err)
+	}
+}
+
+func TestAliasesResolve(t *testing.T) {
+	aliases := Aliases{"Camroll": {"Reel_name", "Reel"}}
+	tests := map[string]string{
+		"reel_name": "Camroll",
+		"REEL":      "Camroll",
+		"camroll":   "Camroll",
+		"Scene":     "Scene",
+	}
+	for name, want := range tests {
+		if got := aliases.Resolve(name); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	// A name given for two columns, which Validate rejects, resolves to the
+	// first in sorted order every time
+	ambiguous := Aliases{"Tape": {"Reel"}, "Camroll": {"Reel"}}
+	if err := ambiguous.Validate(); err == nil {
+		t.Error("Validate() accepted an alias of two columns")
+	}
+	for range 20 {
+		if got := ambiguous.Resolve("reel"); got != "Camroll" {
+			t.Fatalf("Resolve(%q) = %q, want %q", "reel", got, "Camroll")
+		}
+	}
+}
+
+// firstRow returns the column names and first row values of an object in output order.
+func firstRow(obj *types.Object) ([]string, []string) {
+	var columns, values []string
+	for _, col := range obj.Columns {
+		columns = append(columns, col.Name)
+		values = append(values, obj.Rows[0].ValueMap[col].String())
+	}
+	return columns, values
+}
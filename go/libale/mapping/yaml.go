@@ -0,0 +1,385 @@
+package mapping
+
+import (
+	"fmt"
+	"strings"
+)
+
+// decodeYAML decodes the subset of YAML used by mapping profiles, described
+// at LoadYAML, into the same generic values encoding/json produces:
+// map[string]interface{}, []interface{}, string and nil. All scalars are
+// decoded as strings, since every profile value is a name. YAML outside the
+// subset is an error rather than being read differently.
+func decodeYAML(data string) (interface{}, error) {
+	var lines []yamlLine
+	for number, text := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		text = stripComment(text)
+		content := strings.TrimLeft(text, " ")
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		if content == "---" || content == "..." {
+			if len(lines) > 0 || content == "..." {
+				return nil, fmt.Errorf("line %d: only one document is supported", number+1)
+			}
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", number+1)
+		}
+		lines = append(lines, yamlLine{
+			number:  number + 1,
+			indent:  len(text) - len(content),
+			content: strings.TrimRight(content, " \t"),
+		})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.parseNode(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+// yamlLine is a non-empty line of YAML with comments removed.
+type yamlLine struct {
+	number  int
+	indent  int
+	content string
+}
+
+// yamlParser parses block structure from a sequence of lines.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseNode parses the block starting at the current line, which must be at the given indent.
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	line := p.lines[p.pos]
+	if isSequenceItem(line.content) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok := splitKey(line.content); ok {
+		return p.parseMapping(indent)
+	}
+	p.pos++
+	return parseFlow(line.content, line.number)
+}
+
+// parseSequence parses block sequence items at the given indent.
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent || !isSequenceItem(line.content) {
+			break
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(line.content, "-"), " ")
+		if rest == "" {
+			p.pos++
+			item, err := p.parseChild(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		// The item's content becomes a line of its own, indented to where it starts
+		p.lines[p.pos] = yamlLine{
+			number:  line.number,
+			indent:  indent + len(line.content) - len(rest),
+			content: rest,
+		}
+		item, err := p.parseNode(p.lines[p.pos].indent)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseMapping parses block mapping entries at the given indent.
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	mapping := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent {
+			if line.indent > indent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+			}
+			break
+		}
+		key, rest, ok := splitKey(line.content)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a key", line.number)
+		}
+		if _, exists := mapping[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		p.pos++
+		if rest != "" {
+			value, err := parseFlow(rest, line.number)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+			continue
+		}
+		// A sequence may sit at the same indent as its key
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].content) {
+			value, err := p.parseSequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+			continue
+		}
+		value, err := p.parseChild(indent)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	return mapping, nil
+}
+
+// parseChild parses a nested block more indented than its parent, or returns nil if there is none.
+func (p *yamlParser) parseChild(parent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= parent {
+		return nil, nil
+	}
+	return p.parseNode(p.lines[p.pos].indent)
+}
+
+// isSequenceItem reports whether content starts a block sequence item.
+func isSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// splitKey splits "key: value" content, returning false if content is not a mapping entry.
+func splitKey(content string) (key, rest string, ok bool) {
+	if content == "" || strings.ContainsRune("[{", rune(content[0])) {
+		return "", "", false
+	}
+	start := 0
+	if content[0] == '"' || content[0] == '\'' {
+		end := strings.IndexByte(content[1:], content[0])
+		if end < 0 {
+			return "", "", false
+		}
+		start = end + 2
+	}
+	for i := start; i < len(content); i++ {
+		if content[i] == ':' && (i+1 == len(content) || content[i+1] == ' ') {
+			key, err := parseScalar(strings.TrimSpace(content[:i]))
+			if err != nil {
+				return "", "", false
+			}
+			return key, strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// stripComment removes a trailing comment that is not inside quotes.
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// parseFlow parses an inline value: a flow sequence, flow mapping or scalar.
+func parseFlow(text string, number int) (interface{}, error) {
+	f := &flowParser{text: text}
+	value, err := f.parseValue()
+	if err == nil {
+		f.skipSpace()
+		if f.pos < len(f.text) {
+			err = fmt.Errorf("unexpected %q", f.text[f.pos:])
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", number, err)
+	}
+	return value, nil
+}
+
+// flowParser parses YAML flow collections within a single line.
+type flowParser struct {
+	text  string
+	pos   int
+	depth int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.pos < len(f.text) && f.text[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *flowParser) parseValue() (interface{}, error) {
+	f.skipSpace()
+	if f.pos >= len(f.text) {
+		return nil, nil
+	}
+	switch f.text[f.pos] {
+	case '[':
+		f.pos++
+		f.depth++
+		items := []interface{}{}
+		for {
+			f.skipSpace()
+			if f.pos < len(f.text) && f.text[f.pos] == ']' {
+				f.pos++
+				f.depth--
+				return items, nil
+			}
+			item, err := f.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if err := f.endItem(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.pos++
+		f.depth++
+		mapping := map[string]interface{}{}
+		for {
+			f.skipSpace()
+			if f.pos < len(f.text) && f.text[f.pos] == '}' {
+				f.pos++
+				f.depth--
+				return mapping, nil
+			}
+			key, err := f.parseToken(":")
+			if err != nil {
+				return nil, err
+			}
+			if f.pos >= len(f.text) || f.text[f.pos] != ':' {
+				return nil, fmt.Errorf("expected ':' after key %q", key)
+			}
+			f.pos++
+			value, err := f.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+			if err := f.endItem('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if f.depth > 0 {
+		return f.parseToken(",]}")
+	}
+	// Outside a flow collection the rest of the line is one scalar
+	token := strings.TrimSpace(f.text[f.pos:])
+	f.pos = len(f.text)
+	return parseScalar(token)
+}
+
+// endItem consumes the separator after a flow collection item.
+func (f *flowParser) endItem(closing byte) error {
+	f.skipSpace()
+	if f.pos >= len(f.text) {
+		return fmt.Errorf("missing '%c'", closing)
+	}
+	switch f.text[f.pos] {
+	case ',':
+		f.pos++
+		return nil
+	case closing:
+		return nil
+	}
+	return fmt.Errorf("unexpected %q", f.text[f.pos:])
+}
+
+// parseToken parses a scalar inside a flow collection, ending at any of the stop characters.
+func (f *flowParser) parseToken(stops string) (string, error) {
+	f.skipSpace()
+	if f.pos < len(f.text) && (f.text[f.pos] == '"' || f.text[f.pos] == '\'') {
+		quote := f.text[f.pos]
+		end := f.pos + 1
+		for end < len(f.text) && (f.text[end] != quote || (quote == '"' && f.text[end-1] == '\\')) {
+			end++
+		}
+		if end >= len(f.text) {
+			return "", fmt.Errorf("unterminated quoted string")
+		}
+		token := f.text[f.pos : end+1]
+		f.pos = end + 1
+		return parseScalar(token)
+	}
+	start := f.pos
+	for f.pos < len(f.text) && !strings.ContainsRune(stops, rune(f.text[f.pos])) {
+		f.pos++
+	}
+	return parseScalar(strings.TrimSpace(f.text[start:f.pos]))
+}
+
+// parseScalar unquotes a scalar value.
+func parseScalar(token string) (string, error) {
+	if len(token) >= 2 && token[0] == '\'' && token[len(token)-1] == '\'' {
+		return strings.ReplaceAll(token[1:len(token)-1], "''", "'"), nil
+	}
+	if len(token) >= 2 && token[0] == '"' && token[len(token)-1] == '"' {
+		var builder strings.Builder
+		inner := token[1 : len(token)-1]
+		for i := 0; i < len(inner); i++ {
+			if inner[i] != '\\' || i+1 == len(inner) {
+				builder.WriteByte(inner[i])
+				continue
+			}
+			i++
+			switch inner[i] {
+			case 'n':
+				builder.WriteByte('\n')
+			case 't':
+				builder.WriteByte('\t')
+			default:
+				builder.WriteByte(inner[i])
+			}
+		}
+		return builder.String(), nil
+	}
+	if token == "" {
+		return token, nil
+	}
+	switch token[0] {
+	case '"', '\'':
+		return "", fmt.Errorf("unterminated quoted string")
+	case '&', '*':
+		return "", fmt.Errorf("%q: anchors and aliases are not supported", token)
+	case '!':
+		return "", fmt.Errorf("%q: tags are not supported", token)
+	case '|', '>':
+		return "", fmt.Errorf("%q: block scalars are not supported", token)
+	case '?', '%', '@', '`':
+		return "", fmt.Errorf("%q: %c is not supported at the start of a value", token, token[0])
+	}
+	return token, nil
+}
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"lib-post-interchange/libale/errors"
+)
+
+// Projection selects, orders and renames the columns of an Object for output.
+// Column names in a Projection always refer to the source Object's names.
+type Projection struct {
+	// Include lists the columns to output, in output order.
+	// When empty, all columns are output in their existing order.
+	Include []string
+	// Exclude lists columns to leave out of the output.
+	Exclude []string
+	// Rename maps source column names to the names used in the output.
+	Rename map[string]string
+}
+
+// IsZero reports whether the projection leaves an Object unchanged.
+func (p Projection) IsZero() bool {
+	return len(p.Include) == 0 && len(p.Exclude) == 0 && len(p.Rename) == 0
+}
+
+// Project returns a copy of the Object with the projection applied.
+// The source Object is not modified.
+func (o *Object) Project(p Projection) (*Object, error) {
+	if o == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+
+	// Index source columns by name
+	byName := make(map[string]Column, len(o.Columns))
+	ordered := make([]Column, len(o.Columns))
+	for _, col := range o.Columns {
+		byName[col.Name] = col
+		if col.Order >= 0 && col.Order < len(ordered) {
+			ordered[col.Order] = col
+		}
+	}
+
+	excluded := make(map[string]bool, len(p.Exclude))
+	for _, name := range p.Exclude {
+		if _, ok := byName[name]; !ok {
+			return nil, errors.ErrOutputUnknownColumn.WithContext(name)
+		}
+		excluded[name] = true
+	}
+	for name := range p.Rename {
+		if _, ok := byName[name]; !ok {
+			return nil, errors.ErrOutputUnknownColumn.WithContext(name)
+		}
+	}
+
+	// Select source columns in output order
+	var selected []Column
+	if len(p.Include) > 0 {
+		for _, name := range p.Include {
+			col, ok := byName[name]
+			if !ok {
+				return nil, errors.ErrOutputUnknownColumn.WithContext(name)
+			}
+			if !excluded[name] {
+				selected = append(selected, col)
+			}
+		}
+	} else {
+		for _, col := range ordered {
+			if !excluded[col.Name] {
+				selected = append(selected, col)
+			}
+		}
+	}
+
+	// Build output columns
+	columns := make([]Column, len(selected))
+	seen := make(map[string]bool, len(selected))
+	for i, col := range selected {
+		name := col.Name
+		if renamed, ok := p.Rename[name]; ok {
+			name = renamed
+		}
+		if name == "" {
+			return nil, errors.ErrOutputEmptyColumnName.WithContext(col.Name)
+		}
+		if seen[name] {
+			return nil, errors.ErrOutputDuplicateColumn.WithContext(name)
+		}
+		seen[name] = true
+		columns[i] = Column{Name: name, Order: i}
+	}
+
+	// Build output rows
+	rows := make([]Row, len(o.Rows))
+	for i, row := range o.Rows {
+		valueMap := make(map[Column]Value, len(columns))
+		for j, col := range selected {
+			if val, ok := row.ValueMap[col]; ok && val != nil {
+				valueMap[columns[j]] = withColumn(val, columns[j])
+			}
+		}
+		rows[i] = Row{Columns: columns, ValueMap: valueMap, Order: row.Order}
+	}
+
+	projected := *o
+	projected.HeaderFields = append([]Field(nil), o.HeaderFields...)
+	projected.Columns = columns
+	projected.Rows = rows
+	return &projected, nil
+}
+
+// withColumn returns a copy of a value that refers to the given column.
+func withColumn(v Value, col Column) Value {
+	switch val := v.(type) {
+	case StringValue:
+		val.Column = col
+		return val
+	case IntValue:
+		val.Column = col
+		return val
+	}
+	return v
+}
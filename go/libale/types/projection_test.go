@@ -0,0 +1,98 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newProjectionObject() *Object {
+	columns := []Column{
+		{Name: "Name", Order: 0},
+		{Name: "Reel_name", Order: 1},
+		{Name: "Start", Order: 2},
+		{Name: "End", Order: 3},
+	}
+	values := []string{"A001C001", "A001R1AA", "01:00:00:00", "01:00:10:00"}
+	row := Row{Columns: columns, ValueMap: map[Column]Value{}}
+	for i, col := range columns {
+		row.ValueMap[col] = StringValue{Column: col, Value: values[i]}
+	}
+	return &Object{
+		HeaderFields:   []Field{FieldDelimiter{BaseField{Key: "FIELD_DELIM", Value: "TABS"}}},
+		FieldDelimiter: FieldDelimiter{BaseField{Key: "FIELD_DELIM", Value: "TABS"}},
+		Columns:        columns,
+		Rows:           []Row{row},
+	}
+}
+
+func TestObjectProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		projection  Projection
+		wantColumns []string
+		wantValues  []string
+		wantErr     bool
+	}{
+		{
+			name:        "zero projection",
+			projection:  Projection{},
+			wantColumns: []string{"Name", "Reel_name", "Start", "End"},
+			wantValues:  []string{"A001C001", "A001R1AA", "01:00:00:00", "01:00:10:00"},
+		},
+		{
+			name:        "include reorders",
+			projection:  Projection{Include: []string{"End", "Name"}},
+			wantColumns: []string{"End", "Name"},
+			wantValues:  []string{"01:00:10:00", "A001C001"},
+		},
+		{
+			name:        "exclude and rename",
+			projection:  Projection{Exclude: []string{"Start"}, Rename: map[string]string{"Reel_name": "Camroll"}},
+			wantColumns: []string{"Name", "Camroll", "End"},
+			wantValues:  []string{"A001C001", "A001R1AA", "01:00:10:00"},
+		},
+		{
+			name:       "unknown column",
+			projection: Projection{Include: []string{"Missing"}},
+			wantErr:    true,
+		},
+		{
+			name:       "duplicate output name",
+			projection: Projection{Rename: map[string]string{"Start": "End"}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := newProjectionObject()
+			got, err := obj.Project(tt.projection)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Project() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Projected object is invalid: %v", err)
+			}
+
+			var columns, values []string
+			for _, col := range got.Columns {
+				columns = append(columns, col.Name)
+				values = append(values, got.Rows[0].ValueMap[col].String())
+			}
+			if !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("Project() columns = %v, want %v", columns, tt.wantColumns)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Project() values = %v, want %v", values, tt.wantValues)
+			}
+
+			// The source object must be untouched
+			if !reflect.DeepEqual(obj, newProjectionObject()) {
+				t.Error("Project() modified the source object")
+			}
+		})
+	}
+}
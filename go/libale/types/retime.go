@@ -0,0 +1,277 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/timecode"
+)
+
+// RetimeMode selects how Retime carries timecodes to a new frame rate.
+type RetimeMode int
+
+const (
+	// RetimePreserveFrames relabels each timecode's frame count at the new
+	// rate, as when 25 fps clips play at 24 fps with a speed change. Every
+	// frame keeps its place and durations keep their frame counts.
+	RetimePreserveFrames RetimeMode = iota
+	// RetimePreserveRealtime converts each timecode to the frame at the same
+	// wall-clock time at the new rate, rounding times that fall between frames.
+	RetimePreserveRealtime
+)
+
+// Rounding selects how RetimePreserveRealtime rounds a time that falls
+// between frames at the new rate.
+type Rounding int
+
+const (
+	// RoundNearest rounds to the nearest frame, and halves up.
+	RoundNearest Rounding = iota
+	// RoundDown rounds to the frame before.
+	RoundDown
+	// RoundUp rounds to the frame after.
+	RoundUp
+)
+
+// Retiming describes a change of an Object's frame rate.
+type Retiming struct {
+	// Rate is the new frame rate. Timecodes are written in drop frame if it
+	// is drop frame.
+	Rate timecode.Rate
+	// From is the frame rate of the timecodes. The zero value means the FPS
+	// header field. Timecodes written with drop frame separators count in
+	// drop frame.
+	From timecode.Rate
+	// Mode selects whether frame counts or wall-clock times are kept.
+	Mode RetimeMode
+	// Rounding selects how RetimePreserveRealtime rounds between frames.
+	Rounding Rounding
+	// Columns lists the timecode columns to retime. When empty, every column
+	// whose values are all timecodes is retimed.
+	Columns []string
+}
+
+// InexactTimecode is a timecode that Retime could not carry to the new rate
+// exactly.
+type InexactTimecode struct {
+	Row    int
+	Column string
+	// Value is the timecode at the old rate and Result the one written.
+	Value  string
+	Result string
+	// Offset is the frames at the new rate from the exact time to Result,
+	// such as -0.5 for a time rounded down by half a frame.
+	Offset float64
+	// Wrapped is set when the result is past 24 hours and wrapped around
+	// midnight.
+	Wrapped bool
+}
+
+// String describes the timecode, how far it is off or that it wrapped.
+func (t InexactTimecode) String() string {
+	if t.Wrapped {
+		return fmt.Sprintf("row %d, column %q: %s wraps past midnight to %s", t.Row, t.Column, t.Value, t.Result)
+	}
+	return fmt.Sprintf("row %d, column %q: %s is %s, %+.2f frames off", t.Row, t.Column, t.Value, t.Result, t.Offset)
+}
+
+// Retime returns a copy of the Object with its timecode columns and its FPS
+// header field, and any FPS column values at the old rate, changed to a new
+// frame rate. Duration is worked out again from the retimed Start and End
+// when a row has both, and is otherwise retimed as a length. Timecodes that
+// could not be carried exactly are returned with the copy. The source Object
+// is not modified.
+func (o *Object) Retime(r Retiming) (*Object, []InexactTimecode, error) {
+	if o == nil {
+		return nil, nil, errors.ErrOutputNilObject
+	}
+	if r.Rate.IsZero() {
+		return nil, nil, errors.ErrInputInvalidFrameRate.WithContext("no rate to retime to")
+	}
+	from := r.From
+	if from.IsZero() {
+		var err error
+		if from, err = o.FPS.Rate(); err != nil {
+			return nil, nil, errors.ErrInputInvalidFrameRate.WithContext(fmt.Sprintf("FPS header field: %v", err))
+		}
+	}
+
+	names := o.ColumnNames()
+	retimed := make([]bool, len(names))
+	if len(r.Columns) > 0 {
+		for _, name := range r.Columns {
+			i := indexFold(names, name)
+			if i < 0 {
+				return nil, nil, errors.ErrOutputUnknownColumn.WithContext(name)
+			}
+			retimed[i] = true
+		}
+	} else {
+		for i := range names {
+			retimed[i] = o.isTimecodeColumn(i)
+		}
+	}
+	startIndex, endIndex, durationIndex := indexFold(names, "Start"), indexFold(names, "End"), indexFold(names, "Duration")
+	fpsIndex := indexFold(names, "FPS")
+
+	var inexact []InexactTimecode
+	rows := make([][]string, len(o.Rows))
+	for i, row := range o.Rows {
+		values := o.Values(row)
+		frames := make(map[int]int)
+		for j, v := range values {
+			v = strings.TrimSpace(v)
+			if !retimed[j] || v == "" || j == durationIndex {
+				continue
+			}
+			tc, rate, err := parseRetimed(v, from, i, names[j])
+			if err != nil {
+				return nil, nil, err
+			}
+			result, n, diagnostic := r.retime(tc.ToFrames(rate), rate)
+			values[j], frames[j] = result.String(), n
+			if diagnostic != nil {
+				diagnostic.Row, diagnostic.Column, diagnostic.Value = i, names[j], v
+				inexact = append(inexact, *diagnostic)
+			}
+		}
+
+		if durationIndex >= 0 && retimed[durationIndex] {
+			if v := strings.TrimSpace(values[durationIndex]); v != "" {
+				tc, rate, err := parseRetimed(v, from, i, names[durationIndex])
+				if err != nil {
+					return nil, nil, err
+				}
+				start, hasStart := frames[startIndex]
+				end, hasEnd := frames[endIndex]
+				length, exact := r.retimeLength(tc.ToFrames(rate), rate)
+				if hasStart && hasEnd {
+					length = end - start
+				}
+				// A duration is a length, counted without drop frame
+				lengthRate := r.Rate
+				lengthRate.DropFrame = false
+				values[durationIndex] = timecode.FromFrames(length, lengthRate).String()
+				if offset := float64(length) - exact; offset != 0 {
+					inexact = append(inexact, InexactTimecode{
+						Row: i, Column: names[durationIndex], Value: v, Result: values[durationIndex], Offset: offset,
+					})
+				}
+			}
+		}
+
+		if fpsIndex >= 0 {
+			if rate, err := timecode.ParseRate(values[fpsIndex]); err == nil && rate.Num*from.Den == from.Num*rate.Den {
+				values[fpsIndex] = rateValue(r.Rate)
+			}
+		}
+		rows[i] = values
+	}
+
+	headerFields := make([]Field, 0, len(o.HeaderFields)+1)
+	found := false
+	for _, field := range o.HeaderFields {
+		if field != nil && field.GetKey() == "FPS" {
+			field, found = FrameRate{BaseField{Key: "FPS", Value: rateValue(r.Rate)}}, true
+		}
+		headerFields = append(headerFields, field)
+	}
+	if !found {
+		headerFields = append(headerFields, FrameRate{BaseField{Key: "FPS", Value: rateValue(r.Rate)}})
+	}
+	return NewObject(headerFields, names, rows), inexact, nil
+}
+
+// retime carries the frame count of a timecode at a rate to the new rate,
+// returning the new timecode and frame count, and a diagnostic without its
+// row, column and value if it is not exact.
+func (r Retiming) retime(frames int, rate timecode.Rate) (timecode.Timecode, int, *InexactTimecode) {
+	n, exact := frames, float64(frames)
+	if r.Mode == RetimePreserveRealtime {
+		n, exact = r.scale(frames, rate)
+	}
+	result := timecode.FromFrames(n, r.Rate)
+	switch {
+	case n >= (timecode.Timecode{Hours: 24}).ToFrames(r.Rate):
+		return result, n, &InexactTimecode{Result: result.String(), Offset: float64(n) - exact, Wrapped: true}
+	case float64(n) != exact:
+		return result, n, &InexactTimecode{Result: result.String(), Offset: float64(n) - exact}
+	}
+	return result, n, nil
+}
+
+// retimeLength carries a length in frames at a rate to the new rate,
+// returning the rounded and exact lengths.
+func (r Retiming) retimeLength(frames int, rate timecode.Rate) (int, float64) {
+	if r.Mode == RetimePreserveRealtime {
+		return r.scale(frames, rate)
+	}
+	return frames, float64(frames)
+}
+
+// scale returns the frames at the new rate at the wall-clock time of a frame
+// count at a rate, rounded by the rounding policy, and the exact value.
+func (r Retiming) scale(frames int, rate timecode.Rate) (int, float64) {
+	num := frames * r.Rate.Num * rate.Den
+	den := r.Rate.Den * rate.Num
+	n := num / den
+	if rem := num % den; rem != 0 {
+		switch r.Rounding {
+		case RoundNearest:
+			if 2*rem >= den {
+				n++
+			}
+		case RoundUp:
+			n++
+		}
+	}
+	return n, float64(num) / float64(den)
+}
+
+// isTimecodeColumn reports whether the column at an output index has a value
+// in some row and only timecodes.
+func (o *Object) isTimecodeColumn(index int) bool {
+	found := false
+	for _, row := range o.Rows {
+		v := strings.TrimSpace(o.Values(row)[index])
+		if v == "" {
+			continue
+		}
+		if _, err := timecode.Parse(v); err != nil {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// parseRetimed parses a timecode to retime and returns it with its rate,
+// counting in drop frame if it is written so.
+func parseRetimed(v string, rate timecode.Rate, row int, column string) (timecode.Timecode, timecode.Rate, error) {
+	tc, err := timecode.Parse(v)
+	if err == nil {
+		rate.DropFrame = tc.DropFrame && rate.CanDropFrame()
+		err = tc.Validate(rate)
+	}
+	if err != nil {
+		return timecode.Timecode{}, rate, errors.ErrInputInvalidTimecode.WithContext(fmt.Sprintf("row %d, column %q: %v", row, column, err))
+	}
+	return tc, rate, nil
+}
+
+// rateValue formats a rate as FPS values are written, without drop frame.
+func rateValue(rate timecode.Rate) string {
+	rate.DropFrame = false
+	return rate.String()
+}
+
+// indexFold returns the index of a name in names, ignoring case, or -1.
+func indexFold(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,152 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/timecode"
+)
+
+func retimeObject(fps string, rows ...[]string) *Object {
+	headerFields := []Field{BaseField{Key: "FIELD_DELIM", Value: "TABS"}}
+	if fps != "" {
+		headerFields = append(headerFields, FrameRate{BaseField{Key: "FPS", Value: fps}})
+	}
+	columns := []string{"Name", "Start", "End", "Duration", "FPS", "Date_camera", "Time_camera"}
+	return NewObject(headerFields, columns, rows)
+}
+
+func TestRetime(t *testing.T) {
+	clip := []string{"A901C001", "00:22:14:12", "00:23:00:13", "00:00:46:01", "25", "20240426", "17h19m50s"}
+	tests := []struct {
+		name        string
+		obj         *Object
+		retiming    Retiming
+		want        [][]string
+		wantFPS     string
+		wantInexact []string
+	}{
+		{
+			name:     "preserve frames",
+			obj:      retimeObject("25", clip),
+			retiming: Retiming{Rate: timecode.Rate24},
+			want:     [][]string{{"A901C001", "00:23:10:02", "00:23:58:01", "00:00:47:23", "24", "20240426", "17h19m50s"}},
+			wantFPS:  "24",
+		},
+		{
+			name:     "preserve realtime rounding to nearest",
+			obj:      retimeObject("25", clip),
+			retiming: Retiming{Rate: timecode.Rate24, Mode: RetimePreserveRealtime},
+			want:     [][]string{{"A901C001", "00:22:14:12", "00:23:00:12", "00:00:46:00", "24", "20240426", "17h19m50s"}},
+			wantFPS:  "24",
+			wantInexact: []string{
+				`row 0, column "Start": 00:22:14:12 is 00:22:14:12, +0.48 frames off`,
+				`row 0, column "End": 00:23:00:13 is 00:23:00:12, -0.48 frames off`,
+				`row 0, column "Duration": 00:00:46:01 is 00:00:46:00, -0.96 frames off`,
+			},
+		},
+		{
+			name:     "preserve realtime rounding down",
+			obj:      retimeObject("25", clip),
+			retiming: Retiming{Rate: timecode.Rate24, Mode: RetimePreserveRealtime, Rounding: RoundDown},
+			want:     [][]string{{"A901C001", "00:22:14:11", "00:23:00:12", "00:00:46:01", "24", "20240426", "17h19m50s"}},
+			wantFPS:  "24",
+			wantInexact: []string{
+				`row 0, column "Start": 00:22:14:12 is 00:22:14:11, -0.52 frames off`,
+				`row 0, column "End": 00:23:00:13 is 00:23:00:12, -0.48 frames off`,
+				`row 0, column "Duration": 00:00:46:01 is 00:00:46:01, +0.04 frames off`,
+			},
+		},
+		{
+			name:     "preserve realtime exact",
+			obj:      retimeObject("24", []string{"A", "01:00:00:00", "01:00:01:00", "00:00:01:00", "23.976", "", ""}),
+			retiming: Retiming{Rate: timecode.Rate48, Mode: RetimePreserveRealtime, Rounding: RoundUp},
+			want:     [][]string{{"A", "01:00:00:00", "01:00:01:00", "00:00:01:00", "23.976", "", ""}},
+			wantFPS:  "48",
+		},
+		{
+			name:     "drop frame to non-drop frame",
+			obj:      retimeObject("29.97", []string{"A", "01:00:00;00", "01:00:01;00", "", "29.97", "", ""}),
+			retiming: Retiming{Rate: timecode.Rate29_97},
+			want:     [][]string{{"A", "00:59:56:12", "00:59:57:12", "", "29.97", "", ""}},
+			wantFPS:  "29.97",
+		},
+		{
+			name:     "non-drop frame to drop frame keeps a non-drop frame duration",
+			obj:      retimeObject("29.97", []string{"A", "01:00:00:00", "01:00:01:00", "00:00:01:00", "29.97", "", ""}),
+			retiming: Retiming{Rate: timecode.Rate29_97DF},
+			want:     [][]string{{"A", "01:00:03;18", "01:00:04;18", "00:00:01:00", "29.97", "", ""}},
+			wantFPS:  "29.97",
+		},
+		{
+			name:     "selected columns and a rate option",
+			obj:      retimeObject("", clip),
+			retiming: Retiming{Rate: timecode.Rate24, From: timecode.Rate25, Columns: []string{"start"}},
+			want:     [][]string{{"A901C001", "00:23:10:02", "00:23:00:13", "00:00:46:01", "24", "20240426", "17h19m50s"}},
+			wantFPS:  "24",
+		},
+		{
+			name:        "past midnight",
+			obj:         retimeObject("25", []string{"A", "23:30:00:00", "", "", "", "", ""}),
+			retiming:    Retiming{Rate: timecode.Rate24},
+			want:        [][]string{{"A", "00:28:45:00", "", "", "", "", ""}},
+			wantFPS:     "24",
+			wantInexact: []string{`row 0, column "Start": 23:30:00:00 wraps past midnight to 00:28:45:00`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := tt.obj.Values(tt.obj.Rows[0])
+			got, inexact, err := tt.obj.Retime(tt.retiming)
+			if err != nil {
+				t.Fatalf("Retime() error = %v", err)
+			}
+			for i, row := range got.Rows {
+				if values := got.Values(row); !reflect.DeepEqual(values, tt.want[i]) {
+					t.Errorf("Row %d = %v\nwant %v", i, values, tt.want[i])
+				}
+			}
+			if got.FPS.GetValue() != tt.wantFPS {
+				t.Errorf("FPS = %q, want %q", got.FPS.GetValue(), tt.wantFPS)
+			}
+			var messages []string
+			for _, d := range inexact {
+				messages = append(messages, d.String())
+			}
+			if !reflect.DeepEqual(messages, tt.wantInexact) {
+				t.Errorf("Inexact = %q\nwant %q", messages, tt.wantInexact)
+			}
+			if after := tt.obj.Values(tt.obj.Rows[0]); !reflect.DeepEqual(after, before) {
+				t.Errorf("Retime() modified the source row: %v", after)
+			}
+		})
+	}
+}
+
+func TestRetimeErrors(t *testing.T) {
+	clip := []string{"A", "01:00:00:00", "01:00:01:00", "", "", "", ""}
+	tests := []struct {
+		name     string
+		obj      *Object
+		retiming Retiming
+		wantErr  *errors.Error
+	}{
+		{"nil", nil, Retiming{Rate: timecode.Rate24}, errors.ErrOutputNilObject},
+		{"no rate", retimeObject("25", clip), Retiming{}, errors.ErrInputInvalidFrameRate},
+		{"no FPS", retimeObject("", clip), Retiming{Rate: timecode.Rate24}, errors.ErrInputInvalidFrameRate},
+		{"unknown column", retimeObject("25", clip), Retiming{Rate: timecode.Rate24, Columns: []string{"Sound TC"}}, errors.ErrOutputUnknownColumn},
+		{"invalid timecode", retimeObject("24", []string{"A", "01:00:00:24", "", "", "", "", ""}), Retiming{Rate: timecode.Rate25}, errors.ErrInputInvalidTimecode},
+		{"not a timecode", retimeObject("25", clip), Retiming{Rate: timecode.Rate24, Columns: []string{"Name"}}, errors.ErrInputInvalidTimecode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.obj.Retime(tt.retiming)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+				t.Errorf("Retime() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
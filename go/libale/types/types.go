@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/timecode"
 )
 
 // Field defines the common behavior for all ALE field types.
@@ -36,6 +37,53 @@ type AudioFormat struct{ BaseField }
 // FrameRate represents the framerate value in the header.
 type FrameRate struct{ BaseField }
 
+// Rate parses the frame rate value.
+func (f FrameRate) Rate() (timecode.Rate, error) {
+	return timecode.ParseRate(f.Value)
+}
+
+// FilmRate returns the frame rate of the film that video at this rate holds
+// with 2:3 pulldown, such as 23.976 for 29.97. It reports false if the value
+// is not a pulldown video rate.
+func (f FrameRate) FilmRate() (FrameRate, bool) {
+	rate, err := f.Rate()
+	if err != nil {
+		return FrameRate{}, false
+	}
+	film, ok := rate.FilmRate()
+	if !ok {
+		return FrameRate{}, false
+	}
+	return FrameRate{BaseField{Key: f.Key, Value: film.String()}}, true
+}
+
+// VideoRate returns the frame rate of the video that holds film at this rate
+// with 2:3 pulldown, such as 29.97 for 23.976. It reports false if the value
+// is not a pulldown film rate.
+func (f FrameRate) VideoRate() (FrameRate, bool) {
+	rate, err := f.Rate()
+	if err != nil {
+		return FrameRate{}, false
+	}
+	video, ok := rate.VideoRate()
+	if !ok {
+		return FrameRate{}, false
+	}
+	return FrameRate{BaseField{Key: f.Key, Value: video.String()}}, true
+}
+
+// IsPulldownOf reports whether video at this rate holds film at the film
+// rate with 2:3 pulldown.
+func (f FrameRate) IsPulldownOf(film FrameRate) bool {
+	videoRate, err1 := f.Rate()
+	filmRate, err2 := film.Rate()
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	want, ok := videoRate.FilmRate()
+	return ok && want == filmRate
+}
+
 // FilmFormat represents the film format value in the header.
 type FilmFormat struct{ BaseField }
 
@@ -362,3 +410,107 @@ func (r Row) Validate(columns []Column) error {
 
 	return nil
 }
+
+// NewObject builds an Object from header fields, column names and rows of values.
+// Rows with fewer values than columns are padded with empty strings and extra
+// values are ignored, as when reading an ALE file.
+func NewObject(headerFields []Field, columnNames []string, rows [][]string) *Object {
+	columns := make([]Column, len(columnNames))
+	for i, name := range columnNames {
+		columns[i] = Column{Name: name, Order: i}
+	}
+
+	objRows := make([]Row, len(rows))
+	for i, values := range rows {
+		row := Row{
+			Columns:  columns,
+			ValueMap: make(map[Column]Value, len(columns)),
+			Order:    i,
+		}
+		for _, col := range columns {
+			value := ""
+			if col.Order < len(values) {
+				value = values[col.Order]
+			}
+			row.ValueMap[col] = StringValue{Column: col, Value: value}
+		}
+		objRows[i] = row
+	}
+
+	obj := &Object{
+		HeaderFields: headerFields,
+		Columns:      columns,
+		Rows:         objRows,
+	}
+	obj.AssignHeaderFields()
+	return obj
+}
+
+// AssignHeaderFields assigns header fields to their specific types in the Object.
+func (o *Object) AssignHeaderFields() {
+	for _, field := range o.HeaderFields {
+		if field == nil {
+			continue
+		}
+		base := BaseField{Key: field.GetKey(), Value: field.GetValue()}
+		switch field.GetKey() {
+		case "FIELD_DELIM":
+			o.FieldDelimiter = FieldDelimiter{BaseField: base}
+		case "FPS":
+			o.FPS = FrameRate{BaseField: base}
+		case "AUDIO_FORMAT":
+			o.AudioFormat = AudioFormat{BaseField: base}
+		case "VIDEO_FORMAT":
+			o.VideoFormat = VideoFormat{BaseField: base}
+		case "FILM_FORMAT":
+			o.FilmFormat = FilmFormat{BaseField: base}
+		case "TAPE":
+			o.Tape = Tape{BaseField: base}
+		}
+	}
+}
+
+// Header returns the value of the header field with the given key.
+func (o *Object) Header(key string) (string, bool) {
+	for _, field := range o.HeaderFields {
+		if field != nil && field.GetKey() == key {
+			return field.GetValue(), true
+		}
+	}
+	return "", false
+}
+
+// ColumnNames returns the names of the Object's columns in output order.
+func (o *Object) ColumnNames() []string {
+	names := make([]string, len(o.Columns))
+	for _, col := range o.Columns {
+		if col.Order >= 0 && col.Order < len(names) {
+			names[col.Order] = col.Name
+		}
+	}
+	return names
+}
+
+// Values returns a row's values in the Object's column output order.
+func (o *Object) Values(row Row) []string {
+	values := make([]string, len(o.Columns))
+	for _, col := range o.Columns {
+		if val, ok := row.ValueMap[col]; ok && val != nil && col.Order >= 0 && col.Order < len(values) {
+			values[col.Order] = val.String()
+		}
+	}
+	return values
+}
+
+// Value returns the value of the named column in the row.
+func (r Row) Value(name string) (string, bool) {
+	for _, col := range r.Columns {
+		if col.Name == name {
+			if val, ok := r.ValueMap[col]; ok && val != nil {
+				return val.String(), true
+			}
+			return "", true
+		}
+	}
+	return "", false
+}
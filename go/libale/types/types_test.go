@@ -77,6 +77,41 @@ func TestFieldTypes(t *testing.T) {
 	}
 }
 
+func TestFrameRatePulldown(t *testing.T) {
+	fps := func(value string) FrameRate { return FrameRate{BaseField{Key: "FPS", Value: value}} }
+	tests := []struct {
+		video, film string
+	}{
+		{"29.97", "23.976"},
+		{"30", "24"},
+	}
+	for _, tt := range tests {
+		if got, ok := fps(tt.video).FilmRate(); !ok || got != fps(tt.film) {
+			t.Errorf("FilmRate() of %s = %v, %v, want %s", tt.video, got, ok, tt.film)
+		}
+		if got, ok := fps(tt.film).VideoRate(); !ok || got != fps(tt.video) {
+			t.Errorf("VideoRate() of %s = %v, %v, want %s", tt.film, got, ok, tt.video)
+		}
+		if !fps(tt.video).IsPulldownOf(fps(tt.film)) {
+			t.Errorf("%s.IsPulldownOf(%s) = false, want true", tt.video, tt.film)
+		}
+	}
+	if !fps("29.97 DF").IsPulldownOf(fps("23.98")) {
+		t.Errorf("29.97 DF.IsPulldownOf(23.98) = false, want true")
+	}
+	for _, value := range []string{"25", "59.94", "", "fast"} {
+		if _, ok := fps(value).FilmRate(); ok {
+			t.Errorf("FilmRate() of %q ok, want no film rate", value)
+		}
+	}
+	if _, ok := fps("29.97").VideoRate(); ok {
+		t.Errorf("VideoRate() of 29.97 ok, want no video rate")
+	}
+	if fps("29.97").IsPulldownOf(fps("24")) || fps("25").IsPulldownOf(fps("25")) {
+		t.Errorf("IsPulldownOf() = true for rates without pulldown")
+	}
+}
+
 func TestColumn(t *testing.T) {
 	col := Column{
 		Name:  "Scene",
@@ -0,0 +1,291 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/format"
+	"lib-post-interchange/libale/types"
+)
+
+// ReadFile reads an ALE object from an .xlsx file.
+func ReadFile(filepath string, opts Options) (*types.Object, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(data), int64(len(data)), opts)
+}
+
+// Read reads an ALE object from an .xlsx workbook. The first row of the clip
+// sheet holds column names. The clip sheet is the one named in the options,
+// or else the first sheet; header fields are read from the header sheet if
+// the workbook has one.
+func Read(r io.ReaderAt, size int64, opts Options) (*types.Object, error) {
+	opts = withDefaults(opts)
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.ErrInputFailedXLSX.WithContext(err.Error())
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheets, err := readSheetPaths(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(sheets) == 0 {
+		return nil, errors.ErrInputFailedXLSX.WithContext("workbook has no sheets")
+	}
+	var sharedStrings []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if sharedStrings, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	// Find the clip and header sheets
+	clipSheet := sheets[0]
+	var headerSheet *sheetPath
+	for i := range sheets {
+		switch {
+		case strings.EqualFold(sheets[i].name, opts.SheetName):
+			clipSheet = sheets[i]
+		case strings.EqualFold(sheets[i].name, opts.HeaderSheetName):
+			headerSheet = &sheets[i]
+		}
+	}
+
+	records, err := readSheet(files, clipSheet.path, sharedStrings)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.ErrInputEmpty.WithContext("clip sheet is empty")
+	}
+
+	headerFields := []types.Field{format.DelimiterTab}
+	if headerSheet != nil {
+		headerRecords, err := readSheet(files, headerSheet.path, sharedStrings)
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range headerRecords {
+			if len(record) < 2 || record[0] == "" || (i == 0 && record[0] == "Key") {
+				continue
+			}
+			field := types.BaseField{Key: record[0], Value: record[1]}
+			if field.Key == format.DelimiterTab.GetKey() {
+				headerFields[0] = field
+				continue
+			}
+			headerFields = append(headerFields, field)
+		}
+	}
+
+	columns := records[0]
+	for len(columns) > 0 && columns[len(columns)-1] == "" {
+		columns = columns[:len(columns)-1]
+	}
+	obj := types.NewObject(headerFields, columns, records[1:])
+	if err := obj.ValidateColumns(); err != nil {
+		return nil, errors.ErrInputFailedColumns.WithContext(err.Error())
+	}
+	return obj, nil
+}
+
+// sheetPath is a worksheet's name and the path of its part in the package.
+type sheetPath struct {
+	name string
+	path string
+}
+
+// readSheetPaths resolves the workbook's sheets, in order, to their parts.
+func readSheetPaths(files map[string]*zip.File) ([]sheetPath, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	sheets := make([]sheetPath, 0, len(workbook.Sheets))
+	for _, sheet := range workbook.Sheets {
+		target, ok := targets[sheet.ID]
+		if !ok {
+			return nil, errors.ErrInputFailedXLSX.WithContext("missing part for sheet " + sheet.Name)
+		}
+		sheets = append(sheets, sheetPath{name: sheet.Name, path: target})
+	}
+	return sheets, nil
+}
+
+// readSharedStrings reads the workbook's shared string table.
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []richText `xml:"si"`
+	}
+	if err := decodeFile(f, &sst); err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		strs[i] = item.String()
+	}
+	return strs, nil
+}
+
+// richText is string content that may be split into formatted runs.
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var builder strings.Builder
+	builder.WriteString(t.T)
+	for _, run := range t.Runs {
+		builder.WriteString(run.T)
+	}
+	return builder.String()
+}
+
+// readSheet reads a worksheet into records of cell text, placing each cell by its reference.
+func readSheet(files map[string]*zip.File, name string, sharedStrings []string) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R  string   `xml:"r,attr"`
+				T  string   `xml:"t,attr"`
+				V  string   `xml:"v"`
+				IS richText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodePart(files, name, &sheet); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for i, row := range sheet.Rows {
+		index := i
+		if row.R > 0 {
+			index = row.R - 1
+		}
+		for len(records) <= index {
+			records = append(records, nil)
+		}
+		var record []string
+		for j, cell := range row.Cells {
+			column := j
+			if cell.R != "" {
+				c, ok := parseColumn(cell.R)
+				if !ok {
+					return nil, errors.ErrInputFailedXLSX.WithContext("invalid cell reference " + cell.R)
+				}
+				column = c
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+			switch cell.T {
+			case "s":
+				n, err := strconv.Atoi(cell.V)
+				if err != nil || n < 0 || n >= len(sharedStrings) {
+					return nil, errors.ErrInputFailedXLSX.WithContext("invalid shared string in cell " + cell.R)
+				}
+				record[column] = sharedStrings[n]
+			case "inlineStr":
+				record[column] = cell.IS.String()
+			case "", "n":
+				record[column] = normalizeNumber(cell.V)
+			default:
+				record[column] = cell.V
+			}
+		}
+		records[index] = record
+	}
+	return records, nil
+}
+
+// normalizeNumber formats a stored number the shortest way that round-trips,
+// undoing floating point noise such as "0.10000000000000001".
+func normalizeNumber(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// parseColumn returns the zero-based column of an A1-style cell reference.
+func parseColumn(ref string) (int, bool) {
+	column := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		column = column*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 {
+		return 0, false
+	}
+	return column - 1, true
+}
+
+// decodePart decodes a named XML part of the package.
+func decodePart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return errors.ErrInputFailedXLSX.WithContext("missing " + name)
+	}
+	return decodeFile(f, v)
+}
+
+// decodeFile decodes an XML file in the package.
+func decodeFile(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return errors.ErrInputFailedXLSX.WithContext(err.Error())
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return errors.ErrInputFailedXLSX.WithContext(f.Name + ": " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+// Package xlsx converts ALE objects to and from Excel .xlsx workbooks.
+// Workbooks are written and read directly as Office Open XML, using only
+// archive/zip and encoding/xml.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"lib-post-interchange/libale/errors"
+	"lib-post-interchange/libale/types"
+)
+
+// Options controls how ALE objects are converted to and from workbooks.
+type Options struct {
+	// SheetName is the name of the sheet holding the clip table.
+	SheetName string
+	// HeaderSheetName is the name of the sheet holding ALE header fields.
+	HeaderSheetName string
+	// TimecodeColumns lists further columns to keep as text, in addition to
+	// those detected as holding timecode.
+	TimecodeColumns []string
+	// MaxColumnWidth caps the width of sized columns, in characters.
+	MaxColumnWidth int
+}
+
+// DefaultOptions returns the sheet names and limits used when none are given.
+func DefaultOptions() Options {
+	return Options{
+		SheetName:       "Clips",
+		HeaderSheetName: "Header",
+		MaxColumnWidth:  60,
+	}
+}
+
+// Cell styles defined in styles.xml
+const (
+	styleDefault = 0
+	styleHeader  = 1
+	styleText    = 2
+)
+
+// minColumnWidth is the narrowest width given to a sized column.
+const minColumnWidth = 8
+
+// timecodePattern matches SMPTE timecode, with drop-frame and field separators.
+var timecodePattern = regexp.MustCompile(`^\d{1,2}[:;.]\d{2}[:;.]\d{2}[:;.,]\d{2,3}$`)
+
+// knownTimecodeColumns are ALE columns that hold timecode even when empty.
+var knownTimecodeColumns = map[string]bool{
+	"Start":        true,
+	"End":          true,
+	"Duration":     true,
+	"Sound TC":     true,
+	"Auxiliary TC": true,
+	"Aux TC 24":    true,
+	"Mark IN":      true,
+	"Mark OUT":     true,
+}
+
+// WriteFile writes an ALE object to an .xlsx file.
+func WriteFile(filepath string, obj *types.Object, opts Options) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, obj, opts); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath, buf.Bytes(), 0644)
+}
+
+// Write writes an ALE object as an .xlsx workbook. Rows go to one sheet with
+// a frozen header row and columns sized to their content; header fields go to
+// a second sheet. Timecode columns are stored as text so that Excel does not
+// convert them to times.
+func Write(w io.Writer, obj *types.Object, opts Options) error {
+	if obj == nil {
+		return errors.ErrOutputNilObject
+	}
+	opts = withDefaults(opts)
+
+	names := obj.ColumnNames()
+	rows := make([][]string, len(obj.Rows))
+	for i, row := range obj.Rows {
+		rows[i] = obj.Values(row)
+	}
+
+	// Decide which columns are kept as text
+	textColumns := make([]bool, len(names))
+	for i, name := range names {
+		textColumns[i] = knownTimecodeColumns[name] || isTimecodeColumn(rows, i)
+		for _, extra := range opts.TimecodeColumns {
+			if extra == name {
+				textColumns[i] = true
+			}
+		}
+	}
+
+	// Size columns to their content
+	widths := make([]int, len(names))
+	for i, name := range names {
+		widths[i] = utf8.RuneCountInString(name)
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(value))
+		}
+	}
+	for i := range widths {
+		widths[i] = min(max(widths[i]+2, minColumnWidth), opts.MaxColumnWidth)
+	}
+
+	// Build the clip sheet
+	var clips bytes.Buffer
+	clips.WriteString(xml.Header)
+	clips.WriteString(`<worksheet xmlns="` + nsMain + `">`)
+	clips.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	clips.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	clips.WriteString(`</sheetView></sheetViews>`)
+	if len(names) > 0 {
+		clips.WriteString(`<cols>`)
+		for i, width := range widths {
+			style := ""
+			if textColumns[i] {
+				style = fmt.Sprintf(` style="%d"`, styleText)
+			}
+			fmt.Fprintf(&clips, `<col min="%d" max="%d" width="%d" customWidth="1"%s/>`, i+1, i+1, width, style)
+		}
+		clips.WriteString(`</cols>`)
+	}
+	clips.WriteString(`<sheetData>`)
+	writeRow(&clips, 1, names, func(int) int { return styleHeader }, nil)
+	for r, row := range rows {
+		writeRow(&clips, r+2, row, func(i int) int {
+			if textColumns[i] {
+				return styleText
+			}
+			return styleDefault
+		}, textColumns)
+	}
+	clips.WriteString(`</sheetData></worksheet>`)
+
+	// Build the header sheet
+	var header bytes.Buffer
+	header.WriteString(xml.Header)
+	header.WriteString(`<worksheet xmlns="` + nsMain + `">`)
+	header.WriteString(`<cols><col min="1" max="2" width="24" customWidth="1"/></cols>`)
+	header.WriteString(`<sheetData>`)
+	allText := []bool{true, true}
+	writeRow(&header, 1, []string{"Key", "Value"}, func(int) int { return styleHeader }, nil)
+	r := 2
+	for _, field := range obj.HeaderFields {
+		if field == nil {
+			continue
+		}
+		writeRow(&header, r, []string{field.GetKey(), field.GetValue()}, func(int) int { return styleText }, allText)
+		r++
+	}
+	header.WriteString(`</sheetData></worksheet>`)
+
+	// Assemble the package
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypesXML)},
+		{"_rels/.rels", []byte(rootRelsXML)},
+		{"xl/workbook.xml", []byte(fmt.Sprintf(workbookXML, escape(opts.SheetName), escape(opts.HeaderSheetName)))},
+		{"xl/_rels/workbook.xml.rels", []byte(workbookRelsXML)},
+		{"xl/styles.xml", []byte(stylesXML)},
+		{"xl/worksheets/sheet1.xml", clips.Bytes()},
+		{"xl/worksheets/sheet2.xml", header.Bytes()},
+	}
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return errors.ErrOutputFailedXLSX.WithContext(err.Error())
+		}
+		if _, err := f.Write(part.data); err != nil {
+			return errors.ErrOutputFailedXLSX.WithContext(err.Error())
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return errors.ErrOutputFailedXLSX.WithContext(err.Error())
+	}
+	return nil
+}
+
+// writeRow writes a row of cells. Values in text columns, and values that are
+// not plain numbers, are written as inline strings.
+func writeRow(buf *bytes.Buffer, r int, values []string, style func(int) int, text []bool) {
+	fmt.Fprintf(buf, `<row r="%d">`, r)
+	for i, value := range values {
+		ref := cellRef(i, r)
+		s := ""
+		if st := style(i); st != styleDefault {
+			s = fmt.Sprintf(` s="%d"`, st)
+		}
+		if value == "" {
+			if s != "" {
+				fmt.Fprintf(buf, `<c r="%s"%s/>`, ref, s)
+			}
+			continue
+		}
+		if text != nil && !text[i] && isPlainNumber(value) {
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, s, value)
+			continue
+		}
+		fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, s, escape(value))
+	}
+	buf.WriteString(`</row>`)
+}
+
+// isTimecodeColumn reports whether every non-empty value in a column is a timecode.
+func isTimecodeColumn(rows [][]string, column int) bool {
+	found := false
+	for _, row := range rows {
+		if row[column] == "" {
+			continue
+		}
+		if !timecodePattern.MatchString(row[column]) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// isPlainNumber reports whether a value survives a round trip through a
+// spreadsheet number unchanged, so "25" is a number but "25.000" and
+// "0021666" are kept as text.
+func isPlainNumber(value string) bool {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64) == value && len(value) < 16
+}
+
+// cellRef returns the A1-style reference of a zero-based column and one-based row.
+func cellRef(column, row int) string {
+	return columnLetters(column) + strconv.Itoa(row)
+}
+
+// columnLetters returns the letters naming a zero-based column: A, B, ... Z, AA, AB ...
+func columnLetters(column int) string {
+	var letters []byte
+	for column >= 0 {
+		letters = append([]byte{byte('A' + column%26)}, letters...)
+		column = column/26 - 1
+	}
+	return string(letters)
+}
+
+// escape escapes text for use in XML content and attributes.
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// withDefaults fills unset options from DefaultOptions.
+func withDefaults(opts Options) Options {
+	defaults := DefaultOptions()
+	if opts.SheetName == "" {
+		opts.SheetName = defaults.SheetName
+	}
+	if opts.HeaderSheetName == "" {
+		opts.HeaderSheetName = defaults.HeaderSheetName
+	}
+	if opts.MaxColumnWidth <= 0 {
+		opts.MaxColumnWidth = defaults.MaxColumnWidth
+	}
+	return opts
+}
+
+// Office Open XML namespaces
+const (
+	nsMain = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRel  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + nsRel + `/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookXML = xml.Header + `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRel + `">` +
+	`<sheets>` +
+	`<sheet name="%s" sheetId="1" r:id="rId1"/>` +
+	`<sheet name="%s" sheetId="2" r:id="rId2"/>` +
+	`</sheets></workbook>`
+
+const workbookRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + nsRel + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="` + nsRel + `/worksheet" Target="worksheets/sheet2.xml"/>` +
+	`<Relationship Id="rId3" Type="` + nsRel + `/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// stylesXML defines the default style, a bold header style and a text ("@") style.
+const stylesXML = xml.Header + `<styleSheet xmlns="` + nsMain + `">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="49" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1" applyNumberFormat="1"/>` +
+	`<xf numFmtId="49" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
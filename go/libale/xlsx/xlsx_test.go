@@ -0,0 +1,107 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libale/ale"
+)
+
+func TestWriteRead(t *testing.T) {
+	obj, err := ale.ReadFile("../../../samples/ALE/A901R1AA_AVID.ale")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, obj, DefaultOptions()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Options{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.ColumnNames(), obj.ColumnNames()) {
+		t.Errorf("Columns = %v, want %v", got.ColumnNames(), obj.ColumnNames())
+	}
+	if len(got.Rows) != len(obj.Rows) {
+		t.Fatalf("Got %d rows, want %d", len(got.Rows), len(obj.Rows))
+	}
+	for i := range obj.Rows {
+		if want, have := obj.Values(obj.Rows[i]), got.Values(got.Rows[i]); !reflect.DeepEqual(have, want) {
+			t.Errorf("Row %d values = %q, want %q", i, have, want)
+		}
+	}
+	if got.FPS.GetValue() != "25" {
+		t.Errorf("FPS = %q, want 25", got.FPS.GetValue())
+	}
+}
+
+func TestWriteSheetLayout(t *testing.T) {
+	obj, err := ale.Read("Heading\nFIELD_DELIM\tTABS\n\nColumn\nName\tStart\tFrame_width\tCamera_sn\n\nData\nA001C001\t01:00:00:00\t3424\t0021666\n")
+	if err != nil {
+		t.Fatalf("Failed to read ALE: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, obj, DefaultOptions()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	sheet := readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+
+	checks := []struct {
+		name string
+		want string
+	}{
+		{"frozen header row", `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`},
+		{"sized column", `<col min="1" max="1" width="10" customWidth="1"/>`},
+		{"timecode column as text", `<col min="2" max="2" width="13" customWidth="1" style="2"/>`},
+		{"timecode cell as text", `<c r="B2" s="2" t="inlineStr"><is><t xml:space="preserve">01:00:00:00</t></is></c>`},
+		{"number cell", `<c r="C2"><v>3424</v></c>`},
+		{"leading zero kept as text", `<c r="D2" t="inlineStr"><is><t xml:space="preserve">0021666</t></is></c>`},
+	}
+	for _, check := range checks {
+		if !strings.Contains(sheet, check.want) {
+			t.Errorf("%s: %q not found in sheet", check.name, check.want)
+		}
+	}
+
+	if header := readPart(t, buf.Bytes(), "xl/worksheets/sheet2.xml"); !strings.Contains(header, ">FIELD_DELIM<") {
+		t.Error("Header fields not found on header sheet")
+	}
+}
+
+func TestColumnLetters(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for column, want := range tests {
+		if got := columnLetters(column); got != want {
+			t.Errorf("columnLetters(%d) = %q, want %q", column, got, want)
+		}
+		if got, ok := parseColumn(want + "1"); !ok || got != column {
+			t.Errorf("parseColumn(%q) = %d, want %d", want+"1", got, column)
+		}
+	}
+}
+
+// readPart returns the content of a named part of a workbook.
+func readPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Invalid zip: %v", err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("Missing part %s: %v", name, err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,36 @@
+package cdl
+
+import (
+	"math"
+
+	"lib-post-interchange/libcdl/types"
+)
+
+// Rec.709 luma weights used by the ASC CDL saturation operation
+const (
+	LumaRed   = 0.2126
+	LumaGreen = 0.7152
+	LumaBlue  = 0.0722
+)
+
+// Apply returns an RGB value graded by a color correction as in the ASC CDL
+// v1.2 specification: slope and offset are applied and the result clamped to
+// [0, 1] before power, then saturation is applied around Rec.709 luma and the
+// result clamped to [0, 1] again.
+func Apply(cc types.ColorCorrection, rgb [3]float64) [3]float64 {
+	var out [3]float64
+	for i, v := range rgb {
+		v = clamp(v*cc.Slope[i] + cc.Offset[i])
+		out[i] = math.Pow(v, cc.Power[i])
+	}
+	luma := LumaRed*out[0] + LumaGreen*out[1] + LumaBlue*out[2]
+	for i, v := range out {
+		out[i] = clamp(luma + cc.Saturation*(v-luma))
+	}
+	return out
+}
+
+// clamp limits a value to [0, 1].
+func clamp(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
@@ -0,0 +1,65 @@
+package cdl
+
+import (
+	"math"
+	"testing"
+
+	"lib-post-interchange/libcdl/types"
+)
+
+func TestApply(t *testing.T) {
+	grade := func(slope, offset, power [3]float64, saturation float64) types.ColorCorrection {
+		return types.ColorCorrection{Slope: slope, Offset: offset, Power: power, Saturation: saturation}
+	}
+	ones := [3]float64{1, 1, 1}
+
+	tests := []struct {
+		name string
+		cc   types.ColorCorrection
+		in   [3]float64
+		want [3]float64
+	}{
+		{
+			name: "identity",
+			cc:   types.NewColorCorrection("identity"),
+			in:   [3]float64{0.1, 0.5, 0.9},
+			want: [3]float64{0.1, 0.5, 0.9},
+		},
+		{
+			name: "slope and offset",
+			cc:   grade([3]float64{2, 1, 0.5}, [3]float64{0, 0.1, -0.1}, ones, 1),
+			in:   [3]float64{0.25, 0.25, 0.5},
+			want: [3]float64{0.5, 0.35, 0.15},
+		},
+		{
+			name: "clamped before power",
+			cc:   grade([3]float64{2, 1, 1}, [3]float64{0, -0.5, 0}, [3]float64{2, 2, 0.5}, 1),
+			in:   [3]float64{0.75, 0.25, 0.25},
+			want: [3]float64{1, 0, 0.5},
+		},
+		{
+			name: "no saturation is Rec.709 luma",
+			cc:   grade(ones, [3]float64{}, ones, 0),
+			in:   [3]float64{1, 0, 0},
+			want: [3]float64{0.2126, 0.2126, 0.2126},
+		},
+		{
+			name: "saturation clamped",
+			cc:   grade(ones, [3]float64{}, ones, 2),
+			in:   [3]float64{1, 0, 0},
+			want: [3]float64{1, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Apply(tt.cc, tt.in)
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("Apply(%v) = %v, want %v", tt.in, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
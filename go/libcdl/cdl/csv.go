@@ -0,0 +1,182 @@
+package cdl
+
+import (
+	stdcsv "encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+	"lib-post-interchange/timecode"
+)
+
+// CSVColumns lists the column names, compared ignoring case and surrounding
+// spaces, that each value of a grade is read from, in order of preference.
+type CSVColumns struct {
+	Name     []string
+	SOP      []string
+	SAT      []string
+	Start    []string
+	End      []string
+	Duration []string
+	FPS      []string
+	Scene    []string
+	Take     []string
+}
+
+// DefaultCSVColumns are the column names written by grading and dailies
+// applications, such as DaVinci Resolve's "CDL Nodes (SOP)" and "SAT Nodes".
+var DefaultCSVColumns = CSVColumns{
+	Name:     []string{"Clip Identifier", "Name / Clip Identifier", "Name"},
+	SOP:      []string{"CDL Nodes (SOP)", "ASC_SOP"},
+	SAT:      []string{"SAT Nodes", "ASC_SAT"},
+	Start:    []string{"Start", "TC Start", "StartTC", "Start TC"},
+	End:      []string{"End", "TC End", "EndTC", "End TC"},
+	Duration: []string{"Duration", "Clip Duration"},
+	FPS:      []string{"FPS", "Project FPS", "Speed"},
+	Scene:    []string{"Scene"},
+	Take:     []string{"Take"},
+}
+
+// CSVOptions controls how grades are read from CSV.
+type CSVOptions struct {
+	Columns   CSVColumns
+	Delimiter rune
+}
+
+// DefaultCSVOptions returns comma separated options with DefaultCSVColumns.
+func DefaultCSVOptions() CSVOptions {
+	return CSVOptions{Columns: DefaultCSVColumns, Delimiter: ','}
+}
+
+// sopSeparator splits a cell holding more than one ASC_SOP value.
+var sopSeparator = regexp.MustCompile(`[\r\n;]+`)
+
+// ReadCSVFile reads grades from a CSV file.
+func ReadCSVFile(path string, opts CSVOptions) (*types.Object, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ReadCSV(string(data), opts)
+}
+
+// ReadCSV reads one grade per CSV record with both SOP and SAT values as a
+// .ccc collection, named by the Name column. Records without both are
+// skipped. A SOP cell may hold several values separated by line breaks or
+// semicolons, which are combined: slopes and powers are multiplied and
+// offsets added. Start, End, Duration, FPS, Scene and Take are kept as the
+// grade's Metadata; a value that does not parse is an error.
+func ReadCSV(input string, opts CSVOptions) (*types.Object, error) {
+	input = strings.TrimPrefix(input, "\ufeff")
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.ErrInputEmpty
+	}
+	r := stdcsv.NewReader(strings.NewReader(input))
+	if opts.Delimiter != 0 {
+		r.Comma = opts.Delimiter
+	}
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.ErrInputMalformedValue.WithContext(err.Error())
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	obj := &types.Object{Format: types.FormatCCC}
+	for {
+		record, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.ErrInputMalformedValue.WithContext(err.Error())
+		}
+		line, _ := r.FieldPos(0)
+		get := func(aliases []string) string {
+			for _, alias := range aliases {
+				if i, ok := index[strings.ToLower(strings.TrimSpace(alias))]; ok && i < len(record) {
+					if v := strings.TrimSpace(record[i]); v != "" {
+						return v
+					}
+				}
+			}
+			return ""
+		}
+
+		sop, sat := get(opts.Columns.SOP), get(opts.Columns.SAT)
+		if sop == "" || sat == "" {
+			continue
+		}
+		cc := types.NewColorCorrection(get(opts.Columns.Name))
+		cc.Line = line
+		if err := combineSOPs(&cc, sop); err != nil {
+			return nil, withLine(err, line)
+		}
+		if cc.Saturation, err = ParseSAT(sat); err != nil {
+			return nil, withLine(err, line)
+		}
+
+		metadata := &types.Metadata{Scene: get(opts.Columns.Scene), Take: get(opts.Columns.Take)}
+		for _, tc := range []struct {
+			name    string
+			columns []string
+			value   *timecode.Timecode
+		}{
+			{"start", opts.Columns.Start, &metadata.Start},
+			{"end", opts.Columns.End, &metadata.End},
+			{"duration", opts.Columns.Duration, &metadata.Duration},
+		} {
+			if s := get(tc.columns); s != "" {
+				if *tc.value, err = timecode.Parse(s); err != nil {
+					return nil, withLine(errors.ErrInputMalformedValue.WithContext(fmt.Sprintf("%s timecode %q", tc.name, s)), line)
+				}
+			}
+		}
+		if s := get(opts.Columns.FPS); s != "" {
+			if metadata.Rate, err = timecode.ParseRate(s); err != nil {
+				return nil, withLine(errors.ErrInputMalformedValue.WithContext(fmt.Sprintf("frame rate %q", s)), line)
+			}
+		}
+		cc.Metadata = metadata
+		obj.Corrections = append(obj.Corrections, cc)
+	}
+	return obj, nil
+}
+
+// combineSOPs sets a correction's slope, offset and power from one or more
+// ASC_SOP values.
+func combineSOPs(cc *types.ColorCorrection, cell string) error {
+	for _, s := range sopSeparator.Split(cell, -1) {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		slope, offset, power, err := ParseSOP(s)
+		if err != nil {
+			return err
+		}
+		for i := range slope {
+			cc.Slope[i] *= slope[i]
+			cc.Offset[i] += offset[i]
+			cc.Power[i] *= power[i]
+		}
+	}
+	return nil
+}
+
+// withLine adds a line number to a CDL error.
+func withLine(err error, line int) error {
+	if cdlErr, ok := err.(*errors.Error); ok {
+		return cdlErr.WithContext(fmt.Sprintf("line %d", line))
+	}
+	return err
+}
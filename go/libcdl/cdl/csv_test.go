@@ -0,0 +1,157 @@
+package cdl
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/timecode"
+)
+
+func TestReadCSV(t *testing.T) {
+	resolve := "\ufeffName / Clip Identifier,CDL Nodes (SOP),SAT Nodes,TC Start,TC End,Project FPS,Scene,Take\n" +
+		"A001C001,(1.1 1 0.95)(0.01 0 -0.01)(1 1 1),0.9,03:44:40:00,03:44:45:00,25,12A,3\n" +
+		"A001C002,,1.0,03:45:50:00,03:45:55:00,25,12A,4\n" +
+		"A001C003,\"(2 1 1)(0.1 0 0)(1 1 1)\n(0.5 1 1)(0 0.1 0)(2 1 1)\",1.0,,,,,\n"
+
+	tests := []struct {
+		name       string
+		input      string
+		opts       CSVOptions
+		wantIDs    []string
+		wantSlope  [3]float64
+		wantOffset [3]float64
+		wantPower  [3]float64
+		wantErr    *errors.Error
+	}{
+		{
+			name:       "Resolve export",
+			input:      resolve,
+			opts:       DefaultCSVOptions(),
+			wantIDs:    []string{"A001C001", "A001C003"},
+			wantSlope:  [3]float64{1.1, 1, 0.95},
+			wantOffset: [3]float64{0.01, 0, -0.01},
+			wantPower:  [3]float64{1, 1, 1},
+		},
+		{
+			name:       "ALE columns, semicolons",
+			input:      "name;asc_sop;asc_sat\nB001C001;(1 1 1)(0 0 0)(1 1 1);0.5\n",
+			opts:       CSVOptions{Columns: DefaultCSVColumns, Delimiter: ';'},
+			wantIDs:    []string{"B001C001"},
+			wantSlope:  [3]float64{1, 1, 1},
+			wantOffset: [3]float64{0, 0, 0},
+			wantPower:  [3]float64{1, 1, 1},
+		},
+		{
+			name:  "custom aliases",
+			input: "Shot,Grade,Sat\nSH010,(1 1 1)(0.2 0 0)(1 1 1),1\n",
+			opts: CSVOptions{Columns: CSVColumns{
+				Name: []string{"Shot"},
+				SOP:  []string{"Grade"},
+				SAT:  []string{"Sat"},
+			}},
+			wantIDs:    []string{"SH010"},
+			wantSlope:  [3]float64{1, 1, 1},
+			wantOffset: [3]float64{0.2, 0, 0},
+			wantPower:  [3]float64{1, 1, 1},
+		},
+		{
+			name:    "empty",
+			input:   "\ufeff \n",
+			opts:    DefaultCSVOptions(),
+			wantErr: errors.ErrInputEmpty,
+		},
+		{
+			name:    "malformed SOP",
+			input:   "Name,ASC_SOP,ASC_SAT\nA,(1 1 1)(0 0 0)(1 1 1),1\nB,(1 1)(0 0 0)(1 1 1),1\n",
+			opts:    DefaultCSVOptions(),
+			wantErr: errors.ErrInputMalformedValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := ReadCSV(tt.input, tt.opts)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("ReadCSV() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadCSV() error = %v", err)
+			}
+			var ids []string
+			for _, cc := range obj.Corrections {
+				ids = append(ids, cc.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Fatalf("ReadCSV() IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			cc := obj.Corrections[0]
+			if cc.Slope != tt.wantSlope || cc.Offset != tt.wantOffset || cc.Power != tt.wantPower {
+				t.Errorf("ReadCSV() SOP = %v %v %v, want %v %v %v", cc.Slope, cc.Offset, cc.Power, tt.wantSlope, tt.wantOffset, tt.wantPower)
+			}
+		})
+	}
+}
+
+func TestReadCSVMetadata(t *testing.T) {
+	input := "Clip Identifier,CDL Nodes (SOP),SAT Nodes,Start TC,End TC,Duration,FPS,Scene,Take\n" +
+		"A001C001,(1 1 1)(0 0 0)(1 1 1),1,03:44:40:00,03:44:45:00,00:00:05:00,25,12A,3\n" +
+		"A001C002,\"(2 1 1)(0.1 0 0)(1 1 1)\n(0.5 1 1)(0 0.1 0)(2 1 1)\",1,,,,,,\n"
+	obj, err := ReadCSV(input, DefaultCSVOptions())
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if len(obj.Corrections) != 2 {
+		t.Fatalf("ReadCSV() got %d corrections, want 2", len(obj.Corrections))
+	}
+
+	first := obj.Corrections[0]
+	if first.Line != 2 {
+		t.Errorf("Line = %d, want 2", first.Line)
+	}
+	m := first.Metadata
+	if m == nil {
+		t.Fatal("Metadata = nil")
+	}
+	if m.Start != timecode.MustParse("03:44:40:00") || m.End != timecode.MustParse("03:44:45:00") || m.Duration != timecode.MustParse("00:00:05:00") {
+		t.Errorf("Metadata timecodes = %v %v %v", m.Start, m.End, m.Duration)
+	}
+	if want, _ := timecode.ParseRate("25"); m.Rate != want {
+		t.Errorf("Metadata.Rate = %v, want %v", m.Rate, want)
+	}
+	if m.Scene != "12A" || m.Take != "3" {
+		t.Errorf("Metadata scene, take = %q, %q, want 12A, 3", m.Scene, m.Take)
+	}
+
+	// Two SOPs in one cell are combined, and missing timecodes are left unknown
+	second := obj.Corrections[1]
+	if second.Slope != [3]float64{1, 1, 1} || second.Offset != [3]float64{0.1, 0.1, 0} || second.Power != [3]float64{2, 1, 1} {
+		t.Errorf("combined SOP = %v %v %v", second.Slope, second.Offset, second.Power)
+	}
+	if second.Metadata.Start != (timecode.Timecode{}) {
+		t.Errorf("Metadata.Start = %v, want unknown", second.Metadata.Start)
+	}
+
+	// Metadata that does not parse is reported with its line
+	for name, row := range map[string]string{
+		"start":      "A001C003,(1 1 1)(0 0 0)(1 1 1),1,later,,,,,",
+		"end":        "A001C003,(1 1 1)(0 0 0)(1 1 1),1,,03:44:45,,,,",
+		"duration":   "A001C003,(1 1 1)(0 0 0)(1 1 1),1,,,5 seconds,,,",
+		"frame rate": "A001C003,(1 1 1)(0 0 0)(1 1 1),1,,,,fast,,",
+	} {
+		header, _, _ := strings.Cut(input, "\n")
+		_, err := ReadCSV(header+"\n"+row+"\n", DefaultCSVOptions())
+		if err == nil || !strings.HasPrefix(err.Error(), errors.ErrInputMalformedValue.Error()) || !strings.Contains(err.Error(), name) || !strings.HasSuffix(err.Error(), "line 2") {
+			t.Errorf("ReadCSV() with a malformed %s error = %v", name, err)
+		}
+	}
+}
+
+func TestReadCSVFile(t *testing.T) {
+	if _, err := ReadCSVFile("../../../samples/CDL/missing.csv", DefaultCSVOptions()); err == nil {
+		t.Error("ReadCSVFile() error = nil for a missing file")
+	}
+}
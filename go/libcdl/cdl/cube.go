@@ -0,0 +1,114 @@
+package cdl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+)
+
+// DefaultCubeSize is the number of points along each axis of a 3D LUT.
+const DefaultCubeSize = 33
+
+// Sizes of a .cube 3D LUT allowed by the format
+const (
+	MinCubeSize = 2
+	MaxCubeSize = 256
+)
+
+// CubeOptions controls how a color correction is baked into a .cube 3D LUT.
+type CubeOptions struct {
+	// Size is the number of points along each axis, commonly 17, 33 or 65.
+	Size int
+	// Title is written as the TITLE line. The zero value means the correction's ID.
+	Title string
+}
+
+// DefaultCubeOptions returns options for a 33 point LUT titled by the correction's ID.
+func DefaultCubeOptions() CubeOptions {
+	return CubeOptions{Size: DefaultCubeSize}
+}
+
+// WriteCube returns a color correction baked into a .cube 3D LUT over the
+// domain [0, 1], with red changing fastest, as read by monitoring devices and
+// grading applications.
+func WriteCube(cc types.ColorCorrection, opts CubeOptions) (string, error) {
+	size := opts.Size
+	if size < MinCubeSize || size > MaxCubeSize {
+		return "", errors.ErrOutputIllegalValue.WithContext(fmt.Sprintf("LUT size %d is not between %d and %d", size, MinCubeSize, MaxCubeSize))
+	}
+	if err := checkValues(cc); err != nil {
+		return "", err
+	}
+	title := opts.Title
+	if title == "" {
+		title = cc.ID
+	}
+
+	var b strings.Builder
+	if title != "" {
+		fmt.Fprintf(&b, "TITLE \"%s\"\n", strings.ReplaceAll(singleLine(title), `"`, "'"))
+	}
+	fmt.Fprintf(&b, "LUT_3D_SIZE %d\n", size)
+	b.WriteString("DOMAIN_MIN 0.0 0.0 0.0\n")
+	b.WriteString("DOMAIN_MAX 1.0 1.0 1.0\n")
+	step := 1 / float64(size-1)
+	for blue := 0; blue < size; blue++ {
+		for green := 0; green < size; green++ {
+			for red := 0; red < size; red++ {
+				out := Apply(cc, [3]float64{float64(red) * step, float64(green) * step, float64(blue) * step})
+				b.WriteString(FormatTriple(out) + "\n")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// WriteCubeFile writes a color correction baked into a .cube 3D LUT to a file.
+func WriteCubeFile(path string, cc types.ColorCorrection, opts CubeOptions) error {
+	data, err := WriteCube(cc, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(data), 0644)
+}
+
+// WriteCubeFiles writes each color correction of an object to its own .cube
+// file in a folder, named by the ID selected by ids. It returns the paths written.
+func WriteCubeFiles(dir string, obj *types.Object, ids IDSource, opts CubeOptions) ([]string, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	paths := make([]string, len(obj.Corrections))
+	seen := make(map[string]bool)
+	for i, cc := range obj.Corrections {
+		id := exportID(cc, ids)
+		if id == "" {
+			return nil, errors.ErrOutputMissingValue.WithContext(fmt.Sprintf("no ID for the color correction on line %d", cc.Line))
+		}
+		name := fileName(id) + ".cube"
+		if seen[strings.ToLower(name)] {
+			return nil, errors.ErrOutputDuplicateID.WithContext(fmt.Sprintf("more than one %s", name))
+		}
+		seen[strings.ToLower(name)] = true
+		paths[i] = filepath.Join(dir, name)
+	}
+	for i, cc := range obj.Corrections {
+		cubeOpts := opts
+		if cubeOpts.Title == "" {
+			cubeOpts.Title = exportID(cc, ids)
+		}
+		if err := WriteCubeFile(paths[i], cc, cubeOpts); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// singleLine collapses runs of whitespace, including line breaks, to single spaces.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
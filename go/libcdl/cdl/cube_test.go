@@ -0,0 +1,66 @@
+package cdl
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+)
+
+func TestWriteCube(t *testing.T) {
+	for _, size := range []int{17, 33, 65} {
+		cube, err := WriteCube(types.NewColorCorrection("A001C001"), CubeOptions{Size: size})
+		if err != nil {
+			t.Fatalf("WriteCube() error = %v", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(cube, "\n"), "\n")
+		header := []string{`TITLE "A001C001"`, "LUT_3D_SIZE " + strconv.Itoa(size), "DOMAIN_MIN 0.0 0.0 0.0", "DOMAIN_MAX 1.0 1.0 1.0"}
+		if strings.Join(lines[:4], "\n") != strings.Join(header, "\n") {
+			t.Errorf("Header = %q, want %q", lines[:4], header)
+		}
+		if got := len(lines) - 4; got != size*size*size {
+			t.Errorf("Size %d: got %d entries, want %d", size, got, size*size*size)
+		}
+		// Red changes fastest and an identity grade maps each point to itself
+		if want := FormatTriple([3]float64{1 / float64(size-1), 0, 0}); lines[5] != want {
+			t.Errorf("Second entry = %q, want %q", lines[5], want)
+		}
+		if last := lines[len(lines)-1]; last != "1.000000 1.000000 1.000000" {
+			t.Errorf("Last entry = %q", last)
+		}
+	}
+
+	cc := types.NewColorCorrection("warm")
+	cc.Slope = [3]float64{1.1, 1, 0.95}
+	cube, err := WriteCube(cc, CubeOptions{Size: 2, Title: "Day 1\nwarm"})
+	if err != nil {
+		t.Fatalf("WriteCube() error = %v", err)
+	}
+	want := "TITLE \"Day 1 warm\"\nLUT_3D_SIZE 2\nDOMAIN_MIN 0.0 0.0 0.0\nDOMAIN_MAX 1.0 1.0 1.0\n" +
+		"0.000000 0.000000 0.000000\n1.000000 0.000000 0.000000\n0.000000 1.000000 0.000000\n1.000000 1.000000 0.000000\n" +
+		"0.000000 0.000000 0.950000\n1.000000 0.000000 0.950000\n0.000000 1.000000 0.950000\n1.000000 1.000000 0.950000\n"
+	if cube != want {
+		t.Errorf("WriteCube() =\n%s\nwant\n%s", cube, want)
+	}
+
+	if _, err := WriteCube(cc, CubeOptions{Size: 1}); err == nil || !strings.HasPrefix(err.Error(), errors.ErrOutputIllegalValue.Error()) {
+		t.Errorf("WriteCube() error = %v, want %v", err, errors.ErrOutputIllegalValue)
+	}
+}
+
+func TestWriteCubeFiles(t *testing.T) {
+	obj, err := ReadFile("../../../samples/CDL/A001R1AA.cdl")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	paths, err := WriteCubeFiles(t.TempDir(), obj, IDOriginal, CubeOptions{Size: 17})
+	if err != nil {
+		t.Fatalf("WriteCubeFiles() error = %v", err)
+	}
+	want := []string{"A001C001_240426_R1AA.cube", "A001C002_240426_R1AA.cube"}
+	if got := baseNames(paths); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("WriteCubeFiles() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package cdl
+
+import (
+	"fmt"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+	"lib-post-interchange/libedl/edl"
+	edltypes "lib-post-interchange/libedl/types"
+)
+
+// ReadEDLFile reads grades from the ASC_SOP and ASC_SAT comments of an EDL file.
+func ReadEDLFile(path string) (*types.Object, error) {
+	list, err := edl.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromEDL(list)
+}
+
+// FromEDL returns the grades of the video events of an EDL with ASC_SOP or
+// ASC_SAT comments as a .ccc collection. Each is named by the event's clip
+// name, the incoming clip of a transition, or else its reel, and keeps the
+// reel and source timecodes. A grade repeated for the same name, as when a
+// clip is cut in more than once, is kept once. A clip graded differently in
+// two events gives two corrections with the same ID; their export IDs, the
+// ID and event number, are unique.
+func FromEDL(list *edltypes.Object) (*types.Object, error) {
+	if list == nil {
+		return nil, errors.ErrInputEmpty.WithContext("nil EDL object")
+	}
+	obj := &types.Object{Format: types.FormatCCC}
+	for _, event := range list.Events {
+		if !event.HasVideo() || (event.ASCSOP == nil && event.ASCSAT == nil) {
+			continue
+		}
+		id := event.ClipName
+		if event.ToClipName != "" {
+			id = event.ToClipName
+		}
+		if id == "" {
+			id = event.Reel
+		}
+		cc := types.NewColorCorrection(id)
+		cc.ExportID = fmt.Sprintf("%s_%03d", id, event.Number)
+		cc.Reel = event.Reel
+		cc.Line = event.Line
+		cc.Metadata = &types.Metadata{Start: event.SourceIn, End: event.SourceOut}
+		if sop := event.ASCSOP; sop != nil {
+			cc.Slope, cc.Offset, cc.Power = sop.Slope, sop.Offset, sop.Power
+		}
+		if event.ASCSAT != nil {
+			cc.Saturation = *event.ASCSAT
+		}
+		if problem := gradeProblem(cc); problem != "" {
+			return nil, errors.ErrInputValueOutOfRange.WithContext(fmt.Sprintf("line %d: %s", event.Line, problem))
+		}
+		if repeated(obj.Corrections, cc) {
+			continue
+		}
+		obj.Corrections = append(obj.Corrections, cc)
+	}
+	return obj, nil
+}
+
+// repeated reports whether a correction with the same ID and grade is in a list.
+func repeated(corrections []types.ColorCorrection, cc types.ColorCorrection) bool {
+	for _, earlier := range corrections {
+		if earlier.ID == cc.ID && earlier.Slope == cc.Slope && earlier.Offset == cc.Offset && earlier.Power == cc.Power && earlier.Saturation == cc.Saturation {
+			return true
+		}
+	}
+	return false
+}
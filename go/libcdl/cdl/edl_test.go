@@ -0,0 +1,106 @@
+package cdl
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libedl/edl"
+	"lib-post-interchange/timecode"
+)
+
+func TestReadEDLFile(t *testing.T) {
+	obj, err := ReadEDLFile("../../../samples/EDL/A001R1AA_CUT.edl")
+	if err != nil {
+		t.Fatalf("ReadEDLFile() error = %v", err)
+	}
+	// Event 003 refers to a separate CDL file and event 004 is audio
+	if len(obj.Corrections) != 2 {
+		t.Fatalf("ReadEDLFile() got %d corrections, want 2", len(obj.Corrections))
+	}
+
+	tests := []struct {
+		id         string
+		exportID   string
+		line       int
+		start      string
+		end        string
+		slope      [3]float64
+		saturation float64
+	}{
+		{"A001C001_240426_R1AA", "A001C001_240426_R1AA_001", 4, "03:44:40:00", "03:44:45:00", [3]float64{1, 1, 1}, 1},
+		{"A001C002_240426_R1AA", "A001C002_240426_R1AA_002", 11, "03:45:50:00", "03:45:55:00", [3]float64{1.1, 1, 0.95}, 0.9},
+	}
+	for i, tt := range tests {
+		cc := obj.Corrections[i]
+		if cc.ID != tt.id || cc.ExportID != tt.exportID || cc.Reel != "A001R1AA" || cc.Line != tt.line {
+			t.Errorf("correction %d = %q %q %q line %d, want %q %q A001R1AA line %d", i, cc.ID, cc.ExportID, cc.Reel, cc.Line, tt.id, tt.exportID, tt.line)
+		}
+		if cc.Slope != tt.slope || cc.Saturation != tt.saturation {
+			t.Errorf("correction %d grade = %v %v, want %v %v", i, cc.Slope, cc.Saturation, tt.slope, tt.saturation)
+		}
+		if cc.Metadata == nil || cc.Metadata.Start != timecode.MustParse(tt.start) || cc.Metadata.End != timecode.MustParse(tt.end) {
+			t.Errorf("correction %d metadata = %+v, want %s-%s", i, cc.Metadata, tt.start, tt.end)
+		}
+	}
+}
+
+func TestFromEDL(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantIDs []string
+		wantErr *errors.Error
+	}{
+		{
+			name: "repeated grade",
+			input: "001  A001 V     C        01:00:00:00 01:00:01:00 10:00:00:00 10:00:01:00\n" +
+				"* FROM CLIP NAME: CLIP1\n*ASC_SAT 0.8\n" +
+				"002  A001 V     C        01:00:02:00 01:00:03:00 10:00:01:00 10:00:02:00\n" +
+				"* FROM CLIP NAME: CLIP1\n*ASC_SAT 0.8\n",
+			wantIDs: []string{"CLIP1"},
+		},
+		{
+			name: "regraded clip and reel fallback",
+			input: "001  A001 V     C        01:00:00:00 01:00:01:00 10:00:00:00 10:00:01:00\n" +
+				"* FROM CLIP NAME: CLIP1\n*ASC_SAT 0.8\n" +
+				"002  A001 V     C        01:00:02:00 01:00:03:00 10:00:01:00 10:00:02:00\n" +
+				"* FROM CLIP NAME: CLIP1\n*ASC_SAT 0.7\n" +
+				"003  B001 V     C        01:00:02:00 01:00:03:00 10:00:02:00 10:00:03:00\n" +
+				"*ASC_SAT 0.7\n",
+			wantIDs: []string{"CLIP1", "CLIP1", "B001"},
+		},
+		{
+			name: "out of range",
+			input: "001  A001 V     C        01:00:00:00 01:00:01:00 10:00:00:00 10:00:01:00\n" +
+				"*ASC_SAT -0.5\n",
+			wantErr: errors.ErrInputValueOutOfRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := edl.Read(tt.input)
+			if err != nil {
+				t.Fatalf("edl.Read() error = %v", err)
+			}
+			obj, err := FromEDL(list)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("FromEDL() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromEDL() error = %v", err)
+			}
+			var ids []string
+			for _, cc := range obj.Corrections {
+				ids = append(ids, cc.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("FromEDL() IDs = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package cdl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+)
+
+// Element names of the ASC CDL schema
+const (
+	elementColorCorrection    = "ColorCorrection"
+	elementCollection         = "ColorCorrectionCollection"
+	elementDecisionList       = "ColorDecisionList"
+	elementDecision           = "ColorDecision"
+	elementDescription        = "Description"
+	elementInputDescription   = "InputDescription"
+	elementViewingDescription = "ViewingDescription"
+	elementSOPNode            = "SOPNode"
+	elementSATNode            = "SATNode"
+	// elementSatNode is the spelling of SATNode in v1.01 documents
+	elementSatNode    = "SatNode"
+	elementSlope      = "Slope"
+	elementOffset     = "Offset"
+	elementPower      = "Power"
+	elementSaturation = "Saturation"
+)
+
+// node is an XML element with the position it was read from.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	text     strings.Builder
+	children []*node
+	line     int
+	column   int
+}
+
+// attr returns the value of an attribute without a namespace.
+func (n *node) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// value returns the element's text without surrounding whitespace.
+func (n *node) value() string {
+	return strings.TrimSpace(n.text.String())
+}
+
+// errorf returns err with the element's position and name as context.
+func (n *node) errorf(err *errors.Error, format string, args ...any) *errors.Error {
+	return err.WithContext(fmt.Sprintf("line %d, column %d: <%s>: %s", n.line, n.column, n.name.Local, fmt.Sprintf(format, args...)))
+}
+
+// ReadFile reads and parses an ASC CDL file from the filesystem. A color
+// correction without an id attribute takes the file name without extension
+// as its ID.
+func ReadFile(path string) (*types.Object, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := Read(string(data))
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for i := range obj.Corrections {
+		if obj.Corrections[i].ID == "" {
+			obj.Corrections[i].ID = name
+		}
+	}
+	return obj, nil
+}
+
+// Read parses an ASC CDL document from a string. The root element may be a
+// ColorCorrection (.cc), a ColorCorrectionCollection (.ccc) or a
+// ColorDecisionList (.cdl), in any namespace. ColorCorrectionRef elements,
+// which refer to grades in other files, are skipped. SOPNode or SATNode may
+// be left out, giving an identity slope, offset and power or saturation.
+func Read(input string) (*types.Object, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.ErrInputEmpty
+	}
+	root, err := parseTree(input)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &types.Object{Namespace: root.name.Space}
+	switch root.name.Local {
+	case elementColorCorrection:
+		obj.Format = types.FormatCC
+		cc, err := readColorCorrection(root)
+		if err != nil {
+			return nil, err
+		}
+		obj.Corrections = append(obj.Corrections, cc)
+
+	case elementCollection, elementDecisionList:
+		obj.Format = types.FormatCCC
+		if root.name.Local == elementDecisionList {
+			obj.Format = types.FormatCDL
+		}
+		for _, child := range root.children {
+			switch child.name.Local {
+			case elementDescription:
+				obj.Descriptions = append(obj.Descriptions, child.value())
+			case elementInputDescription:
+				obj.InputDescription = child.value()
+			case elementViewingDescription:
+				obj.ViewingDescription = child.value()
+			case elementColorCorrection:
+				if obj.Format != types.FormatCCC {
+					return nil, child.errorf(errors.ErrInputMalformedNode, "not inside a ColorDecision")
+				}
+				cc, err := readColorCorrection(child)
+				if err != nil {
+					return nil, err
+				}
+				obj.Corrections = append(obj.Corrections, cc)
+			case elementDecision:
+				if obj.Format != types.FormatCDL {
+					return nil, child.errorf(errors.ErrInputMalformedNode, "only allowed in a ColorDecisionList")
+				}
+				for _, grandchild := range child.children {
+					if grandchild.name.Local != elementColorCorrection {
+						continue
+					}
+					cc, err := readColorCorrection(grandchild)
+					if err != nil {
+						return nil, err
+					}
+					obj.Corrections = append(obj.Corrections, cc)
+				}
+			}
+		}
+
+	default:
+		return nil, root.errorf(errors.ErrInputUnsupportedRoot, "expected ColorCorrection, ColorCorrectionCollection or ColorDecisionList")
+	}
+	return obj, nil
+}
+
+// parseTree reads an XML document into a tree of elements, keeping the
+// position of each.
+func parseTree(input string) (*node, error) {
+	decoder := xml.NewDecoder(strings.NewReader(input))
+	var root *node
+	var stack []*node
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.ErrInputMalformedXML.WithContext(err.Error())
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			line, column := decoder.InputPos()
+			n := &node{name: t.Name, attrs: t.Attr, line: line, column: column}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, n.errorf(errors.ErrInputMalformedXML, "more than one root element")
+				}
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.ErrInputMalformedXML.WithContext("no root element")
+	}
+	return root, nil
+}
+
+// readColorCorrection reads a ColorCorrection element.
+func readColorCorrection(n *node) (types.ColorCorrection, error) {
+	cc := types.NewColorCorrection(n.attr("id"))
+	cc.Line = n.line
+	var sop, sat *node
+	for _, child := range n.children {
+		switch child.name.Local {
+		case elementDescription:
+			cc.Descriptions = append(cc.Descriptions, child.value())
+		case elementInputDescription:
+			cc.InputDescription = child.value()
+		case elementViewingDescription:
+			cc.ViewingDescription = child.value()
+		case elementSOPNode:
+			if sop != nil {
+				return cc, child.errorf(errors.ErrInputMalformedNode, "more than one in ColorCorrection")
+			}
+			sop = child
+		case elementSATNode, elementSatNode:
+			if sat != nil {
+				return cc, child.errorf(errors.ErrInputMalformedNode, "more than one in ColorCorrection")
+			}
+			sat = child
+		}
+	}
+
+	if sop != nil {
+		values := map[string]*[3]float64{elementSlope: &cc.Slope, elementOffset: &cc.Offset, elementPower: &cc.Power}
+		for _, name := range []string{elementSlope, elementOffset, elementPower} {
+			child := childNamed(sop, name)
+			if child == nil {
+				return cc, sop.errorf(errors.ErrInputMalformedNode, "missing %s", name)
+			}
+			triple, err := readTriple(child)
+			if err != nil {
+				return cc, err
+			}
+			*values[name] = triple
+		}
+	}
+	if sat != nil {
+		child := childNamed(sat, elementSaturation)
+		if child == nil {
+			return cc, sat.errorf(errors.ErrInputMalformedNode, "missing Saturation")
+		}
+		saturation, err := strconv.ParseFloat(child.value(), 64)
+		if err != nil {
+			return cc, child.errorf(errors.ErrInputMalformedNode, "%q is not a number", child.value())
+		}
+		if err := checkRange(child, saturation); err != nil {
+			return cc, err
+		}
+		cc.Saturation = saturation
+	}
+	return cc, nil
+}
+
+// childNamed returns the first child element with a local name.
+func childNamed(n *node, name string) *node {
+	for _, child := range n.children {
+		if child.name.Local == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// readTriple reads the red, green and blue values of a Slope, Offset or Power element.
+func readTriple(n *node) ([3]float64, error) {
+	var triple [3]float64
+	fields := strings.Fields(n.value())
+	if len(fields) != 3 {
+		return triple, n.errorf(errors.ErrInputMalformedNode, "%q is not three numbers", n.value())
+	}
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return triple, n.errorf(errors.ErrInputMalformedNode, "%q is not a number", field)
+		}
+		if err := checkRange(n, v); err != nil {
+			return triple, err
+		}
+		triple[i] = v
+	}
+	return triple, nil
+}
+
+// checkRange validates a value of a Slope, Offset, Power or Saturation element.
+func checkRange(n *node, v float64) error {
+	if problem := rangeProblem(n.name.Local, v); problem != "" {
+		return n.errorf(errors.ErrInputValueOutOfRange, "%s", problem)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package cdl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+)
+
+func TestReadFile(t *testing.T) {
+	warm := types.ColorCorrection{
+		ID:         "A001C002_240426_R1AA",
+		Slope:      [3]float64{1.1, 1, 0.95},
+		Offset:     [3]float64{0.01, 0, -0.01},
+		Power:      [3]float64{1, 1, 1},
+		Saturation: 0.9,
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		wantFormat    types.Format
+		wantNamespace string
+		wantCount     int
+		wantLine      int
+		wantDesc      []string
+	}{
+		{
+			name:          "collection",
+			path:          "../../../samples/CDL/A001R1AA.ccc",
+			wantFormat:    types.FormatCCC,
+			wantNamespace: types.NamespaceV12,
+			wantCount:     2,
+			wantLine:      16,
+			wantDesc:      []string{"warm up"},
+		},
+		{
+			name:          "decision list",
+			path:          "../../../samples/CDL/A001R1AA.cdl",
+			wantFormat:    types.FormatCDL,
+			wantNamespace: types.NamespaceV101,
+			wantCount:     2,
+			wantLine:      16,
+		},
+		{
+			name:          "single correction named by file",
+			path:          "../../../samples/CDL/A001C002_240426_R1AA.cc",
+			wantFormat:    types.FormatCC,
+			wantNamespace: types.NamespaceV12,
+			wantCount:     1,
+			wantLine:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := ReadFile(tt.path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if obj.Format != tt.wantFormat || obj.Namespace != tt.wantNamespace {
+				t.Errorf("Format = %q, Namespace = %q", obj.Format, obj.Namespace)
+			}
+			if len(obj.Corrections) != tt.wantCount {
+				t.Fatalf("Got %d corrections, want %d", len(obj.Corrections), tt.wantCount)
+			}
+			got := obj.Corrections[tt.wantCount-1]
+			want := warm
+			want.Line = tt.wantLine
+			want.Descriptions = tt.wantDesc
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Last correction = %+v, want %+v", got, want)
+			}
+			if tt.wantCount > 1 && !obj.Corrections[0].IsIdentity() {
+				t.Errorf("First correction is not identity: %+v", obj.Corrections[0])
+			}
+		})
+	}
+
+	obj, err := ReadFile("../../../samples/CDL/A001R1AA.ccc")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(obj.Descriptions, []string{"A001R1AA dailies grades"}) || obj.InputDescription != "ARRI LogC3" || obj.ViewingDescription != "Rec.709" {
+		t.Errorf("Descriptions = %q, %q, %q", obj.Descriptions, obj.InputDescription, obj.ViewingDescription)
+	}
+}
+
+func TestRead(t *testing.T) {
+	const sop = "<SOPNode><Slope>1 1 1</Slope><Offset>0 0 0</Offset><Power>1 1 1</Power></SOPNode>"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []types.ColorCorrection
+		wantErr *errors.Error
+		wantMsg string
+	}{
+		{
+			name:  "identity defaults without SOP or SAT node",
+			input: `<ColorCorrection id="a"/>`,
+			want:  []types.ColorCorrection{{ID: "a", Slope: [3]float64{1, 1, 1}, Power: [3]float64{1, 1, 1}, Saturation: 1, Line: 1}},
+		},
+		{
+			name: "references are skipped",
+			input: "<ColorDecisionList>\n<ColorDecision><ColorCorrectionRef ref=\"x\"/></ColorDecision>\n" +
+				"<ColorDecision><ColorCorrection id=\"b\"><SATNode><Saturation> 0.5 </Saturation></SATNode></ColorCorrection></ColorDecision>\n</ColorDecisionList>",
+			want: []types.ColorCorrection{{ID: "b", Slope: [3]float64{1, 1, 1}, Power: [3]float64{1, 1, 1}, Saturation: 0.5, Line: 3}},
+		},
+		{
+			name:    "empty",
+			input:   " \n",
+			wantErr: errors.ErrInputEmpty,
+		},
+		{
+			name:    "not XML",
+			input:   "<ColorCorrection><SOPNode></ColorCorrection>",
+			wantErr: errors.ErrInputMalformedXML,
+		},
+		{
+			name:    "unsupported root",
+			input:   "<ColorCorrections/>",
+			wantErr: errors.ErrInputUnsupportedRoot,
+		},
+		{
+			name:    "missing power",
+			input:   "<ColorCorrection>\n<SOPNode><Slope>1 1 1</Slope><Offset>0 0 0</Offset></SOPNode></ColorCorrection>",
+			wantErr: errors.ErrInputMalformedNode,
+			wantMsg: "line 2, column 10: <SOPNode>: missing Power",
+		},
+		{
+			name:    "two values",
+			input:   "<ColorCorrection><SOPNode><Slope>1 1</Slope><Offset>0 0 0</Offset><Power>1 1 1</Power></SOPNode></ColorCorrection>",
+			wantErr: errors.ErrInputMalformedNode,
+		},
+		{
+			name:    "not a number",
+			input:   "<ColorCorrectionCollection><ColorCorrection>\n<SOPNode><Slope>1 1 x</Slope><Offset>0 0 0</Offset><Power>1 1 1</Power></SOPNode></ColorCorrection></ColorCorrectionCollection>",
+			wantErr: errors.ErrInputMalformedNode,
+			wantMsg: `line 2, column 17: <Slope>: "x" is not a number`,
+		},
+		{
+			name:    "negative slope",
+			input:   "<ColorCorrection><SOPNode><Slope>1 -1 1</Slope><Offset>0 0 0</Offset><Power>1 1 1</Power></SOPNode></ColorCorrection>",
+			wantErr: errors.ErrInputValueOutOfRange,
+		},
+		{
+			name:    "zero power",
+			input:   "<ColorCorrection><SOPNode><Slope>1 1 1</Slope><Offset>0 0 0</Offset><Power>1 0 1</Power></SOPNode></ColorCorrection>",
+			wantErr: errors.ErrInputValueOutOfRange,
+		},
+		{
+			name:    "negative saturation",
+			input:   "<ColorCorrection>" + sop + "<SATNode><Saturation>-0.1</Saturation></SATNode></ColorCorrection>",
+			wantErr: errors.ErrInputValueOutOfRange,
+		},
+		{
+			name:    "missing saturation",
+			input:   "<ColorCorrection>" + sop + "<SATNode/></ColorCorrection>",
+			wantErr: errors.ErrInputMalformedNode,
+		},
+		{
+			name:    "correction outside decision",
+			input:   "<ColorDecisionList><ColorCorrection/></ColorDecisionList>",
+			wantErr: errors.ErrInputMalformedNode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := Read(tt.input)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantMsg != "" && !strings.HasSuffix(err.Error(), tt.wantMsg) {
+					t.Errorf("Read() error = %v, want position %q", err, tt.wantMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(obj.Corrections, tt.want) {
+				t.Errorf("Corrections = %+v, want %+v", obj.Corrections, tt.want)
+			}
+		})
+	}
+}
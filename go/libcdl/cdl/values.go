@@ -0,0 +1,87 @@
+package cdl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+	"lib-post-interchange/libedl/edl"
+	edlerrors "lib-post-interchange/libedl/errors"
+	edltypes "lib-post-interchange/libedl/types"
+)
+
+// ParseSOP parses an ASC_SOP value as written in ALE columns and EDL
+// comments, "(1.000 1.000 1.000)(0.000 0.000 0.000)(1.000 1.000 1.000)",
+// into slope, offset and power, with edl.ParseSOP. Values are validated as
+// in a ColorCorrection.
+func ParseSOP(s string) (slope, offset, power [3]float64, err error) {
+	sop, kind, problem := edl.ParseSOPProblem(s)
+	switch kind {
+	case nil:
+		return sop.Slope, sop.Offset, sop.Power, nil
+	case edlerrors.ErrInputASCOutOfRange:
+		return slope, offset, power, errors.ErrInputValueOutOfRange.WithContext(problem)
+	}
+	return slope, offset, power, errors.ErrInputMalformedValue.WithContext(problem)
+}
+
+// ParseSAT parses an ASC_SAT value, which may be in parentheses.
+func ParseSAT(s string) (float64, error) {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "(") && strings.HasSuffix(trimmed, ")") {
+		trimmed = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+	}
+	v, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil {
+		return 0, errors.ErrInputMalformedValue.WithContext(fmt.Sprintf("ASC_SAT %q is not a number", s))
+	}
+	if problem := rangeProblem(elementSaturation, v); problem != "" {
+		return 0, errors.ErrInputValueOutOfRange.WithContext(fmt.Sprintf("ASC_SAT %q: %s", s, problem))
+	}
+	return v, nil
+}
+
+// gradeProblem describes the first value of a correction that is out of
+// range, or returns "" if all are valid.
+func gradeProblem(cc types.ColorCorrection) string {
+	for _, field := range []struct {
+		name   string
+		values []float64
+	}{
+		{elementSlope, cc.Slope[:]},
+		{elementOffset, cc.Offset[:]},
+		{elementPower, cc.Power[:]},
+		{elementSaturation, []float64{cc.Saturation}},
+	} {
+		for _, v := range field.values {
+			if problem := rangeProblem(field.name, v); problem != "" {
+				return field.name + " " + problem
+			}
+		}
+	}
+	return ""
+}
+
+// rangeProblem describes why a value is not valid for a Slope, Offset, Power
+// or Saturation, or returns "" if it is. All must be finite; slope and
+// saturation may not be negative and power must be positive.
+func rangeProblem(name string, v float64) string {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return fmt.Sprintf("%v is not finite", v)
+	case name == elementPower && v <= 0:
+		return fmt.Sprintf("%v is not positive", v)
+	case (name == elementSlope || name == elementSaturation) && v < 0:
+		return fmt.Sprintf("%v is negative", v)
+	}
+	return ""
+}
+
+// FormatSOP formats slope, offset and power as an ASC_SOP value with
+// edl.FormatSOP, which writes Decimals decimal places.
+func FormatSOP(slope, offset, power [3]float64) string {
+	return edl.FormatSOP(edltypes.SOP{Slope: slope, Offset: offset, Power: power})
+}
@@ -0,0 +1,98 @@
+package cdl
+
+import (
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libcdl/errors"
+)
+
+func TestParseSOP(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantSlope  [3]float64
+		wantOffset [3]float64
+		wantErr    *errors.Error
+	}{
+		{
+			name:       "ALE column",
+			input:      "(1.000 1.000 1.000)(0.000 0.000 0.000)(1.000 1.000 1.000)",
+			wantSlope:  [3]float64{1, 1, 1},
+			wantOffset: [3]float64{0, 0, 0},
+		},
+		{
+			name:       "spaced and signed",
+			input:      " (1.1 1 0.95) (+0.01 0 -0.01) (1 1 1) ",
+			wantSlope:  [3]float64{1.1, 1, 0.95},
+			wantOffset: [3]float64{0.01, 0, -0.01},
+		},
+		{
+			name:    "two triples",
+			input:   "(1 1 1)(0 0 0)",
+			wantErr: errors.ErrInputMalformedValue,
+		},
+		{
+			name:    "two values",
+			input:   "(1 1)(0 0 0)(1 1 1)",
+			wantErr: errors.ErrInputMalformedValue,
+		},
+		{
+			name:    "not a number",
+			input:   "(1 1 1)(0 0 zero)(1 1 1)",
+			wantErr: errors.ErrInputMalformedValue,
+		},
+		{
+			name:    "zero power",
+			input:   "(1 1 1)(0 0 0)(1 0 1)",
+			wantErr: errors.ErrInputValueOutOfRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slope, offset, _, err := ParseSOP(tt.input)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()+": ASC_SOP ") {
+					t.Errorf("ParseSOP() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSOP() error = %v", err)
+			}
+			if slope != tt.wantSlope || offset != tt.wantOffset {
+				t.Errorf("ParseSOP() = %v, %v, want %v, %v", slope, offset, tt.wantSlope, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestParseSAT(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr *errors.Error
+	}{
+		{input: "1.000", want: 1},
+		{input: "(0.85)", want: 0.85},
+		{input: "", wantErr: errors.ErrInputMalformedValue},
+		{input: "high", wantErr: errors.ErrInputMalformedValue},
+		{input: "-0.5", wantErr: errors.ErrInputValueOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSAT(tt.input)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("ParseSAT() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSAT() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package cdl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+)
+
+// Decimals is the number of decimal places values are written with.
+const Decimals = 6
+
+// xmlDeclaration begins every document written.
+const xmlDeclaration = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+// IDSource selects which of a color correction's IDs is written.
+type IDSource int
+
+const (
+	// IDOriginal writes the ID the correction was read or created with.
+	IDOriginal IDSource = iota
+	// IDExport writes the export ID, or the original ID if it has none.
+	IDExport
+	// IDCCC writes the CCC ID, or else the export ID or the original ID.
+	IDCCC
+)
+
+// WriteOptions controls how CDL documents are written.
+type WriteOptions struct {
+	// IDs selects which ID of each correction is written.
+	IDs IDSource
+	// Namespace is the xmlns of the root element. The zero value means
+	// NamespaceV101 for a .cdl and NamespaceV12 otherwise.
+	Namespace string
+}
+
+// DefaultWriteOptions returns options writing original IDs in the default namespace of each format.
+func DefaultWriteOptions() WriteOptions {
+	return WriteOptions{IDs: IDOriginal}
+}
+
+// WriteFile writes an object to a file as a document of its format.
+func WriteFile(path string, obj *types.Object) error {
+	return WriteFileWithOptions(path, obj, DefaultWriteOptions())
+}
+
+// WriteFileWithOptions writes an object to a file as a document of its format.
+func WriteFileWithOptions(path string, obj *types.Object, opts WriteOptions) error {
+	data, err := WriteWithOptions(obj, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(data), 0644)
+}
+
+// Write returns an object as a document of its format.
+func Write(obj *types.Object) (string, error) {
+	return WriteWithOptions(obj, DefaultWriteOptions())
+}
+
+// WriteWithOptions returns an object as a .cc, .ccc or .cdl document
+// according to its Format. Values are written with Decimals decimal places,
+// indented with tabs. IDs must be unique within a collection or list.
+func WriteWithOptions(obj *types.Object, opts WriteOptions) (string, error) {
+	if obj == nil {
+		return "", errors.ErrOutputNilObject
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = types.NamespaceV12
+		if obj.Format == types.FormatCDL {
+			namespace = types.NamespaceV101
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, cc := range obj.Corrections {
+		id := exportID(cc, opts.IDs)
+		if id != "" && seen[id] {
+			return "", errors.ErrOutputDuplicateID.WithContext(id)
+		}
+		seen[id] = true
+		if err := checkValues(cc); err != nil {
+			return "", err
+		}
+	}
+
+	w := &writer{namespace: namespace}
+	w.WriteString(xmlDeclaration)
+	switch obj.Format {
+	case types.FormatCC:
+		if len(obj.Corrections) != 1 {
+			return "", errors.ErrOutputCorrectionCount.WithContext(fmt.Sprintf("got %d", len(obj.Corrections)))
+		}
+		w.colorCorrection(0, obj.Corrections[0], exportID(obj.Corrections[0], opts.IDs), true)
+
+	case types.FormatCCC:
+		w.open(0, elementCollection, "xmlns", namespace)
+		w.descriptions(1, obj.Descriptions, obj.InputDescription, obj.ViewingDescription)
+		for _, cc := range obj.Corrections {
+			w.colorCorrection(1, cc, exportID(cc, opts.IDs), false)
+		}
+		w.close(0, elementCollection)
+
+	case types.FormatCDL:
+		w.open(0, elementDecisionList, "xmlns", namespace)
+		w.descriptions(1, obj.Descriptions, obj.InputDescription, obj.ViewingDescription)
+		for _, cc := range obj.Corrections {
+			w.open(1, elementDecision)
+			w.colorCorrection(2, cc, exportID(cc, opts.IDs), false)
+			w.close(1, elementDecision)
+		}
+		w.close(0, elementDecisionList)
+
+	default:
+		return "", errors.ErrOutputUnknownFormat.WithContext(fmt.Sprintf("%q", obj.Format))
+	}
+	return w.String(), nil
+}
+
+// WriteFilesPerClip writes each color correction of an object to its own
+// .cc file in a folder, named by the ID written. It returns the paths written.
+func WriteFilesPerClip(dir string, obj *types.Object, opts WriteOptions) ([]string, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	var documents []*types.Object
+	var names []string
+	for _, cc := range obj.Corrections {
+		id := exportID(cc, opts.IDs)
+		if id == "" {
+			return nil, errors.ErrOutputMissingValue.WithContext(fmt.Sprintf("no ID for the color correction on line %d", cc.Line))
+		}
+		documents = append(documents, &types.Object{Format: types.FormatCC, Corrections: []types.ColorCorrection{cc}})
+		names = append(names, id)
+	}
+	return writeFiles(dir, documents, names, opts)
+}
+
+// WriteFilesPerReel writes the color corrections of an object to one .ccc
+// collection per Reel in a folder, named by the reel, in the order reels are
+// first seen. It returns the paths written.
+func WriteFilesPerReel(dir string, obj *types.Object, opts WriteOptions) ([]string, error) {
+	if obj == nil {
+		return nil, errors.ErrOutputNilObject
+	}
+	var documents []*types.Object
+	var names []string
+	byReel := make(map[string]*types.Object)
+	for _, cc := range obj.Corrections {
+		if cc.Reel == "" {
+			return nil, errors.ErrOutputMissingValue.WithContext(fmt.Sprintf("no reel for color correction %q", cc.ID))
+		}
+		document, ok := byReel[cc.Reel]
+		if !ok {
+			document = &types.Object{
+				Format:             types.FormatCCC,
+				Descriptions:       obj.Descriptions,
+				InputDescription:   obj.InputDescription,
+				ViewingDescription: obj.ViewingDescription,
+			}
+			byReel[cc.Reel] = document
+			documents = append(documents, document)
+			names = append(names, cc.Reel)
+		}
+		document.Corrections = append(document.Corrections, cc)
+	}
+	return writeFiles(dir, documents, names, opts)
+}
+
+// writeFiles writes documents to a folder, named from names with the
+// extension of their format. Names that would write the same file are an error.
+func writeFiles(dir string, documents []*types.Object, names []string, opts WriteOptions) ([]string, error) {
+	paths := make([]string, len(documents))
+	seen := make(map[string]bool)
+	for i, document := range documents {
+		name := fileName(names[i]) + "." + string(document.Format)
+		if seen[strings.ToLower(name)] {
+			return nil, errors.ErrOutputDuplicateID.WithContext(fmt.Sprintf("more than one %s", name))
+		}
+		seen[strings.ToLower(name)] = true
+		paths[i] = filepath.Join(dir, name)
+	}
+	for i, document := range documents {
+		if err := WriteFileWithOptions(paths[i], document, opts); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// fileName replaces characters that are not allowed in file names.
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// exportID returns the ID of a correction selected by source.
+func exportID(cc types.ColorCorrection, source IDSource) string {
+	if source == IDCCC && cc.CCCID != "" {
+		return cc.CCCID
+	}
+	if source != IDOriginal && cc.ExportID != "" {
+		return cc.ExportID
+	}
+	return cc.ID
+}
+
+// checkValues ensures a correction's values can be written.
+func checkValues(cc types.ColorCorrection) error {
+	values := append(append(append([]float64{cc.Saturation}, cc.Slope[:]...), cc.Offset[:]...), cc.Power[:]...)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.ErrOutputIllegalValue.WithContext(fmt.Sprintf("%q: %v", cc.ID, v))
+		}
+	}
+	return nil
+}
+
+// FormatValue formats a value with Decimals decimal places.
+func FormatValue(v float64) string {
+	s := strconv.FormatFloat(v, 'f', Decimals, 64)
+	// Values that round to zero are written without a sign
+	if strings.Trim(s, "-0.") == "" {
+		return strings.TrimPrefix(s, "-")
+	}
+	return s
+}
+
+// FormatTriple formats red, green and blue values separated by spaces.
+func FormatTriple(values [3]float64) string {
+	return FormatValue(values[0]) + " " + FormatValue(values[1]) + " " + FormatValue(values[2])
+}
+
+// writer builds an indented XML document.
+type writer struct {
+	strings.Builder
+	namespace string
+}
+
+// open writes a start tag with attributes given as name, value pairs.
+func (w *writer) open(depth int, name string, attrs ...string) {
+	w.WriteString(strings.Repeat("\t", depth) + "<" + name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		w.WriteString(" " + attrs[i] + `="`)
+		xml.EscapeText(w, []byte(attrs[i+1]))
+		w.WriteString(`"`)
+	}
+	w.WriteString(">\n")
+}
+
+// close writes an end tag.
+func (w *writer) close(depth int, name string) {
+	w.WriteString(strings.Repeat("\t", depth) + "</" + name + ">\n")
+}
+
+// element writes an element holding text.
+func (w *writer) element(depth int, name, text string) {
+	w.WriteString(strings.Repeat("\t", depth) + "<" + name + ">")
+	xml.EscapeText(w, []byte(text))
+	w.WriteString("</" + name + ">\n")
+}
+
+// descriptions writes the Description, InputDescription and ViewingDescription elements that have values.
+func (w *writer) descriptions(depth int, descriptions []string, input, viewing string) {
+	for _, description := range descriptions {
+		w.element(depth, elementDescription, description)
+	}
+	if input != "" {
+		w.element(depth, elementInputDescription, input)
+	}
+	if viewing != "" {
+		w.element(depth, elementViewingDescription, viewing)
+	}
+}
+
+// colorCorrection writes a ColorCorrection element. A root element carries
+// the namespace. The SATNode is written as SatNode in the v1.01 namespace.
+func (w *writer) colorCorrection(depth int, cc types.ColorCorrection, id string, root bool) {
+	var attrs []string
+	if root {
+		attrs = append(attrs, "xmlns", w.namespace)
+	}
+	if id != "" {
+		attrs = append(attrs, "id", id)
+	}
+	w.open(depth, elementColorCorrection, attrs...)
+	w.descriptions(depth+1, cc.Descriptions, cc.InputDescription, cc.ViewingDescription)
+	w.open(depth+1, elementSOPNode)
+	w.element(depth+2, elementSlope, FormatTriple(cc.Slope))
+	w.element(depth+2, elementOffset, FormatTriple(cc.Offset))
+	w.element(depth+2, elementPower, FormatTriple(cc.Power))
+	w.close(depth+1, elementSOPNode)
+	satNode := elementSATNode
+	if w.namespace == types.NamespaceV101 {
+		satNode = elementSatNode
+	}
+	w.open(depth+1, satNode)
+	w.element(depth+2, elementSaturation, FormatValue(cc.Saturation))
+	w.close(depth+1, satNode)
+	w.close(depth, elementColorCorrection)
+}
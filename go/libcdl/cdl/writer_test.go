@@ -0,0 +1,174 @@
+package cdl
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libcdl/errors"
+	"lib-post-interchange/libcdl/types"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	for _, path := range []string{
+		"../../../samples/CDL/A001R1AA.ccc",
+		"../../../samples/CDL/A001R1AA.cdl",
+		"../../../samples/CDL/A001C002_240426_R1AA.cc",
+	} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read sample file: %v", err)
+			}
+			obj, err := Read(string(data))
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			got, err := Write(obj)
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got != string(data) {
+				t.Errorf("Write() =\n%s\nwant\n%s", got, data)
+			}
+		})
+	}
+}
+
+func TestWriteWithOptions(t *testing.T) {
+	cc := types.NewColorCorrection("A001C001")
+	cc.ExportID = "export"
+	cc.CCCID = "ccc"
+	cc.Offset = [3]float64{-0.00000001, 0.1234567, 0}
+	cc.Descriptions = []string{"a < b & c"}
+
+	tests := []struct {
+		name    string
+		obj     *types.Object
+		opts    WriteOptions
+		want    []string
+		wantErr *errors.Error
+	}{
+		{
+			name: "export ID and rounding",
+			obj:  &types.Object{Format: types.FormatCCC, Corrections: []types.ColorCorrection{cc}},
+			opts: WriteOptions{IDs: IDExport},
+			want: []string{
+				`<ColorCorrectionCollection xmlns="urn:ASC:CDL:v1.2">`,
+				`	<ColorCorrection id="export">`,
+				`		<Description>a &lt; b &amp; c</Description>`,
+				`			<Offset>0.000000 0.123457 0.000000</Offset>`,
+				`			<Saturation>1.000000</Saturation>`,
+			},
+		},
+		{
+			name: "CCC ID and namespace",
+			obj:  &types.Object{Format: types.FormatCDL, Corrections: []types.ColorCorrection{cc}},
+			opts: WriteOptions{IDs: IDCCC, Namespace: types.NamespaceV12},
+			want: []string{
+				`<ColorDecisionList xmlns="urn:ASC:CDL:v1.2">`,
+				`		<ColorCorrection id="ccc">`,
+				`			<SATNode>`,
+			},
+		},
+		{
+			name:    "unknown format",
+			obj:     &types.Object{Corrections: []types.ColorCorrection{cc}},
+			wantErr: errors.ErrOutputUnknownFormat,
+		},
+		{
+			name:    "two corrections in a .cc",
+			obj:     &types.Object{Format: types.FormatCC, Corrections: []types.ColorCorrection{cc, types.NewColorCorrection("B")}},
+			wantErr: errors.ErrOutputCorrectionCount,
+		},
+		{
+			name:    "duplicate ID",
+			obj:     &types.Object{Format: types.FormatCCC, Corrections: []types.ColorCorrection{cc, cc}},
+			wantErr: errors.ErrOutputDuplicateID,
+		},
+		{
+			name:    "nil object",
+			wantErr: errors.ErrOutputNilObject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WriteWithOptions(tt.obj, tt.opts)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("WriteWithOptions() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WriteWithOptions() error = %v", err)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(got, line+"\n") {
+					t.Errorf("Output does not contain %q:\n%s", line, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	obj, err := ReadFile("../../../samples/CDL/A001R1AA.ccc")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	extra := types.NewColorCorrection("B001C001")
+	obj.Corrections = append(obj.Corrections, extra)
+	for i, reel := range []string{"A001R1AA", "A001R1AA", "B001/R1AA"} {
+		obj.Corrections[i].Reel = reel
+	}
+
+	dir := t.TempDir()
+	paths, err := WriteFilesPerClip(dir, obj, DefaultWriteOptions())
+	if err != nil {
+		t.Fatalf("WriteFilesPerClip() error = %v", err)
+	}
+	want := []string{"A001C001_240426_R1AA.cc", "A001C002_240426_R1AA.cc", "B001C001.cc"}
+	if got := baseNames(paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteFilesPerClip() = %q, want %q", got, want)
+	}
+	clip, err := ReadFile(paths[1])
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+	if clip.Format != types.FormatCC || clip.Corrections[0].Saturation != 0.9 {
+		t.Errorf("Written .cc = %+v", clip)
+	}
+
+	paths, err = WriteFilesPerReel(dir, obj, DefaultWriteOptions())
+	if err != nil {
+		t.Fatalf("WriteFilesPerReel() error = %v", err)
+	}
+	want = []string{"A001R1AA.ccc", "B001_R1AA.ccc"}
+	if got := baseNames(paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteFilesPerReel() = %q, want %q", got, want)
+	}
+	reel, err := ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+	if len(reel.Corrections) != 2 || reel.InputDescription != "ARRI LogC3" {
+		t.Errorf("Written .ccc = %+v", reel)
+	}
+
+	obj.Corrections[2].Reel = ""
+	if _, err := WriteFilesPerReel(dir, obj, DefaultWriteOptions()); err == nil || !strings.HasPrefix(err.Error(), errors.ErrOutputMissingValue.Error()) {
+		t.Errorf("WriteFilesPerReel() error = %v, want %v", err, errors.ErrOutputMissingValue)
+	}
+}
+
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names
+}
@@ -0,0 +1,109 @@
+package errors
+
+import "fmt"
+
+// ErrorCategory represents the main category of an error
+type ErrorCategory int32
+
+// Error categories
+const (
+	CategoryInput ErrorCategory = iota + 1
+	CategoryOutput
+)
+
+// Error represents a CDL error.
+type Error struct {
+	Category    ErrorCategory
+	SubCategory int32
+	Message     string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("cdl: [%d.%d] %s", e.Category, e.SubCategory, e.Message)
+}
+
+// Code returns the unique error code
+func (e *Error) Code() int32 {
+	return int32(e.Category)*1000 + e.SubCategory
+}
+
+// WithContext returns a new Error with additional context appended to the message
+func (e *Error) WithContext(context string) *Error {
+	return &Error{
+		Category:    e.Category,
+		SubCategory: e.SubCategory,
+		Message:     e.Message + ": " + context,
+	}
+}
+
+// Error definitions for CDL parsing
+var (
+	// Input errors
+	ErrInputEmpty = &Error{
+		Category: CategoryInput,
+		Message:  "empty input",
+	}
+	ErrInputMalformedXML = &Error{
+		Category: CategoryInput,
+		Message:  "malformed XML",
+	}
+	ErrInputUnsupportedRoot = &Error{
+		Category: CategoryInput,
+		Message:  "unsupported root element",
+	}
+	ErrInputMalformedNode = &Error{
+		Category: CategoryInput,
+		Message:  "malformed node",
+	}
+	ErrInputMalformedValue = &Error{
+		Category: CategoryInput,
+		Message:  "malformed ASC CDL value",
+	}
+	ErrInputValueOutOfRange = &Error{
+		Category: CategoryInput,
+		Message:  "value out of range",
+	}
+
+	// Output errors
+	ErrOutputNilObject = &Error{
+		Category: CategoryOutput,
+		Message:  "nil CDL object",
+	}
+	ErrOutputUnknownFormat = &Error{
+		Category: CategoryOutput,
+		Message:  "unknown CDL format",
+	}
+	ErrOutputCorrectionCount = &Error{
+		Category: CategoryOutput,
+		Message:  "a .cc document holds exactly one color correction",
+	}
+	ErrOutputMissingValue = &Error{
+		Category: CategoryOutput,
+		Message:  "missing value",
+	}
+	ErrOutputDuplicateID = &Error{
+		Category: CategoryOutput,
+		Message:  "duplicate color correction ID",
+	}
+	ErrOutputIllegalValue = &Error{
+		Category: CategoryOutput,
+		Message:  "illegal value in color correction",
+	}
+)
+
+// IsCategory checks if an error belongs to a specific category
+func IsCategory(err error, category ErrorCategory) bool {
+	if cdlErr, ok := err.(*Error); ok {
+		return cdlErr.Code()/1000 == int32(category)
+	}
+	return false
+}
+
+// IsError checks if an error matches a specific category and subcategory
+func IsError(err error, category ErrorCategory, subCategory int32) bool {
+	if cdlErr, ok := err.(*Error); ok {
+		code := cdlErr.Code()
+		return code/1000 == int32(category) && code%1000 == subCategory
+	}
+	return false
+}
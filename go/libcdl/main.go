@@ -0,0 +1,25 @@
+package libcdl
+
+import (
+	"lib-post-interchange/libcdl/cdl"
+	"lib-post-interchange/libcdl/types"
+)
+
+// Handler provides the main interface for interacting with ASC CDL files.
+// It mirrors the ALE handler in libale.
+type Handler struct{}
+
+// New creates a new Handler instance that provides access to all CDL operations.
+func New() *Handler {
+	return &Handler{}
+}
+
+// ReadFile loads and parses a .cc, .ccc or .cdl file from the filesystem.
+func (h *Handler) ReadFile(filepath string) (*types.Object, error) {
+	return cdl.ReadFile(filepath)
+}
+
+// Read parses ASC CDL XML from any string source.
+func (h *Handler) Read(input string) (*types.Object, error) {
+	return cdl.Read(input)
+}
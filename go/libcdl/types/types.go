@@ -0,0 +1,97 @@
+package types
+
+import (
+	"lib-post-interchange/timecode"
+)
+
+// Format is the kind of ASC CDL document, named by its file extension.
+type Format string
+
+// Document formats
+const (
+	// FormatCC is a single ColorCorrection element.
+	FormatCC Format = "cc"
+	// FormatCCC is a ColorCorrectionCollection.
+	FormatCCC Format = "ccc"
+	// FormatCDL is a ColorDecisionList of ColorDecisions.
+	FormatCDL Format = "cdl"
+)
+
+// Namespaces of the ASC CDL schema versions
+const (
+	NamespaceV101 = "urn:ASC:CDL:v1.01"
+	NamespaceV12  = "urn:ASC:CDL:v1.2"
+)
+
+// ColorCorrection is one ASC CDL grade.
+type ColorCorrection struct {
+	// ID is the id attribute, or for a file without one, the file name without extension.
+	ID string
+	// ExportID and CCCID are alternative IDs a writer may be asked to use instead of ID.
+	ExportID string
+	CCCID    string
+	// Reel is the camera reel of the clip graded, for writing one collection per reel.
+	// It is not part of the XML.
+	Reel               string
+	Descriptions       []string
+	InputDescription   string
+	ViewingDescription string
+	// Slope, Offset and Power are each for red, green and blue.
+	Slope      [3]float64
+	Offset     [3]float64
+	Power      [3]float64
+	Saturation float64
+	// Metadata describes the clip graded, for corrections imported from CSV
+	// and EDL sources. It is not part of the XML.
+	Metadata *Metadata
+	// Line is the line number of the ColorCorrection element, or of the CSV
+	// record or EDL event, from 1.
+	Line int
+}
+
+// Metadata describes the clip a grade was imported with. Zero values are unknown.
+type Metadata struct {
+	Start    timecode.Timecode
+	End      timecode.Timecode
+	Duration timecode.Timecode
+	Rate     timecode.Rate
+	Scene    string
+	Take     string
+}
+
+// NewColorCorrection returns an identity grade with the given ID.
+func NewColorCorrection(id string) ColorCorrection {
+	return ColorCorrection{
+		ID:         id,
+		Slope:      [3]float64{1, 1, 1},
+		Power:      [3]float64{1, 1, 1},
+		Saturation: 1,
+	}
+}
+
+// IsIdentity reports whether the grade leaves colors unchanged.
+func (cc ColorCorrection) IsIdentity() bool {
+	identity := NewColorCorrection(cc.ID)
+	return cc.Slope == identity.Slope && cc.Offset == identity.Offset && cc.Power == identity.Power && cc.Saturation == identity.Saturation
+}
+
+// Object represents a parsed ASC CDL document.
+type Object struct {
+	Format Format
+	// Namespace is the xmlns of the root element.
+	Namespace          string
+	Descriptions       []string
+	InputDescription   string
+	ViewingDescription string
+	Corrections        []ColorCorrection
+}
+
+// Find returns the first color correction with an ID.
+func (o *Object) Find(id string) (ColorCorrection, bool) {
+	for _, cc := range o.Corrections {
+		if cc.ID == id {
+			return cc, true
+		}
+	}
+	return ColorCorrection{}, false
+}
@@ -0,0 +1,374 @@
+package edl
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libedl/errors"
+	"lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+// Header and comment keywords
+const (
+	titlePrefix     = "TITLE:"
+	fcmPrefix       = "FCM:"
+	speedKeyword    = "M2"
+	commentPrefix   = "*"
+	commentKeyword  = "COMMENT:"
+	fromClipName    = "FROM CLIP NAME:"
+	toClipName      = "TO CLIP NAME:"
+	sourceFile      = "SOURCE FILE:"
+	locator         = "LOC:"
+	ascSOP          = "ASC_SOP"
+	ascSAT          = "ASC_SAT"
+	ascCCXML        = "ASC_CC_XML"
+	minNumberDigits = 3
+)
+
+// sopPattern matches the three parenthesised triples of an ASC_SOP comment.
+var sopPattern = regexp.MustCompile(`^\(([^()]*)\)\s*\(([^()]*)\)\s*\(([^()]*)\)$`)
+
+// ReadFile reads and parses an EDL file from the filesystem.
+func ReadFile(filepath string) (*types.Object, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return Read(string(data))
+}
+
+// Read parses CMX3600 EDL data from a string. Lines ending in LF, CRLF or CR
+// alone are accepted.
+func Read(input string) (*types.Object, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.ErrInputEmpty
+	}
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+	input = strings.ReplaceAll(input, "\r", "\n")
+
+	obj := &types.Object{FCM: types.NonDropFrame}
+	fcm := obj.FCM
+	seenFCM := false
+	var current *types.Event
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		switch {
+		case line == "":
+			continue
+
+		case strings.HasPrefix(line, titlePrefix):
+			obj.Title = strings.TrimSpace(strings.TrimPrefix(line, titlePrefix))
+
+		case strings.HasPrefix(line, fcmPrefix):
+			mode, err := readFCM(line)
+			if err != nil {
+				return nil, err.WithContext(fmt.Sprintf("line %d: %q", lineNumber, line))
+			}
+			fcm = mode
+			if !seenFCM {
+				obj.FCM = mode
+				seenFCM = true
+			}
+
+		case fields[0] == speedKeyword || (len(fields) > 1 && isEventNumber(fields[0]) && fields[1] == speedKeyword):
+			speed, number, err := readSpeed(fields)
+			if err != nil {
+				return nil, err.WithContext(fmt.Sprintf("line %d: %q", lineNumber, line))
+			}
+			target := current
+			if number > 0 {
+				target = lastEvent(obj, number)
+			}
+			if target == nil {
+				return nil, errors.ErrInputMalformedSpeed.WithContext(fmt.Sprintf("line %d: no event for speed change", lineNumber))
+			}
+			target.Speed = speed
+
+		case isEventNumber(fields[0]):
+			event, err := readEvent(fields)
+			if err != nil {
+				return nil, err.WithContext(fmt.Sprintf("line %d: %q", lineNumber, line))
+			}
+			event.FCM = fcm
+			event.Line = lineNumber
+			obj.Events = append(obj.Events, event)
+			current = &obj.Events[len(obj.Events)-1]
+
+		case strings.HasPrefix(line, commentPrefix) || strings.HasPrefix(line, commentKeyword):
+			comment := strings.TrimPrefix(line, commentKeyword)
+			comment = strings.TrimSpace(strings.TrimPrefix(comment, commentPrefix))
+			if current == nil {
+				obj.Comments = append(obj.Comments, comment)
+				continue
+			}
+			if err := readComment(current, comment); err != nil {
+				return nil, err.WithContext(fmt.Sprintf("line %d: %q", lineNumber, line))
+			}
+
+		default:
+			// Keep lines such as "AUD 3 4" or "SPLIT:" with their event
+			if current == nil {
+				obj.Extra = append(obj.Extra, line)
+			} else {
+				current.Extra = append(current.Extra, line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// isEventNumber reports whether a field is an event number, three or more digits.
+func isEventNumber(field string) bool {
+	return len(field) >= minNumberDigits && isDigits(field)
+}
+
+// lastEvent returns the last event with a number, or nil.
+func lastEvent(obj *types.Object, number int) *types.Event {
+	for i := len(obj.Events) - 1; i >= 0; i-- {
+		if obj.Events[i].Number == number {
+			return &obj.Events[i]
+		}
+	}
+	return nil
+}
+
+// readFCM parses an FCM line.
+func readFCM(line string) (types.FrameCodeMode, *errors.Error) {
+	value := strings.Join(strings.Fields(strings.ToUpper(strings.TrimPrefix(line, fcmPrefix))), " ")
+	switch value {
+	case string(types.NonDropFrame), "NON DROP FRAME":
+		return types.NonDropFrame, nil
+	case string(types.DropFrame):
+		return types.DropFrame, nil
+	}
+	return "", errors.ErrInputMalformedFCM
+}
+
+// readEvent parses the fields of an event line: number, reel, track,
+// transition with an optional duration, then source in and out and record in
+// and out.
+func readEvent(fields []string) (types.Event, *errors.Error) {
+	if len(fields) < 8 {
+		return types.Event{}, errors.ErrInputMalformedEvent.WithContext(fmt.Sprintf("expected at least 8 fields, got %d", len(fields)))
+	}
+	number, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return types.Event{}, errors.ErrInputMalformedEvent.WithContext("event number")
+	}
+	event := types.Event{
+		Number: number,
+		Reel:   fields[1],
+		Track:  fields[2],
+	}
+
+	timecodes := fields[len(fields)-4:]
+	targets := []*timecode.Timecode{&event.SourceIn, &event.SourceOut, &event.RecordIn, &event.RecordOut}
+	for i, value := range timecodes {
+		tc, err := timecode.Parse(value)
+		if err != nil {
+			return types.Event{}, errors.ErrInputMalformedTimecode.WithContext(value)
+		}
+		*targets[i] = tc
+	}
+
+	transition, terr := readTransition(fields[3 : len(fields)-4])
+	if terr != nil {
+		return types.Event{}, terr
+	}
+	event.Transition = transition
+	return event, nil
+}
+
+// readTransition parses the transition fields of an event line, such as
+// ["C"], ["D", "025"], ["W001", "030"] or ["K", "B", "010"].
+func readTransition(fields []string) (types.Transition, *errors.Error) {
+	if len(fields) == 0 {
+		return types.Transition{}, errors.ErrInputMalformedEvent.WithContext("missing transition")
+	}
+	var transition types.Transition
+	hasDuration := false
+	if last := fields[len(fields)-1]; len(fields) > 1 && isDigits(last) {
+		transition.Duration, _ = strconv.Atoi(last)
+		fields = fields[:len(fields)-1]
+		hasDuration = true
+	}
+	transition.Code = strings.Join(fields, "")
+	switch code := strings.ToUpper(transition.Code); {
+	case code == "C":
+		transition.Type = types.Cut
+	case code == "D":
+		transition.Type = types.Dissolve
+	case strings.HasPrefix(code, "W"):
+		transition.Type = types.Wipe
+	case strings.HasPrefix(code, "K"):
+		transition.Type = types.Key
+	default:
+		return types.Transition{}, errors.ErrInputMalformedEvent.WithContext("unknown transition " + transition.Code)
+	}
+	if (transition.Type == types.Dissolve || transition.Type == types.Wipe) && !hasDuration {
+		return types.Transition{}, errors.ErrInputMalformedEvent.WithContext("missing transition duration")
+	}
+	return transition, nil
+}
+
+// readSpeed parses an M2 line: optional event number, "M2", reel, speed in
+// frames per second and entry timecode.
+func readSpeed(fields []string) (*types.Speed, int, *errors.Error) {
+	number := 0
+	if fields[0] != speedKeyword {
+		number, _ = strconv.Atoi(fields[0])
+		fields = fields[1:]
+	}
+	if len(fields) != 4 {
+		return nil, 0, errors.ErrInputMalformedSpeed.WithContext(fmt.Sprintf("expected 4 fields, got %d", len(fields)))
+	}
+	fps, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return nil, 0, errors.ErrInputMalformedSpeed.WithContext("speed " + fields[2])
+	}
+	entry, err := timecode.Parse(fields[3])
+	if err != nil {
+		return nil, 0, errors.ErrInputMalformedTimecode.WithContext(fields[3])
+	}
+	return &types.Speed{Reel: fields[1], FPS: fps, Entry: entry}, number, nil
+}
+
+// readComment assigns a comment, without its leading "*", to an event.
+func readComment(event *types.Event, comment string) *errors.Error {
+	upper := strings.ToUpper(comment)
+	value := func(keyword string) string {
+		return strings.TrimSpace(comment[len(keyword):])
+	}
+	switch {
+	case strings.HasPrefix(upper, fromClipName):
+		event.ClipName = value(fromClipName)
+	case strings.HasPrefix(upper, toClipName):
+		event.ToClipName = value(toClipName)
+	case strings.HasPrefix(upper, sourceFile):
+		event.SourceFile = value(sourceFile)
+	case strings.HasPrefix(upper, locator):
+		loc, err := readLocator(value(locator))
+		if err != nil {
+			return err
+		}
+		event.Locators = append(event.Locators, loc)
+	case strings.HasPrefix(upper, ascSOP):
+		sop, err := parseSOP(value(ascSOP))
+		if err != nil {
+			return errors.ErrInputMalformedComment.WithContext(err.Message)
+		}
+		event.ASCSOP = &sop
+	case strings.HasPrefix(upper, ascSAT):
+		sat, err := strconv.ParseFloat(value(ascSAT), 64)
+		if err != nil {
+			return errors.ErrInputMalformedComment.WithContext("ASC_SAT " + value(ascSAT))
+		}
+		event.ASCSAT = &sat
+	case strings.HasPrefix(upper, ascCCXML):
+		event.ASCCCXML = value(ascCCXML)
+	default:
+		event.Comments = append(event.Comments, comment)
+	}
+	return nil
+}
+
+// readLocator parses the value of a LOC comment: timecode, optional color and comment.
+func readLocator(value string) (types.Locator, *errors.Error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return types.Locator{}, errors.ErrInputMalformedComment.WithContext("empty LOC")
+	}
+	tc, err := timecode.Parse(fields[0])
+	if err != nil {
+		return types.Locator{}, errors.ErrInputMalformedTimecode.WithContext(fields[0])
+	}
+	loc := types.Locator{Timecode: tc}
+	rest := strings.TrimSpace(strings.TrimPrefix(value, fields[0]))
+	if len(fields) > 1 {
+		loc.Color = fields[1]
+		loc.Comment = strings.TrimSpace(strings.TrimPrefix(rest, fields[1]))
+	}
+	return loc, nil
+}
+
+// ParseSOP parses ASC CDL slope, offset and power written as
+// "(s s s)(o o o)(p p p)", as in EDL comments and ALE columns. Every value
+// must be finite, slopes may not be negative and powers must be positive.
+func ParseSOP(s string) (types.SOP, error) {
+	sop, err := parseSOP(s)
+	if err != nil {
+		return types.SOP{}, err
+	}
+	return sop, nil
+}
+
+// parseSOP parses an ASC_SOP value for ParseSOP.
+func parseSOP(s string) (types.SOP, *errors.Error) {
+	sop, kind, problem := ParseSOPProblem(s)
+	if kind != nil {
+		return types.SOP{}, kind.WithContext(problem)
+	}
+	return sop, nil
+}
+
+// ParseSOPProblem parses an ASC_SOP value as ParseSOP does. A rejected value
+// is described by the error it falls under, ErrInputMalformedASC or
+// ErrInputASCOutOfRange itself, and the problem found, for callers that
+// report it as one of their own errors.
+func ParseSOPProblem(s string) (types.SOP, *errors.Error, string) {
+	match := sopPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return types.SOP{}, errors.ErrInputMalformedASC, fmt.Sprintf("ASC_SOP %q: expected (slope)(offset)(power)", s)
+	}
+	var sop types.SOP
+	names := []string{"Slope", "Offset", "Power"}
+	for i, target := range []*[3]float64{&sop.Slope, &sop.Offset, &sop.Power} {
+		values := strings.Fields(match[i+1])
+		if len(values) != 3 {
+			return types.SOP{}, errors.ErrInputMalformedASC, fmt.Sprintf("ASC_SOP %q: %s is not three numbers", s, names[i])
+		}
+		for j, v := range values {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return types.SOP{}, errors.ErrInputMalformedASC, fmt.Sprintf("ASC_SOP %q: %q is not a number", s, v)
+			}
+			var problem string
+			switch {
+			case math.IsNaN(f) || math.IsInf(f, 0):
+				problem = "is not finite"
+			case i == 0 && f < 0:
+				problem = "is negative"
+			case i == 2 && f <= 0:
+				problem = "is not positive"
+			}
+			if problem != "" {
+				return types.SOP{}, errors.ErrInputASCOutOfRange, fmt.Sprintf("ASC_SOP %q: %s %v %s", s, names[i], f, problem)
+			}
+			target[j] = f
+		}
+	}
+	return sop, nil, ""
+}
+
+// isDigits reports whether a string is all decimal digits.
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,204 @@
+package edl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libedl/errors"
+	"lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+func TestReadFile(t *testing.T) {
+	obj, err := ReadFile("../../../samples/EDL/A001R1AA_CUT.edl")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if obj.Title != "A001R1AA_CUT" {
+		t.Errorf("Title = %q, want A001R1AA_CUT", obj.Title)
+	}
+	if obj.FCM != types.NonDropFrame {
+		t.Errorf("FCM = %q, want %q", obj.FCM, types.NonDropFrame)
+	}
+	if len(obj.Events) != 5 {
+		t.Fatalf("Got %d events, want 5", len(obj.Events))
+	}
+
+	first := obj.Events[0]
+	if first.Number != 1 || first.Reel != "A001R1AA" || first.Track != "V" || first.Transition.Type != types.Cut {
+		t.Errorf("First event = %+v", first)
+	}
+	wantTimecodes := []string{"03:44:40:00", "03:44:45:00", "01:00:00:00", "01:00:05:00"}
+	gotTimecodes := []string{first.SourceIn.String(), first.SourceOut.String(), first.RecordIn.String(), first.RecordOut.String()}
+	if !reflect.DeepEqual(gotTimecodes, wantTimecodes) {
+		t.Errorf("Timecodes = %v, want %v", gotTimecodes, wantTimecodes)
+	}
+	if first.ClipName != "A001C001_240426_R1AA" || first.SourceFile != "A001C001_240426_R1AA.mxf" {
+		t.Errorf("ClipName = %q, SourceFile = %q", first.ClipName, first.SourceFile)
+	}
+	if first.ASCSOP == nil || first.ASCSOP.Slope != [3]float64{1, 1, 1} || first.ASCSAT == nil || *first.ASCSAT != 1 {
+		t.Errorf("ASC CDL = %+v, %v", first.ASCSOP, first.ASCSAT)
+	}
+	if first.Line != 4 {
+		t.Errorf("Line = %d, want 4", first.Line)
+	}
+
+	dissolve := obj.Events[2]
+	if dissolve.Number != 2 || dissolve.Transition != (types.Transition{Type: types.Dissolve, Code: "D", Duration: 25}) {
+		t.Errorf("Dissolve = %+v", dissolve.Transition)
+	}
+	if obj.Events[1].ClipName != "" || dissolve.ToClipName != "A001C002_240426_R1AA" {
+		t.Errorf("Comments should attach to the incoming event: %+v", dissolve)
+	}
+	wantLocator := types.Locator{Timecode: timecode.MustParse("01:00:07:12"), Color: "RED", Comment: "check focus"}
+	if len(dissolve.Locators) != 1 || dissolve.Locators[0] != wantLocator {
+		t.Errorf("Locators = %+v, want %+v", dissolve.Locators, wantLocator)
+	}
+	wantSOP := types.SOP{Slope: [3]float64{1.1, 1, 0.95}, Offset: [3]float64{0.01, 0, -0.01}, Power: [3]float64{1, 1, 1}}
+	if dissolve.ASCSOP == nil || *dissolve.ASCSOP != wantSOP {
+		t.Errorf("ASCSOP = %+v, want %+v", dissolve.ASCSOP, wantSOP)
+	}
+
+	speed := obj.Events[3]
+	wantSpeed := types.Speed{Reel: "A001R1AA", FPS: 50, Entry: timecode.MustParse("03:45:58:00")}
+	if speed.Speed == nil || *speed.Speed != wantSpeed {
+		t.Errorf("Speed = %+v, want %+v", speed.Speed, wantSpeed)
+	}
+	if speed.ASCCCXML != "A001C002_240426_R1AA" {
+		t.Errorf("ASCCCXML = %q", speed.ASCCCXML)
+	}
+
+	audio := obj.Events[4]
+	if audio.Reel != "AX" || audio.Track != "AA" || audio.HasVideo() {
+		t.Errorf("Audio event = %+v", audio)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr *errors.Error
+		check   func(*testing.T, *types.Object)
+	}{
+		{
+			name:    "empty input",
+			input:   "\n\n",
+			wantErr: errors.ErrInputEmpty,
+		},
+		{
+			name:  "drop frame with CR line endings",
+			input: "TITLE: DF\rFCM: DROP FRAME\r\r001  TAPE1    V     C        01:00:00;00 01:00:01;00 01:00:00;00 01:00:01;00\r",
+			check: func(t *testing.T, obj *types.Object) {
+				if !obj.DropFrame() || obj.Events[0].FCM != types.DropFrame {
+					t.Error("Expected drop frame")
+				}
+				if !obj.Events[0].SourceIn.DropFrame {
+					t.Error("Expected drop frame timecode")
+				}
+			},
+		},
+		{
+			name: "wipe, key and comment prefix",
+			input: "001  BL       V     C        00:00:00:00 00:00:00:00 01:00:00:00 01:00:00:00\n" +
+				"001  TAPE1    V     W001 030 01:00:00:00 01:00:02:00 01:00:00:00 01:00:02:00\n" +
+				"002  TAPE2    V     K B      01:00:00:00 01:00:02:00 01:00:02:00 01:00:04:00\n" +
+				"COMMENT:*ASC_SAT 0.5\n" +
+				"AUD  3\n",
+			check: func(t *testing.T, obj *types.Object) {
+				if got := obj.Events[1].Transition; got != (types.Transition{Type: types.Wipe, Code: "W001", Duration: 30}) {
+					t.Errorf("Wipe = %+v", got)
+				}
+				key := obj.Events[2]
+				if key.Transition != (types.Transition{Type: types.Key, Code: "KB"}) {
+					t.Errorf("Key = %+v", key.Transition)
+				}
+				if key.ASCSAT == nil || *key.ASCSAT != 0.5 {
+					t.Errorf("ASCSAT = %v", key.ASCSAT)
+				}
+				if !reflect.DeepEqual(key.Extra, []string{"AUD  3"}) || len(key.Comments) != 0 {
+					t.Errorf("Extra = %q, Comments = %q", key.Extra, key.Comments)
+				}
+			},
+		},
+		{
+			name:  "speed with event number and reverse motion",
+			input: "001  TAPE1    V     C        01:00:00:00 01:00:01:00 01:00:00:00 01:00:02:00\n001  M2   TAPE1       -025.0                01:00:01:00\n",
+			check: func(t *testing.T, obj *types.Object) {
+				if obj.Events[0].Speed == nil || obj.Events[0].Speed.FPS != -25 {
+					t.Errorf("Speed = %+v", obj.Events[0].Speed)
+				}
+			},
+		},
+		{
+			name:    "malformed timecode",
+			input:   "001  TAPE1    V     C        01:00:00:00 01:00:01:00 01:00:00:00 1:00:01:00\n",
+			wantErr: errors.ErrInputMalformedTimecode,
+		},
+		{
+			name:    "dissolve without duration",
+			input:   "001  TAPE1    V     D        01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\n",
+			wantErr: errors.ErrInputMalformedEvent,
+		},
+		{
+			name:    "short event line",
+			input:   "001  TAPE1    V     C        01:00:00:00 01:00:01:00\n",
+			wantErr: errors.ErrInputMalformedEvent,
+		},
+		{
+			name:    "speed before any event",
+			input:   "M2   TAPE1       050.0                01:00:00:00\n",
+			wantErr: errors.ErrInputMalformedSpeed,
+		},
+		{
+			name:    "malformed ASC_SOP",
+			input:   "001  TAPE1    V     C        01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\n*ASC_SOP (1 1)(0 0 0)(1 1 1)\n",
+			wantErr: errors.ErrInputMalformedComment,
+		},
+		{
+			name:    "ASC_SOP power out of range",
+			input:   "001  TAPE1    V     C        01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\n*ASC_SOP (1 1 1)(0 0 0)(1 0 1)\n",
+			wantErr: errors.ErrInputMalformedComment,
+		},
+		{
+			name:    "unknown FCM",
+			input:   "FCM: SOMETIMES DROP\n",
+			wantErr: errors.ErrInputMalformedFCM,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := Read(tt.input)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			tt.check(t, obj)
+		})
+	}
+}
+
+func TestParseSOPProblem(t *testing.T) {
+	tests := map[string]*errors.Error{
+		"(1 1 1)(0 0 0)(1 1 1)":    nil,
+		"(1 1 1)(0 0 0)":           errors.ErrInputMalformedASC,
+		"(1 1 1)(0 0 zero)(1 1 1)": errors.ErrInputMalformedASC,
+		"(-1 1 1)(0 0 0)(1 1 1)":   errors.ErrInputASCOutOfRange,
+	}
+	for input, want := range tests {
+		_, kind, problem := ParseSOPProblem(input)
+		if kind != want {
+			t.Errorf("ParseSOPProblem(%q) error = %v, want %v", input, kind, want)
+		}
+		if (problem != "") != (want != nil) || (want != nil && !strings.HasPrefix(problem, "ASC_SOP ")) {
+			t.Errorf("ParseSOPProblem(%q) problem = %q", input, problem)
+		}
+	}
+}
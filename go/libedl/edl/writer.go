@@ -0,0 +1,311 @@
+package edl
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libedl/errors"
+	"lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+// DefaultReelLength is the CMX3600 limit on reel name length.
+const DefaultReelLength = 8
+
+// ReelPolicy selects how reel names longer than the limit are shortened.
+type ReelPolicy int
+
+const (
+	// ReelTruncateEnd keeps the first characters of a long reel name.
+	ReelTruncateEnd ReelPolicy = iota
+	// ReelTruncateStart keeps the last characters of a long reel name, where
+	// camera roll names usually differ.
+	ReelTruncateStart
+	// ReelFail returns an error for a long reel name.
+	ReelFail
+)
+
+// WriteOptions controls how an EDL object is written.
+type WriteOptions struct {
+	// CRLF terminates lines with "\r\n" instead of "\n".
+	CRLF bool
+
+	// ClipNames writes "* FROM CLIP NAME:" and "* TO CLIP NAME:" comments.
+	ClipNames bool
+	// SourceFiles writes "* SOURCE FILE:" comments.
+	SourceFiles bool
+	// Locators writes "* LOC:" comments.
+	Locators bool
+	// ASCCDL writes *ASC_SOP, *ASC_SAT and *ASC_CC_XML comments.
+	ASCCDL bool
+	// Comments writes the remaining comments of each event.
+	Comments bool
+
+	// ReelMap replaces reel names before they are checked against ReelLength.
+	ReelMap map[string]string
+	// ReelLength is the longest reel name written. The zero value means DefaultReelLength.
+	ReelLength int
+	// Reels selects how longer reel names are shortened.
+	Reels ReelPolicy
+}
+
+// DefaultWriteOptions returns the options used by Write and WriteFile:
+// CRLF line endings, all comments and reel names truncated to 8 characters.
+func DefaultWriteOptions() WriteOptions {
+	return WriteOptions{
+		CRLF:        true,
+		ClipNames:   true,
+		SourceFiles: true,
+		Locators:    true,
+		ASCCDL:      true,
+		Comments:    true,
+	}
+}
+
+// WriteFile writes an EDL object to a file at the specified path.
+func WriteFile(filepath string, edl *types.Object) error {
+	return WriteFileWithOptions(filepath, edl, DefaultWriteOptions())
+}
+
+// WriteFileWithOptions writes an EDL object to a file at the specified path using the given options.
+func WriteFileWithOptions(filepath string, edl *types.Object, opts WriteOptions) error {
+	data, err := WriteWithOptions(edl, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath, []byte(data), 0644)
+}
+
+// Write converts an EDL object to CMX3600 text.
+func Write(edl *types.Object) (string, error) {
+	return WriteWithOptions(edl, DefaultWriteOptions())
+}
+
+// WriteWithOptions converts an EDL object to CMX3600 text using the given options.
+// Event lines use the fixed CMX3600 columns. An FCM line is written before
+// any event whose frame code mode differs from the one in effect, and
+// timecodes are written with the separators of their frame code mode.
+func WriteWithOptions(edl *types.Object, opts WriteOptions) (string, error) {
+	if edl == nil {
+		return "", errors.ErrOutputNilObject
+	}
+	reels, err := reelNames(edl, opts)
+	if err != nil {
+		return "", err
+	}
+
+	newline := "\n"
+	if opts.CRLF {
+		newline = "\r\n"
+	}
+	var builder strings.Builder
+	line := func(s string) {
+		builder.WriteString(strings.TrimRight(s, " ") + newline)
+	}
+
+	fcm := edl.FCM
+	if fcm == "" {
+		fcm = types.NonDropFrame
+	}
+	line("TITLE: " + singleLine(edl.Title))
+	line("FCM: " + string(fcm))
+	for _, extra := range edl.Extra {
+		line(singleLine(extra))
+	}
+	for _, comment := range edl.Comments {
+		if opts.Comments {
+			line("* " + singleLine(comment))
+		}
+	}
+
+	for i, event := range edl.Events {
+		if i == 0 || event.Number != edl.Events[i-1].Number {
+			line("")
+		}
+		if event.FCM != "" && event.FCM != fcm {
+			fcm = event.FCM
+			line("FCM: " + string(fcm))
+		}
+		eventLine, err := formatEvent(event, reels[event.Reel], fcm)
+		if err != nil {
+			return "", err.WithContext(fmt.Sprintf("event %d", event.Number))
+		}
+		line(eventLine)
+		if event.Speed != nil {
+			line(formatSpeed(*event.Speed, reels[event.Speed.Reel], fcm))
+		}
+		// Lines such as "AUD 3 4" are written back as they were read
+		for _, extra := range event.Extra {
+			line(singleLine(extra))
+		}
+		for _, comment := range eventComments(event, opts) {
+			line(comment)
+		}
+	}
+	return builder.String(), nil
+}
+
+// trackPattern matches the CMX3600 track types: V, A, A2, AA, B, NONE and
+// their combinations with video, and the A3, A4 and higher channels of
+// extended EDLs.
+var trackPattern = regexp.MustCompile(`^(V|A\d*|AA|B|NONE|(A\d*|AA|B)/V)$`)
+
+// maxEventNumber is the largest event number that fits the three-digit column.
+const maxEventNumber = 999
+
+// formatEvent formats an event line in the fixed CMX3600 columns.
+func formatEvent(event types.Event, reel string, fcm types.FrameCodeMode) (string, *errors.Error) {
+	if !trackPattern.MatchString(event.Track) {
+		return "", errors.ErrOutputIllegalValue.WithContext(fmt.Sprintf("track %q", event.Track))
+	}
+	if event.Number < 0 || event.Number > maxEventNumber {
+		return "", errors.ErrOutputIllegalValue.WithContext(fmt.Sprintf("event number %d is outside 000 to %d", event.Number, maxEventNumber))
+	}
+	code := event.Transition.Code
+	if code == "" {
+		code = map[types.TransitionType]string{
+			types.Cut:      "C",
+			types.Dissolve: "D",
+			types.Wipe:     "W001",
+			types.Key:      "K",
+		}[event.Transition.Type]
+	}
+	duration := ""
+	if event.Transition.Type != types.Cut {
+		duration = fmt.Sprintf("%03d", event.Transition.Duration)
+	}
+	return fmt.Sprintf("%03d  %-8s %-5s %-4s %3s %s %s %s %s",
+		event.Number, reel, event.Track, code, duration,
+		formatTimecode(event.SourceIn, fcm), formatTimecode(event.SourceOut, fcm),
+		formatTimecode(event.RecordIn, fcm), formatTimecode(event.RecordOut, fcm),
+	), nil
+}
+
+// formatSpeed formats an M2 line, with the speed and entry timecode in their fixed columns.
+func formatSpeed(speed types.Speed, reel string, fcm types.FrameCodeMode) string {
+	return fmt.Sprintf("M2   %-8s       %-20s %s", reel, fmt.Sprintf("%05.1f", speed.FPS), formatTimecode(speed.Entry, fcm))
+}
+
+// formatTimecode writes a timecode with the separators of a frame code mode.
+func formatTimecode(tc timecode.Timecode, fcm types.FrameCodeMode) string {
+	tc.DropFrame = fcm == types.DropFrame
+	return tc.String()
+}
+
+// eventComments returns the comment lines written after an event.
+func eventComments(event types.Event, opts WriteOptions) []string {
+	var lines []string
+	if opts.ClipNames && event.ClipName != "" {
+		lines = append(lines, "* FROM CLIP NAME: "+singleLine(event.ClipName))
+	}
+	if opts.ClipNames && event.ToClipName != "" {
+		lines = append(lines, "* TO CLIP NAME: "+singleLine(event.ToClipName))
+	}
+	if opts.SourceFiles && event.SourceFile != "" {
+		lines = append(lines, "* SOURCE FILE: "+singleLine(event.SourceFile))
+	}
+	if opts.Locators {
+		for _, loc := range event.Locators {
+			lines = append(lines, fmt.Sprintf("* LOC: %s %-7s %s", loc.Timecode, singleLine(loc.Color), singleLine(loc.Comment)))
+		}
+	}
+	if opts.ASCCDL {
+		if event.ASCSOP != nil {
+			lines = append(lines, "*ASC_SOP "+FormatSOP(*event.ASCSOP))
+		}
+		if event.ASCSAT != nil {
+			lines = append(lines, fmt.Sprintf("*ASC_SAT %.6f", *event.ASCSAT))
+		}
+		if event.ASCCCXML != "" {
+			lines = append(lines, "*ASC_CC_XML "+singleLine(event.ASCCCXML))
+		}
+	}
+	if opts.Comments {
+		for _, comment := range event.Comments {
+			lines = append(lines, "* "+singleLine(comment))
+		}
+	}
+	return lines
+}
+
+// FormatSOP formats ASC CDL slope, offset and power as
+// "(s s s)(o o o)(p p p)" with six decimal places. Values that round to zero
+// are written without a sign.
+func FormatSOP(sop types.SOP) string {
+	var builder strings.Builder
+	for _, values := range [][3]float64{sop.Slope, sop.Offset, sop.Power} {
+		builder.WriteString("(")
+		for i, v := range values {
+			if i > 0 {
+				builder.WriteString(" ")
+			}
+			s := strconv.FormatFloat(v, 'f', 6, 64)
+			if strings.Trim(s, "-0.") == "" {
+				s = strings.TrimPrefix(s, "-")
+			}
+			builder.WriteString(s)
+		}
+		builder.WriteString(")")
+	}
+	return builder.String()
+}
+
+// reelNames maps each reel name used in the list to the name written,
+// applying the reel map, replacing whitespace and shortening long names.
+// Two reels that would be written with the same name are an error.
+func reelNames(edl *types.Object, opts WriteOptions) (map[string]string, error) {
+	limit := opts.ReelLength
+	if limit <= 0 {
+		limit = DefaultReelLength
+	}
+	names := make(map[string]string)
+	sources := make(map[string]string)
+	add := func(reel string) error {
+		if _, ok := names[reel]; ok {
+			return nil
+		}
+		name := reel
+		if mapped, ok := opts.ReelMap[reel]; ok {
+			name = mapped
+		}
+		name = strings.Join(strings.Fields(name), "_")
+		if name == "" {
+			return errors.ErrOutputIllegalValue.WithContext("empty reel name")
+		}
+		if runes := []rune(name); len(runes) > limit {
+			switch opts.Reels {
+			case ReelTruncateEnd:
+				name = string(runes[:limit])
+			case ReelTruncateStart:
+				name = string(runes[len(runes)-limit:])
+			default:
+				return errors.ErrOutputReelTooLong.WithContext(fmt.Sprintf("%q is longer than %d characters", reel, limit))
+			}
+		}
+		if other, ok := sources[name]; ok {
+			return errors.ErrOutputReelCollision.WithContext(fmt.Sprintf("%q and %q are both written as %q", other, reel, name))
+		}
+		names[reel] = name
+		sources[name] = reel
+		return nil
+	}
+	for _, event := range edl.Events {
+		if err := add(event.Reel); err != nil {
+			return nil, err
+		}
+		if event.Speed != nil {
+			if err := add(event.Speed.Reel); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return names, nil
+}
+
+// singleLine replaces line breaks, which would end a comment early, with spaces.
+func singleLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,260 @@
+package edl
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libedl/errors"
+	"lib-post-interchange/libedl/types"
+	"lib-post-interchange/timecode"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	path := "../../../samples/EDL/A001R1AA_CUT.edl"
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	obj, err := Read(string(want))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	opts := DefaultWriteOptions()
+	opts.CRLF = false
+	got, err := WriteWithOptions(obj, opts)
+	if err != nil {
+		t.Fatalf("WriteWithOptions() error = %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Round trip differs:\n%s\nwant:\n%s", got, want)
+	}
+
+	crlf, err := Write(obj)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if crlf != strings.ReplaceAll(string(want), "\n", "\r\n") {
+		t.Error("Write() should use CRLF line endings")
+	}
+}
+
+func TestWriteRoundTripExtra(t *testing.T) {
+	input := "TITLE: AUDIO\nFCM: NON-DROP FRAME\nSPLIT: AUDIO DELAY= 00:00:00:05\n\n" +
+		"001  TAPE1    A     C        01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\n" +
+		"AUD  3    4\n* NOTE\n"
+	obj, err := Read(input)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	opts := DefaultWriteOptions()
+	opts.CRLF = false
+	got, err := WriteWithOptions(obj, opts)
+	if err != nil {
+		t.Fatalf("WriteWithOptions() error = %v", err)
+	}
+	if got != input {
+		t.Errorf("Round trip differs:\n%s\nwant:\n%s", got, input)
+	}
+}
+
+func TestWriteWithOptions(t *testing.T) {
+	tc := timecode.MustParse
+	sat := 0.5
+	event := func(number int, reel string) types.Event {
+		return types.Event{
+			Number:    number,
+			Reel:      reel,
+			Track:     "V",
+			SourceIn:  tc("10:00:00:00"),
+			SourceOut: tc("10:00:01:00"),
+			RecordIn:  tc("01:00:00:00"),
+			RecordOut: tc("01:00:01:00"),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		obj     *types.Object
+		opts    func(*WriteOptions)
+		want    []string
+		notWant []string
+		wantErr *errors.Error
+	}{
+		{
+			name: "drop frame",
+			obj:  &types.Object{Title: "DF", FCM: types.DropFrame, Events: []types.Event{event(1, "TAPE1")}},
+			want: []string{
+				"FCM: DROP FRAME",
+				"001  TAPE1    V     C        10:00:00;00 10:00:01;00 01:00:00;00 01:00:01;00",
+			},
+		},
+		{
+			name: "frame code mode change",
+			obj: func() *types.Object {
+				second := event(2, "TAPE2")
+				second.FCM = types.DropFrame
+				return &types.Object{Events: []types.Event{event(1, "TAPE1"), second}}
+			}(),
+			want: []string{
+				"FCM: NON-DROP FRAME",
+				"001  TAPE1    V     C        10:00:00:00",
+				"FCM: DROP FRAME\n002  TAPE2    V     C        10:00:00;00",
+			},
+		},
+		{
+			name: "dissolve, wipe and audio",
+			obj: func() *types.Object {
+				dissolve := event(1, "TAPE1")
+				dissolve.Transition = types.Transition{Type: types.Dissolve, Duration: 12}
+				wipe := event(2, "TAPE2")
+				wipe.Transition = types.Transition{Type: types.Wipe, Code: "W010", Duration: 100}
+				audio := event(3, "AX")
+				audio.Track = "A2"
+				return &types.Object{Events: []types.Event{dissolve, wipe, audio}}
+			}(),
+			want: []string{
+				"001  TAPE1    V     D    012 10:00:00:00",
+				"002  TAPE2    V     W010 100 10:00:00:00",
+				"003  AX       A2    C        10:00:00:00",
+			},
+		},
+		{
+			name: "speed and comments",
+			obj: func() *types.Object {
+				e := event(1, "TAPE1")
+				e.Speed = &types.Speed{Reel: "TAPE1", FPS: -12.5, Entry: tc("10:00:01:00")}
+				e.ClipName = "CLIP\nNAME"
+				e.Locators = []types.Locator{{Timecode: tc("01:00:00:12"), Color: "GREEN", Comment: "note"}}
+				e.ASCSAT = &sat
+				e.ASCCCXML = "cc01"
+				e.Comments = []string{"NOTE"}
+				e.Extra = []string{"AUD  3    4"}
+				return &types.Object{Events: []types.Event{e}}
+			}(),
+			want: []string{
+				"M2   TAPE1          -12.5                10:00:01:00\nAUD  3    4\n* FROM CLIP NAME: CLIP NAME\n",
+				"* LOC: 01:00:00:12 GREEN   note\n*ASC_SAT 0.500000\n*ASC_CC_XML cc01\n* NOTE\n",
+			},
+			notWant: []string{"* AUD"},
+		},
+		{
+			name: "comments disabled",
+			obj: func() *types.Object {
+				e := event(1, "TAPE1")
+				e.ClipName = "CLIP"
+				e.ASCSAT = &sat
+				return &types.Object{Events: []types.Event{e}}
+			}(),
+			opts:    func(opts *WriteOptions) { opts.ClipNames, opts.ASCCDL = false, false },
+			notWant: []string{"CLIP", "ASC_SAT"},
+		},
+		{
+			name: "reel truncated at end by default",
+			obj:  &types.Object{Events: []types.Event{event(1, "A001C001_240426_R1AA")}},
+			want: []string{"001  A001C001 V"},
+		},
+		{
+			name: "reel truncated at start",
+			obj:  &types.Object{Events: []types.Event{event(1, "A001C001_240426_R1AA")}},
+			opts: func(opts *WriteOptions) { opts.Reels = ReelTruncateStart },
+			want: []string{"001  426_R1AA V"},
+		},
+		{
+			name: "reel map and longer limit",
+			obj:  &types.Object{Events: []types.Event{event(1, "A001C001_240426_R1AA"), event(2, "B 002")}},
+			opts: func(opts *WriteOptions) {
+				opts.ReelMap = map[string]string{"A001C001_240426_R1AA": "A001C001"}
+				opts.ReelLength = 32
+			},
+			want: []string{"001  A001C001 V", "002  B_002    V"},
+		},
+		{
+			name:    "reel too long",
+			obj:     &types.Object{Events: []types.Event{event(1, "A001C001_240426_R1AA")}},
+			opts:    func(opts *WriteOptions) { opts.Reels = ReelFail },
+			wantErr: errors.ErrOutputReelTooLong,
+		},
+		{
+			name:    "reel collision",
+			obj:     &types.Object{Events: []types.Event{event(1, "A001C001_A"), event(2, "A001C001_B")}},
+			wantErr: errors.ErrOutputReelCollision,
+		},
+		{
+			name: "illegal track",
+			obj: func() *types.Object {
+				e := event(1, "TAPE1")
+				e.Track = "A 2"
+				return &types.Object{Events: []types.Event{e}}
+			}(),
+			wantErr: errors.ErrOutputIllegalValue,
+		},
+		{
+			name: "track that is not a CMX3600 track",
+			obj: func() *types.Object {
+				e := event(1, "TAPE1")
+				e.Track = "VIDEO"
+				return &types.Object{Events: []types.Event{e}}
+			}(),
+			wantErr: errors.ErrOutputIllegalValue,
+		},
+		{
+			name:    "event number wider than three digits",
+			obj:     &types.Object{Events: []types.Event{event(1000, "TAPE1")}},
+			wantErr: errors.ErrOutputIllegalValue,
+		},
+		{
+			name:    "nil object",
+			wantErr: errors.ErrOutputNilObject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultWriteOptions()
+			opts.CRLF = false
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			got, err := WriteWithOptions(tt.obj, opts)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("WriteWithOptions() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WriteWithOptions() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Output missing %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Output should not contain %q:\n%s", notWant, got)
+				}
+			}
+			if _, err := Read(got); err != nil {
+				t.Errorf("Output does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestFormatSOP(t *testing.T) {
+	sop := types.SOP{
+		Slope:  [3]float64{1.1, 1, 0.95},
+		Offset: [3]float64{-0.0000001, 0.01, -0.01},
+		Power:  [3]float64{1, 1, 1},
+	}
+	want := "(1.100000 1.000000 0.950000)(0.000000 0.010000 -0.010000)(1.000000 1.000000 1.000000)"
+	if got := FormatSOP(sop); got != want {
+		t.Errorf("FormatSOP() = %q, want %q", got, want)
+	}
+	if got, err := ParseSOP(want); err != nil || FormatSOP(got) != want {
+		t.Errorf("ParseSOP() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,109 @@
+package errors
+
+import "fmt"
+
+// ErrorCategory represents the main category of an error
+type ErrorCategory int32
+
+// Error categories
+const (
+	CategoryInput ErrorCategory = iota + 1
+	CategoryOutput
+)
+
+// Error represents an EDL error.
+type Error struct {
+	Category    ErrorCategory
+	SubCategory int32
+	Message     string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("edl: [%d.%d] %s", e.Category, e.SubCategory, e.Message)
+}
+
+// Code returns the unique error code
+func (e *Error) Code() int32 {
+	return int32(e.Category)*1000 + e.SubCategory
+}
+
+// WithContext returns a new Error with additional context appended to the message
+func (e *Error) WithContext(context string) *Error {
+	return &Error{
+		Category:    e.Category,
+		SubCategory: e.SubCategory,
+		Message:     e.Message + ": " + context,
+	}
+}
+
+// Error definitions for EDL parsing
+var (
+	// Input errors
+	ErrInputEmpty = &Error{
+		Category: CategoryInput,
+		Message:  "empty input",
+	}
+	ErrInputMalformedEvent = &Error{
+		Category: CategoryInput,
+		Message:  "malformed event line",
+	}
+	ErrInputMalformedTimecode = &Error{
+		Category: CategoryInput,
+		Message:  "malformed timecode",
+	}
+	ErrInputMalformedFCM = &Error{
+		Category: CategoryInput,
+		Message:  "malformed FCM line",
+	}
+	ErrInputMalformedSpeed = &Error{
+		Category: CategoryInput,
+		Message:  "malformed M2 speed change",
+	}
+	ErrInputMalformedComment = &Error{
+		Category: CategoryInput,
+		Message:  "malformed comment",
+	}
+	ErrInputMalformedASC = &Error{
+		Category: CategoryInput,
+		Message:  "malformed ASC CDL value",
+	}
+	ErrInputASCOutOfRange = &Error{
+		Category: CategoryInput,
+		Message:  "ASC CDL value out of range",
+	}
+
+	// Output errors
+	ErrOutputNilObject = &Error{
+		Category: CategoryOutput,
+		Message:  "nil EDL object",
+	}
+	ErrOutputReelTooLong = &Error{
+		Category: CategoryOutput,
+		Message:  "reel name too long",
+	}
+	ErrOutputReelCollision = &Error{
+		Category: CategoryOutput,
+		Message:  "reel names collide after shortening",
+	}
+	ErrOutputIllegalValue = &Error{
+		Category: CategoryOutput,
+		Message:  "illegal value in event",
+	}
+)
+
+// IsCategory checks if an error belongs to a specific category
+func IsCategory(err error, category ErrorCategory) bool {
+	if edlErr, ok := err.(*Error); ok {
+		return edlErr.Code()/1000 == int32(category)
+	}
+	return false
+}
+
+// IsError checks if an error matches a specific category and subcategory
+func IsError(err error, category ErrorCategory, subCategory int32) bool {
+	if edlErr, ok := err.(*Error); ok {
+		code := edlErr.Code()
+		return code/1000 == int32(category) && code%1000 == subCategory
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package libedl
+
+import (
+	"lib-post-interchange/libedl/edl"
+	"lib-post-interchange/libedl/types"
+)
+
+// Handler provides the main interface for interacting with EDL files.
+// It mirrors the ALE handler in libale.
+type Handler struct{}
+
+// New creates a new Handler instance that provides access to all EDL operations.
+func New() *Handler {
+	return &Handler{}
+}
+
+// ReadFile loads and parses a CMX3600 EDL from the filesystem.
+func (h *Handler) ReadFile(filepath string) (*types.Object, error) {
+	return edl.ReadFile(filepath)
+}
+
+// Read parses CMX3600 EDL data from any string source.
+func (h *Handler) Read(input string) (*types.Object, error) {
+	return edl.Read(input)
+}
@@ -0,0 +1,26 @@
+package libedl
+
+import (
+	"testing"
+)
+
+func TestHandler_ReadFile(t *testing.T) {
+	handler := New()
+
+	t.Run("non-existent file", func(t *testing.T) {
+		_, err := handler.ReadFile("testdata/nonexistent.edl")
+		if err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+	})
+
+	t.Run("valid file", func(t *testing.T) {
+		obj, err := handler.ReadFile("../../samples/EDL/A001R1AA_CUT.edl")
+		if err != nil {
+			t.Fatalf("Handler.ReadFile() error = %v", err)
+		}
+		if len(obj.Events) == 0 {
+			t.Error("Expected events")
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"lib-post-interchange/timecode"
+)
+
+// FrameCodeMode is the value of an FCM line.
+type FrameCodeMode string
+
+// Frame code modes
+const (
+	NonDropFrame FrameCodeMode = "NON-DROP FRAME"
+	DropFrame    FrameCodeMode = "DROP FRAME"
+)
+
+// FCMFor returns the frame code mode for timecode counted at a rate.
+func FCMFor(rate timecode.Rate) FrameCodeMode {
+	if rate.DropFrame {
+		return DropFrame
+	}
+	return NonDropFrame
+}
+
+// TransitionType is the kind of transition into an event.
+type TransitionType int
+
+// Transition types
+const (
+	Cut TransitionType = iota
+	Dissolve
+	Wipe
+	Key
+)
+
+// Transition describes how an event begins.
+type Transition struct {
+	Type TransitionType
+	// Code is the transition as written, such as "C", "D", "W001" or "KB".
+	Code string
+	// Duration is the length of a dissolve, wipe or key in frames.
+	Duration int
+}
+
+// Speed is an M2 motion effect on an event.
+type Speed struct {
+	Reel string
+	// FPS is the playback speed in frames per second. It is negative for reverse motion.
+	FPS float64
+	// Entry is the source timecode at which the effect begins.
+	Entry timecode.Timecode
+}
+
+// Locator is a marker from a "* LOC:" comment.
+type Locator struct {
+	Timecode timecode.Timecode
+	Color    string
+	Comment  string
+}
+
+// SOP holds the slope, offset and power of an ASC CDL, each for red, green and blue.
+type SOP struct {
+	Slope  [3]float64
+	Offset [3]float64
+	Power  [3]float64
+}
+
+// Event is one event line of an EDL with the lines that follow it. A
+// dissolve or wipe is written as two event lines with the same number; each
+// becomes its own Event.
+type Event struct {
+	Number int
+	Reel   string
+	// Track is the track type as written, such as "V", "A", "A2" or "AA/V".
+	Track      string
+	Transition Transition
+	SourceIn   timecode.Timecode
+	SourceOut  timecode.Timecode
+	RecordIn   timecode.Timecode
+	RecordOut  timecode.Timecode
+	// FCM is the frame code mode in effect for the event.
+	FCM   FrameCodeMode
+	Speed *Speed
+	// ClipName is from "* FROM CLIP NAME:" and ToClipName from "* TO CLIP NAME:".
+	ClipName   string
+	ToClipName string
+	SourceFile string
+	Locators   []Locator
+	ASCSOP     *SOP
+	ASCSAT     *float64
+	// ASCCCXML is the ID of a color correction in a separate CDL file.
+	ASCCCXML string
+	// Comments holds the comment lines not otherwise recognised, without their leading "*".
+	Comments []string
+	// Extra holds the lines that follow the event and are neither comments nor
+	// M2 lines, such as "AUD 3 4" or "SPLIT:", as written.
+	Extra []string
+	// Line is the line number of the event line, from 1.
+	Line int
+}
+
+// HasVideo reports whether the event is on a video track.
+func (e Event) HasVideo() bool {
+	switch e.Track {
+	case "V", "B", "A/V", "AA/V", "B/V":
+		return true
+	}
+	return false
+}
+
+// Object represents a parsed CMX3600 edit decision list.
+type Object struct {
+	Title  string
+	FCM    FrameCodeMode
+	Events []Event
+	// Comments holds comment lines before the first event.
+	Comments []string
+	// Extra holds other lines before the first event, as written.
+	Extra []string
+}
+
+// DropFrame reports whether the list's timecodes count in drop frame.
+func (o *Object) DropFrame() bool {
+	return o.FCM == DropFrame
+}
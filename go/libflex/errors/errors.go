@@ -0,0 +1,97 @@
+package errors
+
+import "fmt"
+
+// ErrorCategory represents the main category of an error
+type ErrorCategory int32
+
+// Error categories
+const (
+	CategoryInput ErrorCategory = iota + 1
+	CategoryOutput
+)
+
+// Error represents a FLEx error.
+type Error struct {
+	Category    ErrorCategory
+	SubCategory int32
+	Message     string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("flex: [%d.%d] %s", e.Category, e.SubCategory, e.Message)
+}
+
+// Code returns the unique error code
+func (e *Error) Code() int32 {
+	return int32(e.Category)*1000 + e.SubCategory
+}
+
+// WithContext returns a new Error with additional context appended to the message
+func (e *Error) WithContext(context string) *Error {
+	return &Error{
+		Category:    e.Category,
+		SubCategory: e.SubCategory,
+		Message:     e.Message + ": " + context,
+	}
+}
+
+// Error definitions for FLEx parsing
+var (
+	// Input errors
+	ErrInputEmpty = &Error{
+		Category: CategoryInput,
+		Message:  "empty input",
+	}
+	ErrInputUnknownRecord = &Error{
+		Category: CategoryInput,
+		Message:  "unknown record type",
+	}
+	ErrInputMalformedRecord = &Error{
+		Category: CategoryInput,
+		Message:  "malformed record",
+	}
+	ErrInputMalformedTimecode = &Error{
+		Category: CategoryInput,
+		Message:  "malformed timecode",
+	}
+	ErrInputMalformedRate = &Error{
+		Category: CategoryInput,
+		Message:  "malformed frame rate",
+	}
+	ErrInputOrphanRecord = &Error{
+		Category: CategoryInput,
+		Message:  "record before the first edit record",
+	}
+
+	// Output errors
+	ErrOutputNilObject = &Error{
+		Category: CategoryOutput,
+		Message:  "nil FLEx object",
+	}
+	ErrOutputValueTooLong = &Error{
+		Category: CategoryOutput,
+		Message:  "value too long for its field",
+	}
+	ErrOutputIllegalValue = &Error{
+		Category: CategoryOutput,
+		Message:  "illegal value in event",
+	}
+)
+
+// IsCategory checks if an error belongs to a specific category
+func IsCategory(err error, category ErrorCategory) bool {
+	if flexErr, ok := err.(*Error); ok {
+		return flexErr.Code()/1000 == int32(category)
+	}
+	return false
+}
+
+// IsError checks if an error matches a specific category and subcategory
+func IsError(err error, category ErrorCategory, subCategory int32) bool {
+	if flexErr, ok := err.(*Error); ok {
+		code := flexErr.Code()
+		return code/1000 == int32(category) && code%1000 == subCategory
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package flex
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/keycode"
+	"lib-post-interchange/libflex/errors"
+	"lib-post-interchange/libflex/types"
+	"lib-post-interchange/timecode"
+)
+
+// ReadFile reads and parses a FLEx file from the filesystem.
+func ReadFile(filepath string) (*types.Object, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return Read(string(data))
+}
+
+// Read parses FLEx data from a string. Lines ending in LF, CRLF or CR alone
+// are accepted. Scene, description, film, sound and comment records belong
+// to the edit record before them.
+func Read(input string) (*types.Object, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.ErrInputEmpty
+	}
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+	input = strings.ReplaceAll(input, "\r", "\n")
+
+	obj := &types.Object{}
+	var current *types.Event
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(line) < 3 || !isDigits(line[:3]) || (len(line) > 3 && line[3] != ' ') {
+			return nil, errors.ErrInputUnknownRecord.WithContext(fmt.Sprintf("line %d: %q", lineNumber, line))
+		}
+		recordType := types.RecordType(line[:3])
+		if current == nil && (recordType == types.RecordScene || recordType == types.RecordDescription ||
+			recordType == types.RecordFilm || recordType == types.RecordSound) {
+			return nil, errors.ErrInputOrphanRecord.WithContext(fmt.Sprintf("line %d: %q", lineNumber, line))
+		}
+
+		var err *errors.Error
+		switch recordType {
+		case types.RecordHeader:
+			obj.Version = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[3:]), headerLabel))
+
+		case types.RecordTitle:
+			text := strings.TrimSpace(line[3:])
+			if !strings.HasPrefix(text, titleLabel) {
+				err = errors.ErrInputMalformedRecord.WithContext("expected " + titleLabel)
+				break
+			}
+			obj.Title = strings.TrimSpace(strings.TrimPrefix(text, titleLabel))
+
+		case types.RecordEdit:
+			var event types.Event
+			if event, err = readEdit(line); err == nil {
+				event.Line = lineNumber
+				obj.Events = append(obj.Events, event)
+				current = &obj.Events[len(obj.Events)-1]
+			}
+
+		case types.RecordScene:
+			err = readScene(current, line)
+
+		case types.RecordDescription:
+			text := strings.TrimSpace(line[3:])
+			if current.Description != "" && text != "" {
+				current.Description += " "
+			}
+			current.Description += text
+
+		case types.RecordFilm:
+			err = readFilm(current, line)
+
+		case types.RecordSound:
+			err = readSound(current, line)
+
+		case types.RecordComment:
+			comment := strings.TrimSpace(line[3:])
+			if current == nil {
+				obj.Comments = append(obj.Comments, comment)
+			} else {
+				current.Comments = append(current.Comments, comment)
+			}
+		}
+		if err != nil {
+			return nil, err.WithContext(fmt.Sprintf("line %d: %q", lineNumber, line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// readEdit parses an edit record.
+func readEdit(line string) (types.Event, *errors.Error) {
+	values, err := parseRecord(line, editFields)
+	if err != nil {
+		return types.Event{}, err
+	}
+	var event types.Event
+	number, convErr := strconv.Atoi(values[0])
+	if convErr != nil || number < 0 {
+		return types.Event{}, errors.ErrInputMalformedRecord.WithContext("edit number " + strconv.Quote(values[0]))
+	}
+	event.Number = number
+	switch values[1] {
+	case "1", "2":
+		event.Field, _ = strconv.Atoi(values[1])
+	default:
+		return types.Event{}, errors.ErrInputMalformedRecord.WithContext("field " + strconv.Quote(values[1]))
+	}
+	event.Reel = values[2]
+
+	if values[5] != "" {
+		rate, rateErr := timecode.ParseRate(values[5])
+		if rateErr != nil {
+			return types.Event{}, errors.ErrInputMalformedRate.WithContext(values[5])
+		}
+		event.Rate = rate
+	}
+	for i, tc := range []*timecode.Timecode{&event.VideoIn, &event.VideoOut} {
+		value := values[3+i]
+		parsed, tcErr := timecode.Parse(value)
+		if tcErr == nil && !event.Rate.IsZero() {
+			tcErr = parsed.Validate(event.Rate)
+		}
+		if tcErr != nil {
+			return types.Event{}, errors.ErrInputMalformedTimecode.WithContext(value)
+		}
+		*tc = parsed
+	}
+	if !event.Rate.IsZero() && event.VideoOut.ToFrames(event.Rate) < event.VideoIn.ToFrames(event.Rate) {
+		return types.Event{}, errors.ErrInputMalformedRecord.WithContext("Out is before In")
+	}
+	return event, nil
+}
+
+// readScene parses a scene record into an event.
+func readScene(event *types.Event, line string) *errors.Error {
+	values, err := parseRecord(line, sceneFields)
+	if err != nil {
+		return err
+	}
+	event.Scene, event.Take, event.Camroll, event.Soundroll = values[0], values[1], values[2], values[3]
+	return nil
+}
+
+// readFilm parses a film record into an event.
+func readFilm(event *types.Event, line string) *errors.Error {
+	values, err := parseRecord(line, filmFields)
+	if err != nil {
+		return err
+	}
+	if values[1] != "" {
+		perfs, convErr := strconv.Atoi(values[1])
+		if convErr != nil || perfs <= 0 {
+			return errors.ErrInputMalformedRecord.WithContext("perforations " + strconv.Quote(values[1]))
+		}
+		event.Perfs = perfs
+	}
+	if !isPullin(values[3]) {
+		return errors.ErrInputMalformedRecord.WithContext("pull-in " + strconv.Quote(values[3]))
+	}
+	event.Gauge, event.Keycode, event.Pullin = values[0], values[2], strings.ToUpper(values[3])
+	event.Labroll, event.Ink = values[4], values[5]
+	return nil
+}
+
+// readSound parses a sound record into an event.
+func readSound(event *types.Event, line string) *errors.Error {
+	values, err := parseRecord(line, soundFields)
+	if err != nil {
+		return err
+	}
+	if values[0] != "" {
+		tc, tcErr := timecode.Parse(values[0])
+		if tcErr != nil {
+			return errors.ErrInputMalformedTimecode.WithContext(values[0])
+		}
+		event.SoundTC = tc
+	}
+	return nil
+}
+
+// isPullin reports whether s is empty or a pulldown phase, as accepted by
+// keycode.PullinPhase.
+func isPullin(s string) bool {
+	_, err := keycode.PullinPhase(s)
+	return err == nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
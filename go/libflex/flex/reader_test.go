@@ -0,0 +1,160 @@
+package flex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libflex/errors"
+	"lib-post-interchange/libflex/types"
+	"lib-post-interchange/timecode"
+)
+
+func TestReadFile(t *testing.T) {
+	obj, err := ReadFile("../../../samples/FLEx/A001R1AA.flx")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if obj.Version != "1.00" || obj.Title != "DAY 4 DAILIES A001" {
+		t.Errorf("Version = %q, Title = %q", obj.Version, obj.Title)
+	}
+	if len(obj.Comments) != 1 || !strings.HasPrefix(obj.Comments[0], "Transferred on") {
+		t.Errorf("Comments = %q", obj.Comments)
+	}
+	if len(obj.Events) != 3 {
+		t.Fatalf("Got %d events, want 3", len(obj.Events))
+	}
+
+	want := types.Event{
+		Number:      1,
+		Field:       1,
+		Reel:        "001",
+		VideoIn:     timecode.MustParse("01:00:00:00"),
+		VideoOut:    timecode.MustParse("01:00:12:10"),
+		Rate:        timecode.Rate29_97,
+		Scene:       "12A",
+		Take:        "3",
+		Camroll:     "A001",
+		Soundroll:   "S004",
+		Description: "EXT. PIER - NIGHT",
+		Gauge:       "35",
+		Perfs:       4,
+		Keycode:     "KJ 23 4512 8765+07",
+		Pullin:      "A",
+		Labroll:     "L017",
+		Ink:         "012 0456",
+		SoundTC:     timecode.MustParse("13:22:05:14"),
+		Line:        4,
+	}
+	if !reflect.DeepEqual(obj.Events[0], want) {
+		t.Errorf("First event = %+v\nwant %+v", obj.Events[0], want)
+	}
+	if !reflect.DeepEqual(obj.Events[1].Comments, []string{"Boom in shot at end"}) {
+		t.Errorf("Comments = %q", obj.Events[1].Comments)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr *errors.Error
+		check   func(*testing.T, *types.Object)
+	}{
+		{
+			name:    "empty input",
+			input:   "\r\n\r\n",
+			wantErr: errors.ErrInputEmpty,
+		},
+		{
+			name: "drop frame, field 2 and unknown records",
+			input: "011 Equipment: Spirit\n" +
+				"100 Edit 0007 Field 2 Reel 002      In 01:00:00;00 Out 01:00:01;00 FPS 29.97 DF\n" +
+				"500 Video: 1080\n",
+			check: func(t *testing.T, obj *types.Object) {
+				event := obj.Events[0]
+				if event.Number != 7 || event.Field != 2 || event.Rate != timecode.Rate29_97DF || !event.VideoIn.DropFrame {
+					t.Errorf("Event = %+v", event)
+				}
+				if event.HasScene() || event.HasFilm() || event.HasSound() {
+					t.Errorf("Event should have no scene, film or sound: %+v", event)
+				}
+			},
+		},
+		{
+			name: "short records and lower case pull-in",
+			input: "100 Edit 0001 Field 1 Reel 001      In 01:00:00:00 Out 01:00:01:00\n" +
+				"110 Scene 4          Take 2\n" +
+				"200 Gauge 16 Perf   Key EK 12 3456 7890+00 Pullin c\n",
+			check: func(t *testing.T, obj *types.Object) {
+				event := obj.Events[0]
+				if !event.Rate.IsZero() || event.Scene != "4" || event.Take != "2" || event.Camroll != "" {
+					t.Errorf("Event = %+v", event)
+				}
+				if event.Gauge != "16" || event.Perfs != 0 || event.Keycode != "EK 12 3456 7890+00" || event.Pullin != "C" {
+					t.Errorf("Film record = %+v", event)
+				}
+			},
+		},
+		{
+			name: "X frame pull-in and description records",
+			input: "100 Edit 0001 Field 1 Reel 001      In 01:00:00:00 Out 01:00:01:00\n" +
+				"120 INT. KITCHEN -\n" +
+				"120 DAY\n" +
+				"200 Gauge 35 Perf 4 Key KJ 23 4512 8765+07 Pullin X\n",
+			check: func(t *testing.T, obj *types.Object) {
+				event := obj.Events[0]
+				if event.Pullin != "X" || event.Description != "INT. KITCHEN - DAY" {
+					t.Errorf("Event = %+v", event)
+				}
+			},
+		},
+		{
+			name:    "record before an edit",
+			input:   "110 Scene 4          Take 2\n",
+			wantErr: errors.ErrInputOrphanRecord,
+		},
+		{
+			name:    "not a record",
+			input:   "Title: DAY 4\n",
+			wantErr: errors.ErrInputUnknownRecord,
+		},
+		{
+			name:    "misaligned field",
+			input:   "100 Edit 1 Field 1 Reel 001 In 01:00:00:00 Out 01:00:01:00\n",
+			wantErr: errors.ErrInputMalformedRecord,
+		},
+		{
+			name:    "malformed timecode",
+			input:   "100 Edit 0001 Field 1 Reel 001      In 01:00:00:30 Out 01:00:01:00 FPS 25\n",
+			wantErr: errors.ErrInputMalformedTimecode,
+		},
+		{
+			name:    "malformed rate",
+			input:   "100 Edit 0001 Field 1 Reel 001      In 01:00:00:00 Out 01:00:01:00 FPS 25 DF\n",
+			wantErr: errors.ErrInputMalformedRate,
+		},
+		{
+			name: "pull-in out of range",
+			input: "100 Edit 0001 Field 1 Reel 001      In 01:00:00:00 Out 01:00:01:00\n" +
+				"200 Gauge 35 Perf 4 Key KJ 23 4512 8765+07 Pullin E\n",
+			wantErr: errors.ErrInputMalformedRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := Read(tt.input)
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			tt.check(t, obj)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package flex reads and writes FLEx telecine logs, the film transfer log
+// format ALE was derived from.
+//
+// Each line of a FLEx file is a record: a three-digit record type followed
+// by its fields. An event is an edit record (100) followed by its scene and
+// take (110), scene description (120), film (200) and sound (300) records:
+//
+//	100 Edit 0001 Field 1 Reel 001      In 01:00:00:00 Out 01:00:12:10 FPS 29.97
+//	110 Scene 12A        Take 3     Cam Roll A001     Sound Roll S004
+//	120 EXT. PIER - NIGHT
+//	200 Gauge 35 Perf 4 Key KJ 23 4512 8765+07 Pullin A Lab Roll L017     Ink 012 0456
+//	300 Sound TC 13:22:05:14
+//
+// The scene description is free text. In the other records each field is a
+// space, its label, a space and its value padded to the field width, so a
+// value starts in the same column on every record of a type. Records other
+// than those in types are ignored when reading.
+package flex
+
+import (
+	"fmt"
+	"strings"
+
+	"lib-post-interchange/libflex/errors"
+)
+
+// field is a labelled value of fixed width in a record.
+type field struct {
+	label string
+	width int
+}
+
+// Field layouts of the records with labelled fields
+var (
+	editFields = []field{
+		{"Edit", 4}, {"Field", 1}, {"Reel", 8}, {"In", 11}, {"Out", 11}, {"FPS", 8},
+	}
+	sceneFields = []field{
+		{"Scene", 10}, {"Take", 5}, {"Cam Roll", 8}, {"Sound Roll", 8},
+	}
+	filmFields = []field{
+		{"Gauge", 2}, {"Perf", 1}, {"Key", 18}, {"Pullin", 1}, {"Lab Roll", 8}, {"Ink", 12},
+	}
+	soundFields = []field{
+		{"Sound TC", 11},
+	}
+)
+
+// Labels and longest values of the free text records, which keep within 80
+// columns
+const (
+	headerLabel    = "FLEx"
+	titleLabel     = "Title:"
+	maxTitle       = 69
+	maxComment     = 76
+	maxDescription = 76
+)
+
+// formatRecord lays out values in the fields of a record, leaving out empty
+// fields at the end. A value longer than its field is an error.
+func formatRecord(recordType string, fields []field, values ...string) (string, *errors.Error) {
+	for len(values) > 0 && values[len(values)-1] == "" {
+		values = values[:len(values)-1]
+	}
+	var b strings.Builder
+	b.WriteString(recordType)
+	for i, f := range fields[:len(values)] {
+		if len(values[i]) > f.width {
+			return "", errors.ErrOutputValueTooLong.WithContext(fmt.Sprintf("%s %q is longer than %d characters", f.label, values[i], f.width))
+		}
+		fmt.Fprintf(&b, " %s %-*s", f.label, f.width, values[i])
+	}
+	return b.String(), nil
+}
+
+// parseRecord returns the trimmed values of the fields of a record, which
+// begins with its record type. Fields missing from the end of a record,
+// whose trailing spaces may have been stripped, are empty.
+func parseRecord(record string, fields []field) ([]string, *errors.Error) {
+	values := make([]string, len(fields))
+	pos := 3
+	for i, f := range fields {
+		if pos >= len(record) {
+			break
+		}
+		label := " " + f.label + " "
+		end := pos + len(label)
+		if end > len(record) {
+			end = len(record)
+		}
+		if !strings.EqualFold(record[pos:end], label[:end-pos]) {
+			return nil, errors.ErrInputMalformedRecord.WithContext(fmt.Sprintf("expected %s at column %d", f.label, pos+2))
+		}
+		pos = end
+		end = min(pos+f.width, len(record))
+		values[i] = strings.TrimSpace(record[pos:end])
+		pos = end
+	}
+	if pos < len(record) && strings.TrimSpace(record[pos:]) != "" {
+		return nil, errors.ErrInputMalformedRecord.WithContext(fmt.Sprintf("unexpected text at column %d", pos+1))
+	}
+	return values, nil
+}
+
+// singleLine replaces line breaks, which would end a record early, with spaces.
+func singleLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,139 @@
+package flex
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"lib-post-interchange/libflex/errors"
+	"lib-post-interchange/libflex/types"
+)
+
+// WriteFile writes a FLEx object to a file at the specified path.
+func WriteFile(filepath string, flex *types.Object) error {
+	data, err := Write(flex)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath, []byte(data), 0644)
+}
+
+// Write converts a FLEx object to FLEx text with CRLF line endings. Each
+// event is an edit record followed by scene, description, film and sound
+// records when the event has values for them, and then its comments. A
+// comment longer than a record is split across several.
+func Write(flex *types.Object) (string, error) {
+	if flex == nil {
+		return "", errors.ErrOutputNilObject
+	}
+	var builder strings.Builder
+	line := func(s string) {
+		builder.WriteString(strings.TrimRight(s, " ") + "\r\n")
+	}
+
+	version := flex.Version
+	if version == "" {
+		version = types.Version
+	}
+	line(fmt.Sprintf("%s %s %s", types.RecordHeader, headerLabel, singleLine(version)))
+	title := singleLine(flex.Title)
+	if len(title) > maxTitle {
+		return "", errors.ErrOutputValueTooLong.WithContext(fmt.Sprintf("title %q is longer than %d characters", title, maxTitle))
+	}
+	line(fmt.Sprintf("%s %s %s", types.RecordTitle, titleLabel, title))
+	for _, comment := range flex.Comments {
+		for _, record := range commentRecords(comment) {
+			line(record)
+		}
+	}
+
+	for _, event := range flex.Events {
+		records, err := eventRecords(event)
+		if err != nil {
+			return "", err.WithContext(fmt.Sprintf("edit %d", event.Number))
+		}
+		for _, record := range records {
+			line(record)
+		}
+	}
+	return builder.String(), nil
+}
+
+// eventRecords formats the records of an event.
+func eventRecords(event types.Event) ([]string, *errors.Error) {
+	if event.Number < 0 || event.Number > 9999 {
+		return nil, errors.ErrOutputIllegalValue.WithContext("edit number " + strconv.Itoa(event.Number))
+	}
+	field := event.Field
+	if field == 0 {
+		field = 1
+	}
+	if field != 1 && field != 2 {
+		return nil, errors.ErrOutputIllegalValue.WithContext("field " + strconv.Itoa(event.Field))
+	}
+	record, err := formatRecord(string(types.RecordEdit), editFields,
+		fmt.Sprintf("%04d", event.Number), strconv.Itoa(field), event.Reel,
+		event.VideoIn.String(), event.VideoOut.String(), event.Rate.String())
+	if err != nil {
+		return nil, err
+	}
+	records := []string{record}
+
+	if event.HasScene() {
+		record, err := formatRecord(string(types.RecordScene), sceneFields,
+			event.Scene, event.Take, event.Camroll, event.Soundroll)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if description := strings.TrimSpace(singleLine(event.Description)); description != "" {
+		if len(description) > maxDescription {
+			return nil, errors.ErrOutputValueTooLong.WithContext(fmt.Sprintf("description %q is longer than %d characters", description, maxDescription))
+		}
+		records = append(records, string(types.RecordDescription)+" "+description)
+	}
+	if event.HasFilm() {
+		if !isPullin(event.Pullin) {
+			return nil, errors.ErrOutputIllegalValue.WithContext("pull-in " + strconv.Quote(event.Pullin))
+		}
+		perfs := ""
+		if event.Perfs != 0 {
+			perfs = strconv.Itoa(event.Perfs)
+		}
+		record, err := formatRecord(string(types.RecordFilm), filmFields,
+			event.Gauge, perfs, event.Keycode, event.Pullin, event.Labroll, event.Ink)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if event.HasSound() {
+		record, err := formatRecord(string(types.RecordSound), soundFields, event.SoundTC.String())
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	for _, comment := range event.Comments {
+		records = append(records, commentRecords(comment)...)
+	}
+	return records, nil
+}
+
+// commentRecords splits a comment into comment records, breaking at spaces
+// where it can.
+func commentRecords(comment string) []string {
+	var records []string
+	text := strings.TrimSpace(singleLine(comment))
+	for len(text) > maxComment {
+		cut := strings.LastIndex(text[:maxComment+1], " ")
+		if cut <= 0 {
+			cut = maxComment
+		}
+		records = append(records, string(types.RecordComment)+" "+strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	return append(records, string(types.RecordComment)+" "+text)
+}
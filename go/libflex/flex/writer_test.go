@@ -0,0 +1,75 @@
+package flex
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"lib-post-interchange/libflex/errors"
+	"lib-post-interchange/libflex/types"
+	"lib-post-interchange/timecode"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	want, err := os.ReadFile("../../../samples/FLEx/A001R1AA.flx")
+	if err != nil {
+		t.Fatalf("Failed to read sample file: %v", err)
+	}
+	obj, err := Read(string(want))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	got, err := Write(obj)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Round trip differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	event := types.Event{
+		Number:   12,
+		Reel:     "002",
+		VideoIn:  timecode.MustParse("01:00:00;00"),
+		VideoOut: timecode.MustParse("01:00:01;00"),
+		Rate:     timecode.Rate29_97DF,
+		Keycode:  "KJ 23 4512 8765+07",
+		Pullin:   "X",
+		Comments: []string{strings.Repeat("long comment ", 8)},
+	}
+	got, err := Write(&types.Object{Title: "DF", Events: []types.Event{event}})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := "000 FLEx 1.00\r\n" +
+		"010 Title: DF\r\n" +
+		"100 Edit 0012 Field 1 Reel 002      In 01:00:00;00 Out 01:00:01;00 FPS 29.97 DF\r\n" +
+		"200 Gauge    Perf   Key KJ 23 4512 8765+07 Pullin X\r\n" +
+		"700 long comment long comment long comment long comment long comment long\r\n" +
+		"700 comment long comment long comment\r\n"
+	if got != want {
+		t.Errorf("Write() =\n%s\nwant:\n%s", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		obj     *types.Object
+		wantErr *errors.Error
+	}{
+		{"nil object", nil, errors.ErrOutputNilObject},
+		{"long title", &types.Object{Title: strings.Repeat("T", 70)}, errors.ErrOutputValueTooLong},
+		{"long reel", &types.Object{Events: []types.Event{{Reel: "A001R1AA_X"}}}, errors.ErrOutputValueTooLong},
+		{"field 3", &types.Object{Events: []types.Event{{Field: 3}}}, errors.ErrOutputIllegalValue},
+		{"pull-in", &types.Object{Events: []types.Event{{Pullin: "E"}}}, errors.ErrOutputIllegalValue},
+		{"long description", &types.Object{Events: []types.Event{{Description: strings.Repeat("D", 77)}}}, errors.ErrOutputValueTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Write(tt.obj); err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+				t.Errorf("Write() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
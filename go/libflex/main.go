@@ -0,0 +1,25 @@
+package libflex
+
+import (
+	"lib-post-interchange/libflex/flex"
+	"lib-post-interchange/libflex/types"
+)
+
+// Handler provides the main interface for interacting with FLEx telecine logs.
+// It mirrors the ALE handler in libale.
+type Handler struct{}
+
+// New creates a new Handler instance that provides access to all FLEx operations.
+func New() *Handler {
+	return &Handler{}
+}
+
+// ReadFile loads and parses a FLEx telecine log from the filesystem.
+func (h *Handler) ReadFile(filepath string) (*types.Object, error) {
+	return flex.ReadFile(filepath)
+}
+
+// Read parses FLEx data from any string source.
+func (h *Handler) Read(input string) (*types.Object, error) {
+	return flex.Read(input)
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"lib-post-interchange/timecode"
+)
+
+// RecordType is the three-digit number that begins each FLEx record.
+type RecordType string
+
+// Record types
+const (
+	// RecordHeader carries the FLEx version.
+	RecordHeader RecordType = "000"
+	// RecordTitle carries the title of the transfer.
+	RecordTitle RecordType = "010"
+	// RecordEdit begins an event with its video reel, field and timecodes.
+	RecordEdit RecordType = "100"
+	// RecordScene carries the scene, take, camera roll and sound roll.
+	RecordScene RecordType = "110"
+	// RecordDescription is a line of the scene description.
+	RecordDescription RecordType = "120"
+	// RecordFilm carries the film gauge, perforations, key number, pulldown,
+	// lab roll and ink number.
+	RecordFilm RecordType = "200"
+	// RecordSound carries the sound timecode.
+	RecordSound RecordType = "300"
+	// RecordComment is a line of free text.
+	RecordComment RecordType = "700"
+)
+
+// Version is the FLEx version written in header records.
+const Version = "1.00"
+
+// Event is one edit record with the scene, description, film, sound and
+// comment records that follow it.
+type Event struct {
+	Number int
+	// Field is the video field, 1 or 2, on which the transfer starts.
+	Field int
+	// Reel is the video reel the transfer was recorded to.
+	Reel      string
+	VideoIn   timecode.Timecode
+	VideoOut  timecode.Timecode
+	Rate      timecode.Rate
+	Scene     string
+	Take      string
+	Camroll   string
+	Soundroll string
+	// Description is the scene description, its records joined by spaces.
+	Description string
+	// Gauge is the film width in millimetres, such as "35" or "16".
+	Gauge string
+	// Perfs is the number of perforations per frame. It is zero when unknown.
+	Perfs int
+	// Keycode is the key number of the first frame, such as "KJ 23 4512 8765+07".
+	Keycode string
+	// Pullin is the pulldown phase of the first video frame, one of the
+	// phases in keycode.PulldownPhases.
+	Pullin  string
+	Labroll string
+	// Ink is the ink number of the first frame.
+	Ink      string
+	SoundTC  timecode.Timecode
+	Comments []string
+	// Line is the line number of the edit record, from 1.
+	Line int
+}
+
+// HasFilm reports whether the event has a film record.
+func (e Event) HasFilm() bool {
+	return e.Gauge != "" || e.Perfs != 0 || e.Keycode != "" || e.Pullin != "" || e.Labroll != "" || e.Ink != ""
+}
+
+// HasScene reports whether the event has a scene record.
+func (e Event) HasScene() bool {
+	return e.Scene != "" || e.Take != "" || e.Camroll != "" || e.Soundroll != ""
+}
+
+// HasSound reports whether the event has a sound record.
+func (e Event) HasSound() bool {
+	return !e.SoundTC.IsZero()
+}
+
+// Object represents a parsed FLEx telecine log.
+type Object struct {
+	Version string
+	Title   string
+	Events  []Event
+	// Comments holds comment records before the first edit record.
+	Comments []string
+}
@@ -0,0 +1,314 @@
+// Package timecode provides SMPTE timecode and frame rate arithmetic shared by
+// the ALE and EDL packages.
+package timecode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Errors returned when parsing rates and timecodes.
+var (
+	ErrInvalidRate     = errors.New("invalid frame rate")
+	ErrInvalidTimecode = errors.New("invalid timecode")
+)
+
+// Rate is a frame rate expressed as a ratio of frames per second, such as
+// 24000/1001. DropFrame selects drop-frame counting for 29.97 and 59.94.
+type Rate struct {
+	Num       int
+	Den       int
+	DropFrame bool
+}
+
+// Common frame rates
+var (
+	Rate23_976  = Rate{Num: 24000, Den: 1001}
+	Rate24      = Rate{Num: 24, Den: 1}
+	Rate25      = Rate{Num: 25, Den: 1}
+	Rate29_97   = Rate{Num: 30000, Den: 1001}
+	Rate29_97DF = Rate{Num: 30000, Den: 1001, DropFrame: true}
+	Rate30      = Rate{Num: 30, Den: 1}
+	Rate48      = Rate{Num: 48, Den: 1}
+	Rate50      = Rate{Num: 50, Den: 1}
+	Rate59_94   = Rate{Num: 60000, Den: 1001}
+	Rate59_94DF = Rate{Num: 60000, Den: 1001, DropFrame: true}
+	Rate60      = Rate{Num: 60, Den: 1}
+)
+
+// decimalRates maps the decimal spellings of fractional rates to their ratios.
+var decimalRates = map[string]Rate{
+	"23.976": Rate23_976, "23.98": Rate23_976,
+	"29.97":  Rate29_97,
+	"47.952": {Num: 48000, Den: 1001}, "47.95": {Num: 48000, Den: 1001},
+	"59.94": Rate59_94,
+}
+
+// ParseRate parses a frame rate as written in ALE headers and elsewhere:
+// "25", "23.976", "29.97 DF", "29.97DF", "30000/1001" or "59.94 NDF".
+func ParseRate(s string) (Rate, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	dropFrame := false
+	switch {
+	case strings.HasSuffix(value, "NDF"):
+		value = strings.TrimSpace(strings.TrimSuffix(value, "NDF"))
+	case strings.HasSuffix(value, "DF"):
+		value = strings.TrimSpace(strings.TrimSuffix(value, "DF"))
+		dropFrame = true
+	}
+
+	var rate Rate
+	if num, den, ok := strings.Cut(value, "/"); ok {
+		n, err1 := strconv.Atoi(num)
+		d, err2 := strconv.Atoi(den)
+		if err1 != nil || err2 != nil || n <= 0 || d <= 0 {
+			return Rate{}, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+		}
+		rate = Rate{Num: n, Den: d}
+	} else if r, ok := decimalRates[value]; ok {
+		rate = r
+	} else {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return Rate{}, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+		}
+		rate = Rate{Num: n, Den: 1}
+	}
+
+	if dropFrame {
+		if !rate.CanDropFrame() {
+			return Rate{}, fmt.Errorf("%w: drop frame is only defined for 29.97 and 59.94: %q", ErrInvalidRate, s)
+		}
+		rate.DropFrame = true
+	}
+	return rate, nil
+}
+
+// FPS returns the exact number of frames per second.
+func (r Rate) FPS() float64 {
+	if r.Den == 0 {
+		return 0
+	}
+	return float64(r.Num) / float64(r.Den)
+}
+
+// Base returns the whole number of frames counted per second of timecode,
+// such as 30 for 29.97.
+func (r Rate) Base() int {
+	return int(math.Round(r.FPS()))
+}
+
+// IsZero reports whether the rate is unset.
+func (r Rate) IsZero() bool {
+	return r.Num == 0 || r.Den == 0
+}
+
+// String formats the rate as it is written in ALE headers, such as "23.976"
+// or "25", with a "DF" suffix for drop frame.
+func (r Rate) String() string {
+	var s string
+	switch {
+	case r.IsZero():
+		return ""
+	case r.Num%r.Den == 0:
+		s = strconv.Itoa(r.Num / r.Den)
+	default:
+		s = strconv.FormatFloat(math.Round(r.FPS()*1000)/1000, 'f', -1, 64)
+	}
+	if r.DropFrame {
+		s += " DF"
+	}
+	return s
+}
+
+// CanDropFrame reports whether drop-frame counting is defined at the rate.
+func (r Rate) CanDropFrame() bool {
+	return r.dropFrames() > 0
+}
+
+// FilmRate returns the film rate that video at this rate holds with 2:3
+// pulldown: 23.976 for 29.97 and 24 for 30, counted without drop frame. It
+// reports false at other rates.
+func (r Rate) FilmRate() (Rate, bool) {
+	switch {
+	case r.Num == 30000 && r.Den == 1001:
+		return Rate23_976, true
+	case r.Num == 30*r.Den:
+		return Rate24, true
+	}
+	return Rate{}, false
+}
+
+// VideoRate returns the video rate that holds film at this rate with 2:3
+// pulldown: 29.97 for 23.976 and 30 for 24, counted without drop frame. It
+// reports false at other rates.
+func (r Rate) VideoRate() (Rate, bool) {
+	switch {
+	case r.Num == 24000 && r.Den == 1001:
+		return Rate29_97, true
+	case r.Num == 24*r.Den:
+		return Rate30, true
+	}
+	return Rate{}, false
+}
+
+// dropFrames returns the frame numbers skipped each minute in drop-frame
+// counting at this rate, or zero if drop frame does not apply.
+func (r Rate) dropFrames() int {
+	if r.Den != 1001 {
+		return 0
+	}
+	switch r.Num {
+	case 30000:
+		return 2
+	case 60000:
+		return 4
+	}
+	return 0
+}
+
+// Timecode is a SMPTE timecode address. It holds no frame rate; conversions
+// to and from frame counts take one.
+type Timecode struct {
+	Hours     int
+	Minutes   int
+	Seconds   int
+	Frames    int
+	DropFrame bool
+}
+
+// Parse parses timecode written as HH:MM:SS:FF. A semicolon, comma or full
+// stop before the frames marks drop frame.
+func Parse(s string) (Timecode, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 11 {
+		return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+	}
+	var tc Timecode
+	fields := [4]*int{&tc.Hours, &tc.Minutes, &tc.Seconds, &tc.Frames}
+	for i, field := range fields {
+		digits := s[i*3 : i*3+2]
+		if digits[0] < '0' || digits[0] > '9' || digits[1] < '0' || digits[1] > '9' {
+			return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+		}
+		*field = int(digits[0]-'0')*10 + int(digits[1]-'0')
+		if i == 3 {
+			break
+		}
+		switch sep := s[i*3+2]; sep {
+		case ':':
+		case ';':
+			tc.DropFrame = true
+		case ',', '.':
+			if i != 2 {
+				return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+			}
+			tc.DropFrame = true
+		default:
+			return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+		}
+	}
+	if tc.Minutes > 59 || tc.Seconds > 59 {
+		return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+	}
+	return tc, nil
+}
+
+// MustParse is like Parse but panics if the timecode is invalid.
+func MustParse(s string) Timecode {
+	tc, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return tc
+}
+
+// String formats the timecode as HH:MM:SS:FF, or HH:MM:SS;FF in drop frame.
+func (t Timecode) String() string {
+	sep := ':'
+	if t.DropFrame {
+		sep = ';'
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%c%02d", t.Hours, t.Minutes, t.Seconds, sep, t.Frames)
+}
+
+// Validate checks that the frames field fits the rate and that the address
+// is not one skipped in drop-frame counting.
+func (t Timecode) Validate(rate Rate) error {
+	if t.Frames >= rate.Base() {
+		return fmt.Errorf("%w: %s has more frames than %s fps", ErrInvalidTimecode, t, rate)
+	}
+	if drop := rate.dropFrames(); rate.DropFrame && drop > 0 &&
+		t.Seconds == 0 && t.Frames < drop && t.Minutes%10 != 0 {
+		return fmt.Errorf("%w: %s is skipped in drop frame", ErrInvalidTimecode, t)
+	}
+	return nil
+}
+
+// ToFrames returns the number of frames from 00:00:00:00 at the rate,
+// counting in drop frame if the rate is drop frame.
+func (t Timecode) ToFrames(rate Rate) int {
+	base := rate.Base()
+	frames := ((t.Hours*60+t.Minutes)*60+t.Seconds)*base + t.Frames
+	if drop := rate.dropFrames(); rate.DropFrame && drop > 0 {
+		minutes := t.Hours*60 + t.Minutes
+		frames -= drop * (minutes - minutes/10)
+	}
+	return frames
+}
+
+// FromFrames returns the timecode of a frame count from 00:00:00:00 at the
+// rate. Counts outside one day wrap around midnight.
+func FromFrames(frames int, rate Rate) Timecode {
+	base := rate.Base()
+	if base <= 0 {
+		return Timecode{}
+	}
+	drop := rate.dropFrames()
+	dropFrame := rate.DropFrame && drop > 0
+
+	perDay := base * 86400
+	if dropFrame {
+		perDay -= drop * (1440 - 144)
+	}
+	frames %= perDay
+	if frames < 0 {
+		frames += perDay
+	}
+
+	if dropFrame {
+		perTenMinutes := base*600 - drop*9
+		perMinute := base*60 - drop
+		tens, rem := frames/perTenMinutes, frames%perTenMinutes
+		frames += drop * 9 * tens
+		if rem > drop {
+			frames += drop * ((rem - drop) / perMinute)
+		}
+	}
+
+	return Timecode{
+		Hours:     frames / (base * 3600),
+		Minutes:   frames / (base * 60) % 60,
+		Seconds:   frames / base % 60,
+		Frames:    frames % base,
+		DropFrame: dropFrame,
+	}
+}
+
+// Add returns the timecode a number of frames later at the rate.
+func (t Timecode) Add(frames int, rate Rate) Timecode {
+	return FromFrames(t.ToFrames(rate)+frames, rate)
+}
+
+// Sub returns the number of frames from u to t at the rate.
+func (t Timecode) Sub(u Timecode, rate Rate) int {
+	return t.ToFrames(rate) - u.ToFrames(rate)
+}
+
+// IsZero reports whether the timecode is 00:00:00:00.
+func (t Timecode) IsZero() bool {
+	return t.Hours == 0 && t.Minutes == 0 && t.Seconds == 0 && t.Frames == 0
+}
@@ -0,0 +1,168 @@
+package timecode
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Rate
+		wantErr bool
+	}{
+		{input: "25", want: Rate25},
+		{input: "23.976", want: Rate23_976},
+		{input: "23.98", want: Rate23_976},
+		{input: "29.97", want: Rate29_97},
+		{input: "29.97 DF", want: Rate29_97DF},
+		{input: "29.97df", want: Rate29_97DF},
+		{input: "59.94 NDF", want: Rate59_94},
+		{input: "30000/1001", want: Rate29_97},
+		{input: "25 DF", wantErr: true},
+		{input: "fast", wantErr: true},
+		{input: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("ParseRate(%q) error = %v, want ErrInvalidRate", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRateString(t *testing.T) {
+	tests := map[Rate]string{
+		Rate23_976:  "23.976",
+		Rate25:      "25",
+		Rate29_97:   "29.97",
+		Rate29_97DF: "29.97 DF",
+		Rate59_94:   "59.94",
+	}
+	for rate, want := range tests {
+		if got := rate.String(); got != want {
+			t.Errorf("%+v.String() = %q, want %q", rate, got, want)
+		}
+	}
+}
+
+func TestPulldownRates(t *testing.T) {
+	tests := []struct {
+		video, film Rate
+	}{
+		{Rate29_97, Rate23_976},
+		{Rate30, Rate24},
+	}
+	for _, tt := range tests {
+		if got, ok := tt.video.FilmRate(); !ok || got != tt.film {
+			t.Errorf("%s.FilmRate() = %s, %v, want %s", tt.video, got, ok, tt.film)
+		}
+		if got, ok := tt.film.VideoRate(); !ok || got != tt.video {
+			t.Errorf("%s.VideoRate() = %s, %v, want %s", tt.film, got, ok, tt.video)
+		}
+	}
+	if got, ok := Rate29_97DF.FilmRate(); !ok || got != Rate23_976 {
+		t.Errorf("Rate29_97DF.FilmRate() = %s, %v, want 23.976", got, ok)
+	}
+	for _, rate := range []Rate{Rate25, Rate59_94, Rate60} {
+		if _, ok := rate.FilmRate(); ok {
+			t.Errorf("%s.FilmRate() ok, want no film rate", rate)
+		}
+	}
+	for _, rate := range []Rate{Rate25, Rate29_97, Rate48} {
+		if _, ok := rate.VideoRate(); ok {
+			t.Errorf("%s.VideoRate() ok, want no video rate", rate)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Timecode
+		wantErr bool
+	}{
+		{input: "01:02:03:04", want: Timecode{Hours: 1, Minutes: 2, Seconds: 3, Frames: 4}},
+		{input: "01:02:03;04", want: Timecode{Hours: 1, Minutes: 2, Seconds: 3, Frames: 4, DropFrame: true}},
+		{input: "01;02;03;04", want: Timecode{Hours: 1, Minutes: 2, Seconds: 3, Frames: 4, DropFrame: true}},
+		{input: "01:02:03.04", want: Timecode{Hours: 1, Minutes: 2, Seconds: 3, Frames: 4, DropFrame: true}},
+		{input: "01:60:00:00", wantErr: true},
+		{input: "1:00:00:00", wantErr: true},
+		{input: "01.00.00.00", wantErr: true},
+		{input: "01:00:0x:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFrames(t *testing.T) {
+	tests := []struct {
+		tc     string
+		rate   Rate
+		frames int
+	}{
+		{tc: "00:00:00:00", rate: Rate25, frames: 0},
+		{tc: "01:00:00:00", rate: Rate25, frames: 90000},
+		{tc: "01:00:00:00", rate: Rate23_976, frames: 86400},
+		{tc: "00:01:00:00", rate: Rate29_97, frames: 1800},
+		{tc: "00:00:59;29", rate: Rate29_97DF, frames: 1799},
+		{tc: "00:01:00;02", rate: Rate29_97DF, frames: 1800},
+		{tc: "00:10:00;00", rate: Rate29_97DF, frames: 17982},
+		{tc: "01:00:00;00", rate: Rate29_97DF, frames: 107892},
+		{tc: "00:01:00;04", rate: Rate59_94DF, frames: 3600},
+	}
+	for _, tt := range tests {
+		tc := MustParse(tt.tc)
+		if got := tc.ToFrames(tt.rate); got != tt.frames {
+			t.Errorf("%s.ToFrames(%s) = %d, want %d", tt.tc, tt.rate, got, tt.frames)
+		}
+		if got := FromFrames(tt.frames, tt.rate).String(); got != tt.tc {
+			t.Errorf("FromFrames(%d, %s) = %s, want %s", tt.frames, tt.rate, got, tt.tc)
+		}
+	}
+
+	// Every frame of an hour round-trips in drop frame
+	for frames := 0; frames < 107892; frames++ {
+		tc := FromFrames(frames, Rate29_97DF)
+		if err := tc.Validate(Rate29_97DF); err != nil {
+			t.Fatalf("FromFrames(%d) = %s: %v", frames, tc, err)
+		}
+		if got := tc.ToFrames(Rate29_97DF); got != frames {
+			t.Fatalf("FromFrames(%d) = %s, which is frame %d", frames, tc, got)
+		}
+	}
+
+	if got := MustParse("00:00:00:00").Add(-1, Rate25).String(); got != "23:59:59:24" {
+		t.Errorf("Add(-1) = %s, want 23:59:59:24", got)
+	}
+	if got := MustParse("01:00:10:00").Sub(MustParse("01:00:00:00"), Rate24); got != 240 {
+		t.Errorf("Sub() = %d, want 240", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := MustParse("01:00:00:25").Validate(Rate25); err == nil {
+		t.Error("Expected error for frame 25 at 25 fps")
+	}
+	if err := MustParse("01:01:00;01").Validate(Rate29_97DF); err == nil {
+		t.Error("Expected error for dropped frame")
+	}
+	if err := MustParse("01:10:00;00").Validate(Rate29_97DF); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
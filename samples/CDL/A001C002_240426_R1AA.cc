@@ -0,0 +1,11 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<ColorCorrection xmlns="urn:ASC:CDL:v1.2">
+	<SOPNode>
+		<Slope>1.100000 1.000000 0.950000</Slope>
+		<Offset>0.010000 0.000000 -0.010000</Offset>
+		<Power>1.000000 1.000000 1.000000</Power>
+	</SOPNode>
+	<SATNode>
+		<Saturation>0.900000</Saturation>
+	</SATNode>
+</ColorCorrection>